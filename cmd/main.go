@@ -8,6 +8,7 @@ import (
 	_ "net/http/pprof"
 	"os"
 	"time"
+	_ "time/tzdata" // часовые пояса регулярных рейсов не должны зависеть от образа
 
 	"github.com/gin-gonic/gin"
 	"github.com/mutsaevz/team-5-ambitious/internal/config"
@@ -44,35 +45,284 @@ func main() {
 	if err := db.AutoMigrate(
 		&models.User{},
 		&models.Car{},
+		&models.CarPhoto{},
 		&models.Trip{},
+		&models.TripStop{},
 		&models.Booking{},
-		&models.Review{}); err != nil {
+		&models.Review{},
+		&models.AuthCode{},
+		&models.LedgerEntry{},
+		&models.PaymentIntent{},
+		&models.WaitlistEntry{},
+		&models.TripTemplate{},
+		&models.PromoCode{},
+		&models.PromoRedemption{},
+		&models.VerificationRequest{},
+		&models.City{},
+		&models.CityAlias{},
+		&models.SavedSearch{},
+		&models.SavedSearchMatch{}); err != nil {
 		logger.Error("failed to migrate database", "error", err)
 		os.Exit(1)
 	}
 
 	logger.Info("migrations completed")
 
+	authCfg, err := config.LoadAuthConfig()
+	if err != nil {
+		logger.Error("failed to load auth config", "error", err)
+		os.Exit(1)
+	}
+
 	userRepo := repository.NewUserRepository(db, logger)
 	carRepo := repository.NewCarRepository(db, logger)
 	tripRepo := repository.NewTripRepository(db, logger)
+	bookingRepo := repository.NewBookingRepository(db, logger)
+	reviewRepo := repository.NewReviewRepository(db, logger)
+	authCodeRepo := repository.NewAuthCodeRepository(db, logger)
+	ledgerRepo := repository.NewLedgerRepository(db, logger)
+	paymentRepo := repository.NewPaymentRepository(db, logger)
+	waitlistRepo := repository.NewWaitlistRepository(db, logger)
+	tripTemplateRepo := repository.NewTripTemplateRepository(db, logger)
+	promoRepo := repository.NewPromoRepository(db, logger)
+	verificationRepo := repository.NewVerificationRepository(db, logger)
+	cityRepo := repository.NewCityRepository(db, logger)
+	savedSearchRepo := repository.NewSavedSearchRepository(db, logger)
+
+	policy := services.NewPolicy(userRepo, logger)
+
+	cityService := services.NewCityService(cityRepo, policy, logger)
+	if err := cityService.Seed(services.DefaultCities); err != nil {
+		logger.Error("failed to seed city directory", "error", err)
+		os.Exit(1)
+	}
+	ledgerService := services.NewLedgerService(ledgerRepo, userRepo, logger)
+
+	tokenService := services.NewTokenService(authCfg.Secret, authCfg.AccessTTL, authCfg.RefreshTTL)
+	authService := services.NewAuthService(
+		authCodeRepo,
+		userRepo,
+		tokenService,
+		// настоящего отправителя пока нет: LoadAuthConfig пропускает только log и только в dev
+		services.NewLogCodeSender(logger),
+		authCfg.CodeTTL,
+		authCfg.CodeMaxAttempts,
+		authCfg.CodeCooldown,
+		authCfg.CodeWindow,
+		authCfg.CodeMaxRequests,
+		logger,
+	)
+
+	userService := services.NewUserService(userRepo, authService, policy, logger)
+	if err := userService.BootstrapAdmins(authCfg.AdminPhones); err != nil {
+		logger.Error("failed to bootstrap admins", "error", err)
+		os.Exit(1)
+	}
+	carCfg := config.LoadCarConfig()
+	carPhotos, err := services.NewLocalBlobStore(carCfg.PhotoDir, carCfg.PhotoURL, logger)
+	if err != nil {
+		logger.Error("failed to init car photo storage", "error", err)
+		os.Exit(1)
+	}
+	r.Static(carCfg.PhotoURL, carCfg.PhotoDir)
+
+	carService := services.NewCarService(
+		carRepo,
+		userRepo,
+		policy,
+		carPhotos,
+		carCfg.MaxPerDriver,
+		int64(carCfg.PhotoMaxSizeKB)*1024,
+		carCfg.MaxPhotos,
+		logger,
+	)
+	cancelCfg, err := config.LoadCancellationConfig()
+	if err != nil {
+		logger.Error("failed to load cancellation config", "error", err)
+		os.Exit(1)
+	}
+	cancellationPolicy := services.NewCancellationPolicy(cancelCfg.FullRefundBefore, cancelCfg.PartialRefundBefore, cancelCfg.PartialPercent)
+
+	pricingCfg, err := config.LoadPricingConfig()
+	if err != nil {
+		logger.Error("failed to load pricing config", "error", err)
+		os.Exit(1)
+	}
+	var pricingRules []services.PricingRule
+	if pricingCfg.EarlyBirdDays > 0 {
+		pricingRules = append(pricingRules, services.NewEarlyBirdRule(time.Duration(pricingCfg.EarlyBirdDays)*24*time.Hour, pricingCfg.EarlyBirdPercent))
+	}
+	if pricingCfg.LastSeatsThreshold > 0 {
+		pricingRules = append(pricingRules, services.NewLastSeatsRule(pricingCfg.LastSeatsThreshold, pricingCfg.LastSeatsPercent))
+	}
+	pricing := services.NewPricingEngine(pricingCfg.CommissionPercent, pricingRules...)
+
+	promoService := services.NewPromoService(promoRepo, bookingRepo, policy, logger)
+	referralCfg := config.LoadReferralConfig()
+	referralService := services.NewReferralService(userRepo, bookingRepo, ledgerService, referralCfg.ReferrerBonus, referralCfg.RefereeBonus, logger)
+
+	var notifier services.Notifier = services.NewLogNotifier(logger)
+	if notifierCfg := config.LoadNotifierConfig(); notifierCfg.Kind == "memory" {
+		notifier = services.NewInMemoryNotifier(notifierCfg.MemoryLimit)
+	}
+	bookingCfg := config.LoadBookingConfig()
+
+	waitlistService := services.NewWaitlistService(
+		waitlistRepo,
+		bookingRepo,
+		tripRepo,
+		promoRepo,
+		ledgerService,
+		policy,
+		cancellationPolicy,
+		pricing,
+		notifier,
+		bookingCfg.WaitlistOfferWindow,
+		db,
+		logger,
+	)
+
+	savedSearchCfg := config.LoadSavedSearchConfig()
+	savedSearchService := services.NewSavedSearchService(
+		savedSearchRepo,
+		cityService,
+		policy,
+		savedSearchCfg.DefaultTTL,
+		savedSearchCfg.MaxTTL,
+		savedSearchCfg.MaxPerUser,
+		logger,
+	)
+	savedSearchMatcher := services.NewSavedSearchMatcher(savedSearchRepo, tripRepo, notifier, logger)
+
+	tripCfg := config.LoadTripConfig()
+	tripService := services.NewTripService(
+		tripRepo,
+		userRepo,
+		carRepo,
+		bookingRepo,
+		promoRepo,
+		ledgerService,
+		policy,
+		cancellationPolicy,
+		notifier,
+		waitlistService,
+		referralService,
+		cityService,
+		savedSearchMatcher,
+		tripCfg.MaterialShift,
+		db,
+		logger,
+	)
+
+	bookingService := services.NewBookingService(
+		bookingRepo,
+		tripRepo,
+		userRepo,
+		promoRepo,
+		ledgerService,
+		policy,
+		cancellationPolicy,
+		pricing,
+		promoService,
+		notifier,
+		waitlistService,
+		db,
+		logger,
+	)
+	scheduleCfg := config.LoadScheduleConfig()
+	tripTemplateService := services.NewTripTemplateService(
+		tripTemplateRepo,
+		tripRepo,
+		carRepo,
+		userRepo,
+		cityService,
+		savedSearchMatcher,
+		policy,
+		time.Duration(scheduleCfg.HorizonDays)*24*time.Hour,
+		db,
+		logger,
+	)
+	reviewService := services.NewReviewService(reviewRepo, tripRepo, policy, db, rdb, logger)
+
+	paymentCfg, err := config.LoadPaymentConfig()
+	if err != nil {
+		logger.Error("failed to load payment config", "error", err)
+		os.Exit(1)
+	}
+	// без провайдера платежи отключены; настоящего провайдера пока нет,
+	// а fake LoadPaymentConfig пропускает только в dev
+	var paymentService services.PaymentService
+	if paymentCfg.Provider == config.PaymentProviderFake {
+		paymentProvider := services.NewFakePaymentProvider(paymentCfg.CallbackSecret, paymentCfg.FakeDelay, logger)
+		paymentService = services.NewPaymentService(paymentRepo, ledgerService, paymentProvider, db, logger)
+		paymentProvider.SetCallback(paymentService.HandleCallback)
+	} else {
+		logger.Warn("payment provider is not configured, payments are disabled")
+	}
 
 	tripStatusWorker := services.NewTripStatusWorker(
 		tripRepo,
+		tripService,
+		tripCfg.StartGrace,
 		logger,
-		time.Minute,
+		tripCfg.StatusTick,
 	)
 
 	tripStatusWorker.Start(ctx)
 
-	bookingRepo := repository.NewBookingRepository(db, logger)
-	reviewRepo := repository.NewReviewRepository(db, logger)
+	bookingExpiryWorker := services.NewBookingExpiryWorker(
+		bookingRepo,
+		bookingService,
+		bookingCfg.PendingTimeout,
+		logger,
+		bookingCfg.ExpiryTick,
+	)
+
+	bookingExpiryWorker.Start(ctx)
+
+	waitlistWorker := services.NewWaitlistWorker(
+		waitlistRepo,
+		waitlistService,
+		logger,
+		bookingCfg.WaitlistTick,
+	)
+
+	waitlistWorker.Start(ctx)
+
+	tripTemplateWorker := services.NewTripTemplateWorker(
+		tripTemplateRepo,
+		tripTemplateService,
+		logger,
+		scheduleCfg.Tick,
+	)
+
+	tripTemplateWorker.Start(ctx)
+
+	verificationCfg := config.LoadVerificationConfig()
+	verificationDocuments, err := services.NewLocalBlobStore(verificationCfg.DocumentDir, "", logger)
+	if err != nil {
+		logger.Error("failed to init verification document storage", "error", err)
+		os.Exit(1)
+	}
+	verificationService := services.NewVerificationService(
+		verificationRepo,
+		userRepo,
+		carRepo,
+		policy,
+		verificationDocuments,
+		notifier,
+		int64(verificationCfg.DocumentMaxSizeKB)*1024,
+		db,
+		logger,
+	)
+
+	verificationWorker := services.NewVerificationExpiryWorker(
+		verificationService,
+		logger,
+		verificationCfg.ExpiryTick,
+	)
 
-	userService := services.NewUserService(userRepo, logger)
-	carService := services.NewCarService(carRepo, userRepo, logger)
-	tripService := services.NewTripService(tripRepo, userRepo, carRepo, logger)
-	bookingService := services.NewBookingService(bookingRepo, tripRepo, db, logger)
-	reviewService := services.NewReviewService(reviewRepo, tripRepo, db, rdb, logger)
+	verificationWorker.Start(ctx)
 
 	transports.RegisterRoutes(
 		r, logger,
@@ -81,6 +331,17 @@ func main() {
 		tripService,
 		bookingService,
 		reviewService,
+		authService,
+		tokenService,
+		ledgerService,
+		paymentService,
+		waitlistService,
+		tripTemplateService,
+		promoService,
+		referralService,
+		verificationService,
+		cityService,
+		savedSearchService,
 	)
 
 	port := os.Getenv("PORT")
@@ -0,0 +1,9 @@
+package config
+
+import "os"
+
+// DevMode — локальная разработка (APP_ENV=dev). Только в ней разрешены заглушки,
+// которые пишут коды входа в лог и подтверждают платежи без оплаты
+func DevMode() bool {
+	return os.Getenv("APP_ENV") == "dev"
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CodeSenderLog — отправка кода в лог вместо SMS; только для локальной разработки
+const CodeSenderLog = "log"
+
+type AuthConfig struct {
+	Secret          []byte
+	AccessTTL       time.Duration
+	RefreshTTL      time.Duration
+	CodeTTL         time.Duration
+	CodeMaxAttempts int
+
+	// CodeSender — способ доставки кода; без явного выбора сервер не запускается
+	CodeSender string
+
+	// CodeCooldown — пауза между запросами кода на один номер; за CodeWindow на номер
+	// уходит не больше CodeMaxRequests кодов, а неверные попытки считаются по всем этим кодам
+	CodeCooldown    time.Duration
+	CodeWindow      time.Duration
+	CodeMaxRequests int
+
+	// AdminPhones — номера, которым при запуске выдаётся роль администратора (ADMIN_PHONES через запятую)
+	AdminPhones []string
+}
+
+func LoadAuthConfig() (AuthConfig, error) {
+	secret := os.Getenv("AUTH_SECRET")
+	if secret == "" {
+		return AuthConfig{}, errors.New("AUTH_SECRET is not set")
+	}
+
+	// код в логе позволяет войти под любым номером тому, кто читает логи
+	sender := os.Getenv("AUTH_CODE_SENDER")
+	switch {
+	case sender == "":
+		return AuthConfig{}, errors.New("AUTH_CODE_SENDER is not set")
+	case sender == CodeSenderLog && !DevMode():
+		return AuthConfig{}, errors.New("AUTH_CODE_SENDER=log is allowed only with APP_ENV=dev")
+	case sender != CodeSenderLog:
+		return AuthConfig{}, errors.New("unknown AUTH_CODE_SENDER: " + sender)
+	}
+
+	return AuthConfig{
+		Secret:          []byte(secret),
+		AccessTTL:       durationFromEnv("AUTH_ACCESS_TTL", 15*time.Minute),
+		RefreshTTL:      durationFromEnv("AUTH_REFRESH_TTL", 30*24*time.Hour),
+		CodeTTL:         durationFromEnv("AUTH_CODE_TTL", 5*time.Minute),
+		CodeMaxAttempts: intFromEnv("AUTH_CODE_MAX_ATTEMPTS", 5),
+		CodeSender:      sender,
+		CodeCooldown:    durationFromEnv("AUTH_CODE_COOLDOWN", time.Minute),
+		CodeWindow:      durationFromEnv("AUTH_CODE_WINDOW", time.Hour),
+		CodeMaxRequests: intFromEnv("AUTH_CODE_MAX_REQUESTS", 5),
+		AdminPhones:     listFromEnv("ADMIN_PHONES"),
+	}, nil
+}
+
+func durationFromEnv(key string, def time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+	}
+	return def
+}
+
+func listFromEnv(key string) []string {
+	var list []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+func intFromEnv(key string, def int) int {
+	if v := os.Getenv(key); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+	}
+	return def
+}
+
+// percentFromEnv читает процент 0..100; неверное значение — ошибка конфигурации, а не значение по умолчанию
+func percentFromEnv(key string, def int) (int, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 || n > 100 {
+		return 0, errors.New(key + " must be a percent from 0 to 100")
+	}
+	return n, nil
+}
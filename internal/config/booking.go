@@ -0,0 +1,23 @@
+package config
+
+import "time"
+
+type BookingConfig struct {
+	// PendingTimeout — сколько заявка ждёт ответа водителя, если у поездки не задан свой срок
+	PendingTimeout time.Duration
+	ExpiryTick     time.Duration
+
+	// WaitlistOfferWindow — сколько у пассажира из очереди есть времени подтвердить бронь
+	WaitlistOfferWindow time.Duration
+	WaitlistTick        time.Duration
+}
+
+func LoadBookingConfig() BookingConfig {
+	return BookingConfig{
+		PendingTimeout: durationFromEnv("BOOKING_PENDING_TIMEOUT", 24*time.Hour),
+		ExpiryTick:     durationFromEnv("BOOKING_EXPIRY_TICK", time.Minute),
+
+		WaitlistOfferWindow: durationFromEnv("WAITLIST_OFFER_WINDOW", 30*time.Minute),
+		WaitlistTick:        durationFromEnv("WAITLIST_TICK", time.Minute),
+	}
+}
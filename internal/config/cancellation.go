@@ -0,0 +1,22 @@
+package config
+
+import "time"
+
+type CancellationConfig struct {
+	FullRefundBefore    time.Duration
+	PartialRefundBefore time.Duration
+	PartialPercent      int
+}
+
+func LoadCancellationConfig() (CancellationConfig, error) {
+	partial, err := percentFromEnv("CANCEL_PARTIAL_PERCENT", 50)
+	if err != nil {
+		return CancellationConfig{}, err
+	}
+
+	return CancellationConfig{
+		FullRefundBefore:    durationFromEnv("CANCEL_FULL_REFUND_BEFORE", 24*time.Hour),
+		PartialRefundBefore: durationFromEnv("CANCEL_PARTIAL_REFUND_BEFORE", 2*time.Hour),
+		PartialPercent:      partial,
+	}, nil
+}
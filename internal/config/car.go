@@ -0,0 +1,35 @@
+package config
+
+import "os"
+
+type CarConfig struct {
+	// MaxPerDriver — сколько автомобилей может добавить один водитель
+	MaxPerDriver int
+
+	// фотографии хранятся в PhotoDir и раздаются по PhotoURL
+	PhotoDir       string
+	PhotoURL       string
+	PhotoMaxSizeKB int
+	MaxPhotos      int
+}
+
+func LoadCarConfig() CarConfig {
+	dir := os.Getenv("CAR_PHOTO_DIR")
+	if dir == "" {
+		dir = "./uploads/cars"
+	}
+
+	url := os.Getenv("CAR_PHOTO_URL")
+	if url == "" {
+		url = "/media/cars"
+	}
+
+	return CarConfig{
+		MaxPerDriver: intFromEnv("CAR_MAX_PER_DRIVER", 3),
+
+		PhotoDir:       dir,
+		PhotoURL:       url,
+		PhotoMaxSizeKB: intFromEnv("CAR_PHOTO_MAX_SIZE_KB", 5120),
+		MaxPhotos:      intFromEnv("CAR_MAX_PHOTOS", 10),
+	}
+}
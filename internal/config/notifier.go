@@ -0,0 +1,21 @@
+package config
+
+import "os"
+
+type NotifierConfig struct {
+	// Kind — куда отправлять уведомления: log пишет в лог, memory хранит последние MemoryLimit на пользователя
+	Kind        string
+	MemoryLimit int
+}
+
+func LoadNotifierConfig() NotifierConfig {
+	kind := os.Getenv("NOTIFIER")
+	if kind == "" {
+		kind = "log"
+	}
+
+	return NotifierConfig{
+		Kind:        kind,
+		MemoryLimit: intFromEnv("NOTIFIER_MEMORY_LIMIT", 100),
+	}
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+// PaymentProviderFake — внутрипроцессный провайдер, подтверждающий любой платёж; только для локальной разработки
+const PaymentProviderFake = "fake"
+
+type PaymentConfig struct {
+	// Provider — платёжный провайдер; пустой — платежи отключены
+	Provider       string
+	CallbackSecret []byte
+	FakeDelay      time.Duration
+}
+
+func LoadPaymentConfig() (PaymentConfig, error) {
+	provider := os.Getenv("PAYMENT_PROVIDER")
+	switch {
+	case provider == "":
+		return PaymentConfig{}, nil
+	case provider == PaymentProviderFake && !DevMode():
+		return PaymentConfig{}, errors.New("PAYMENT_PROVIDER=fake is allowed only with APP_ENV=dev")
+	case provider != PaymentProviderFake:
+		return PaymentConfig{}, errors.New("unknown PAYMENT_PROVIDER: " + provider)
+	}
+
+	secret := os.Getenv("PAYMENT_CALLBACK_SECRET")
+	if secret == "" {
+		return PaymentConfig{}, errors.New("PAYMENT_CALLBACK_SECRET is not set")
+	}
+
+	return PaymentConfig{
+		Provider:       provider,
+		CallbackSecret: []byte(secret),
+		FakeDelay:      durationFromEnv("PAYMENT_FAKE_DELAY", 2*time.Second),
+	}, nil
+}
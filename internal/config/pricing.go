@@ -0,0 +1,31 @@
+package config
+
+type PricingConfig struct {
+	// скидка за раннее бронирование: не меньше EarlyBirdDays дней до отправления; 0 — выключена
+	EarlyBirdDays    int
+	EarlyBirdPercent int
+	// надбавка за последние места: после брони на участке останется не больше LastSeatsThreshold мест; 0 — выключена
+	LastSeatsThreshold int
+	LastSeatsPercent   int
+	CommissionPercent  int
+}
+
+func LoadPricingConfig() (PricingConfig, error) {
+	cfg := PricingConfig{
+		EarlyBirdDays:      intFromEnv("PRICING_EARLY_BIRD_DAYS", 0),
+		LastSeatsThreshold: intFromEnv("PRICING_LAST_SEATS_THRESHOLD", 0),
+	}
+
+	var err error
+	if cfg.EarlyBirdPercent, err = percentFromEnv("PRICING_EARLY_BIRD_PERCENT", 10); err != nil {
+		return PricingConfig{}, err
+	}
+	if cfg.LastSeatsPercent, err = percentFromEnv("PRICING_LAST_SEATS_PERCENT", 15); err != nil {
+		return PricingConfig{}, err
+	}
+	if cfg.CommissionPercent, err = percentFromEnv("PRICING_COMMISSION_PERCENT", 0); err != nil {
+		return PricingConfig{}, err
+	}
+
+	return cfg, nil
+}
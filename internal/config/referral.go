@@ -0,0 +1,14 @@
+package config
+
+type ReferralConfig struct {
+	// бонусы на баланс пригласившему и приглашённому после первой завершённой поездки приглашённого
+	ReferrerBonus int
+	RefereeBonus  int
+}
+
+func LoadReferralConfig() ReferralConfig {
+	return ReferralConfig{
+		ReferrerBonus: intFromEnv("REFERRAL_REFERRER_BONUS", 200),
+		RefereeBonus:  intFromEnv("REFERRAL_REFEREE_BONUS", 200),
+	}
+}
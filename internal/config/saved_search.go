@@ -0,0 +1,18 @@
+package config
+
+import "time"
+
+type SavedSearchConfig struct {
+	// DefaultTTL — срок подписки, если пассажир не указал свой; дольше MaxTTL подписка не живёт
+	DefaultTTL time.Duration
+	MaxTTL     time.Duration
+	MaxPerUser int
+}
+
+func LoadSavedSearchConfig() SavedSearchConfig {
+	return SavedSearchConfig{
+		DefaultTTL: durationFromEnv("SAVED_SEARCH_DEFAULT_TTL", 30*24*time.Hour),
+		MaxTTL:     durationFromEnv("SAVED_SEARCH_MAX_TTL", 90*24*time.Hour),
+		MaxPerUser: intFromEnv("SAVED_SEARCH_MAX_PER_USER", 10),
+	}
+}
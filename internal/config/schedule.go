@@ -0,0 +1,16 @@
+package config
+
+import "time"
+
+type ScheduleConfig struct {
+	// HorizonDays — на сколько дней вперёд создаются поездки регулярных рейсов
+	HorizonDays int
+	Tick        time.Duration
+}
+
+func LoadScheduleConfig() ScheduleConfig {
+	return ScheduleConfig{
+		HorizonDays: intFromEnv("TRIP_TEMPLATE_HORIZON_DAYS", 14),
+		Tick:        durationFromEnv("TRIP_TEMPLATE_TICK", time.Hour),
+	}
+}
@@ -0,0 +1,23 @@
+package config
+
+import "time"
+
+type TripConfig struct {
+	// StartGrace — сколько после планового отправления водитель может начать поездку сам,
+	// прежде чем её переведёт в пути воркер
+	StartGrace time.Duration
+	StatusTick time.Duration
+
+	// MaterialShift — сдвиг отправления или прибытия, начиная с которого изменение поездки
+	// считается существенным: пассажиров уведомляют и разрешают отмену без штрафа
+	MaterialShift time.Duration
+}
+
+func LoadTripConfig() TripConfig {
+	return TripConfig{
+		StartGrace: durationFromEnv("TRIP_START_GRACE", 30*time.Minute),
+		StatusTick: durationFromEnv("TRIP_STATUS_TICK", time.Minute),
+
+		MaterialShift: durationFromEnv("TRIP_MATERIAL_SHIFT", time.Hour),
+	}
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+type VerificationConfig struct {
+	// DocumentDir — закрытый каталог документов, по HTTP не раздаётся
+	DocumentDir       string
+	DocumentMaxSizeKB int
+	ExpiryTick        time.Duration
+}
+
+func LoadVerificationConfig() VerificationConfig {
+	dir := os.Getenv("VERIFICATION_DOCUMENT_DIR")
+	if dir == "" {
+		dir = "./uploads/verification"
+	}
+
+	return VerificationConfig{
+		DocumentDir:       dir,
+		DocumentMaxSizeKB: intFromEnv("VERIFICATION_DOCUMENT_MAX_SIZE_KB", 10240),
+		ExpiryTick:        durationFromEnv("VERIFICATION_EXPIRY_TICK", time.Hour),
+	}
+}
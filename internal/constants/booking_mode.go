@@ -0,0 +1,9 @@
+package constants
+
+// BookingMode — как поездка принимает заявки
+type BookingMode string
+
+const (
+	BookingModeManual  BookingMode = "manual"  // водитель подтверждает каждую заявку
+	BookingModeInstant BookingMode = "instant" // заявка подтверждается сразу, если есть места и деньги
+)
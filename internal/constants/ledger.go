@@ -0,0 +1,26 @@
+package constants
+
+type LedgerAccount string
+
+const (
+	AccountWallet   LedgerAccount = "wallet"   // свободные средства пользователя (= users.balance)
+	AccountHold     LedgerAccount = "hold"     // средства пассажира, заблокированные под бронь
+	AccountPlatform LedgerAccount = "platform" // счёт платформы
+	AccountExternal LedgerAccount = "external" // деньги за пределами платформы (платёжный провайдер)
+	AccountPayout   LedgerAccount = "payout"   // средства, зарезервированные под вывод
+)
+
+type LedgerKind string
+
+const (
+	LedgerBookingHold    LedgerKind = "booking_hold"
+	LedgerBookingSettle  LedgerKind = "booking_settle"
+	LedgerBookingRelease LedgerKind = "booking_release"
+	LedgerCommission     LedgerKind = "booking_commission"
+	LedgerPromoDiscount  LedgerKind = "promo_discount"
+	LedgerReferralBonus  LedgerKind = "referral_bonus"
+	LedgerTopUp          LedgerKind = "top_up"
+	LedgerPayoutReserve  LedgerKind = "payout_reserve"
+	LedgerPayoutComplete LedgerKind = "payout_complete"
+	LedgerPayoutRelease  LedgerKind = "payout_release"
+)
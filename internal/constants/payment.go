@@ -0,0 +1,16 @@
+package constants
+
+type PaymentKind string
+
+const (
+	PaymentTopUp  PaymentKind = "top_up"
+	PaymentPayout PaymentKind = "payout"
+)
+
+type PaymentStatus string
+
+const (
+	PaymentPending   PaymentStatus = "pending"
+	PaymentSucceeded PaymentStatus = "succeeded"
+	PaymentFailed    PaymentStatus = "failed"
+)
@@ -0,0 +1,8 @@
+package constants
+
+type PromoDiscountType string
+
+const (
+	PromoPercent PromoDiscountType = "percent" // процент от стоимости брони
+	PromoFixed   PromoDiscountType = "fixed"   // фиксированная сумма, не больше стоимости брони
+)
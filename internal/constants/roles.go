@@ -0,0 +1,9 @@
+package constants
+
+type UserRole string
+
+const (
+	RolePassenger UserRole = "passenger" // роль по умолчанию
+	RoleDriver    UserRole = "driver"    // есть хотя бы один автомобиль
+	RoleAdmin     UserRole = "admin"
+)
@@ -7,6 +7,7 @@ const (
 	TripPublished  TripStatus = "published"
 	TripInProgress TripStatus = "in_progress"
 	TripCompleted  TripStatus = "completed"
+	TripCancelled  TripStatus = "cancelled" // отменена водителем, брони отменены с возвратом
 )
 
 type BookingStatus string
@@ -16,4 +17,8 @@ const (
 	BookingApproved = "approved" // водитель принял
 	BookingRejected = "rejected" // водитель отклонил
 
+	BookingCancelledByPassenger = "cancelled_by_passenger" // пассажир отменил
+	BookingCancelledByDriver    = "cancelled_by_driver"    // водитель отменил
+
+	BookingExpired = "expired" // водитель не ответил вовремя или поездка ушла из продажи
 )
@@ -0,0 +1,10 @@
+package constants
+
+// TripSort — порядок выдачи в поиске поездок
+type TripSort string
+
+const (
+	TripSortDeparture TripSort = "departure" // ближайшие отправления первыми
+	TripSortPrice     TripSort = "price"     // дешёвые первыми
+	TripSortRating    TripSort = "rating"    // с высоким рейтингом первыми
+)
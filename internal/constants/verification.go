@@ -0,0 +1,18 @@
+package constants
+
+type VerificationStatus string
+
+const (
+	VerificationPending  VerificationStatus = "pending"  // ждёт проверки администратором
+	VerificationApproved VerificationStatus = "approved" // документы подтверждены
+	VerificationRejected VerificationStatus = "rejected" // отклонена с указанием причины
+	VerificationExpired  VerificationStatus = "expired"  // истёк срок действия водительского удостоверения
+)
+
+// VerificationDocument — вид документа в заявке на проверку
+type VerificationDocument string
+
+const (
+	DocumentLicense      VerificationDocument = "license"      // водительское удостоверение
+	DocumentRegistration VerificationDocument = "registration" // свидетельство о регистрации автомобиля
+)
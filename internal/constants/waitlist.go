@@ -0,0 +1,12 @@
+package constants
+
+type WaitlistStatus string
+
+const (
+	WaitlistWaiting  WaitlistStatus = "waiting"  // ждёт освобождения мест
+	WaitlistOffered  WaitlistStatus = "offered"  // места освободились, ждём согласия пассажира
+	WaitlistAccepted WaitlistStatus = "accepted" // бронь создана
+	WaitlistExpired  WaitlistStatus = "expired"  // пассажир не ответил на предложение вовремя
+	WaitlistLeft     WaitlistStatus = "left"     // пассажир вышел из очереди
+	WaitlistClosed   WaitlistStatus = "closed"   // поездка отменена
+)
@@ -3,8 +3,21 @@ package dto
 import "github.com/mutsaevz/team-5-ambitious/internal/constants"
 
 type BookingCreateRequest struct {
-	TripID      uint `json:"trip_id" binding:"required"`
-	PassengerID uint `json:"passenger_id" binding:"required"`
+	TripID uint `json:"trip_id" binding:"required"`
+	Seats  int  `json:"seats" binding:"omitempty,min=1"` // по умолчанию одно место
+	// позиции остановок посадки и высадки, по умолчанию — весь маршрут
+	FromStop  *int   `json:"from_stop" binding:"omitempty,min=0"`
+	ToStop    *int   `json:"to_stop" binding:"omitempty,min=1"`
+	PromoCode string `json:"promo_code" binding:"omitempty,max=50"`
+}
+
+// BookingQuoteRequest — параметры расчёта цены до бронирования, передаются в query
+type BookingQuoteRequest struct {
+	TripID    uint   `form:"trip_id" binding:"required"`
+	Seats     int    `form:"seats" binding:"omitempty,min=1"`
+	FromStop  *int   `form:"from_stop" binding:"omitempty,min=0"`
+	ToStop    *int   `form:"to_stop" binding:"omitempty,min=1"`
+	PromoCode string `form:"promo_code" binding:"omitempty,max=50"`
 }
 
 type BookingUpdateRequest struct {
@@ -1,14 +1,33 @@
 package dto
 
 type CarCreateRequest struct {
-	OwnerID  uint   `json:"owner_id"`
-	Brand    string `json:"brand"`
-	CarModel string `json:"car_model"`
-	Seats    int    `json:"seats"`
+	Brand    string `json:"brand" binding:"required"`
+	CarModel string `json:"car_model" binding:"required"`
+	Seats    int    `json:"seats" binding:"required,min=1"`
+
+	Plate     string       `json:"plate" binding:"required,max=20"`
+	Color     string       `json:"color" binding:"omitempty,max=50"`
+	Year      int          `json:"year" binding:"omitempty,min=1950"`
+	Amenities CarAmenities `json:"amenities"`
 }
 
 type CarUpdateRequest struct {
 	Brand    *string `json:"brand"`
 	CarModel *string `json:"car_model"`
-	Seats    *int    `json:"seats"`
+	Seats    *int    `json:"seats" binding:"omitempty,min=1"`
+
+	Plate     *string      `json:"plate" binding:"omitempty,max=20"`
+	Color     *string      `json:"color" binding:"omitempty,max=50"`
+	Year      *int         `json:"year" binding:"omitempty,min=1950"`
+	Amenities CarAmenities `json:"amenities"`
+}
+
+// CarAmenities — удобства в салоне; при создании не указанные считаются отсутствующими,
+// при обновлении не меняются
+type CarAmenities struct {
+	AirConditioning *bool `json:"air_conditioning"`
+	LuggageSpace    *bool `json:"luggage_space"`
+	ChildSeat       *bool `json:"child_seat"`
+	PetsAllowed     *bool `json:"pets_allowed"`
+	SmokingAllowed  *bool `json:"smoking_allowed"`
 }
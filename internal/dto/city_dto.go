@@ -0,0 +1,11 @@
+package dto
+
+type CityCreateRequest struct {
+	Name    string   `json:"name" binding:"required,max=100"`
+	Region  string   `json:"region" binding:"max=100"`
+	Aliases []string `json:"aliases" binding:"omitempty,dive,required,max=100"`
+}
+
+type CityAliasRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
@@ -0,0 +1,8 @@
+package dto
+
+// Page — страница списка; NextCursor передаётся в параметре cursor за следующей страницей,
+// пустой — записей больше нет
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
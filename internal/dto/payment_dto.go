@@ -0,0 +1,12 @@
+package dto
+
+import "github.com/mutsaevz/team-5-ambitious/internal/models"
+
+type PaymentRequest struct {
+	Amount int `json:"amount" binding:"required,min=1"`
+}
+
+type PaymentIntentResponse struct {
+	Intent          *models.PaymentIntent `json:"intent"`
+	ConfirmationURL string                `json:"confirmation_url,omitempty"`
+}
@@ -0,0 +1,24 @@
+package dto
+
+// PriceAdjustment — надбавка (>0) или скидка (<0) к цене места по одному правилу
+type PriceAdjustment struct {
+	Rule   string `json:"rule"`
+	Amount int    `json:"amount"`
+}
+
+// PriceQuote — расчёт стоимости брони; эти же суммы фиксируются в брони при её создании
+type PriceQuote struct {
+	TripID      uint              `json:"trip_id"`
+	FromStop    int               `json:"from_stop"`
+	ToStop      int               `json:"to_stop"`
+	Seats       int               `json:"seats"`
+	BasePerSeat int               `json:"base_per_seat"`
+	Adjustments []PriceAdjustment `json:"adjustments,omitempty"`
+	PerSeat     int               `json:"per_seat"`
+	// скидка по промокоду на всю бронь; Total уже уменьшен на неё
+	PromoCode string `json:"promo_code,omitempty"`
+	Discount  int    `json:"discount"`
+	Total     int    `json:"total"`
+	// Commission — доля платформы в стоимости без скидки, водитель получает Total + Discount - Commission
+	Commission int `json:"commission"`
+}
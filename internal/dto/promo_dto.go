@@ -0,0 +1,31 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+)
+
+type PromoCodeCreateRequest struct {
+	Code          string                      `json:"code" binding:"required,max=50"`
+	DiscountType  constants.PromoDiscountType `json:"discount_type" binding:"required,oneof=percent fixed"`
+	DiscountValue int                         `json:"discount_value" binding:"required,min=1"`
+	MaxUses       int                         `json:"max_uses" binding:"omitempty,min=0"`
+	// PerUserLimit по умолчанию 1
+	PerUserLimit  *int       `json:"per_user_limit" binding:"omitempty,min=0"`
+	FirstTripOnly bool       `json:"first_trip_only"`
+	ValidFrom     *time.Time `json:"valid_from"`
+	ValidUntil    *time.Time `json:"valid_until"`
+}
+
+type PromoCodeUpdateRequest struct {
+	MaxUses      *int       `json:"max_uses" binding:"omitempty,min=0"`
+	PerUserLimit *int       `json:"per_user_limit" binding:"omitempty,min=0"`
+	ValidFrom    *time.Time `json:"valid_from"`
+	ValidUntil   *time.Time `json:"valid_until"`
+	Active       *bool      `json:"active"`
+}
+
+type ReferralApplyRequest struct {
+	Code string `json:"code" binding:"required"`
+}
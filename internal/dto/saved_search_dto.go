@@ -0,0 +1,24 @@
+package dto
+
+import "time"
+
+// SavedSearchCreateRequest — условия поиска, как в GET /trips; нужен хотя бы город или точка
+// отправления либо прибытия. ExpiresAt по умолчанию — срок из конфигурации
+type SavedSearchCreateRequest struct {
+	FromCity     *string   `json:"from_city" binding:"omitempty,min=1,max=100"`
+	ToCity       *string   `json:"to_city" binding:"omitempty,min=1,max=100"`
+	FromPoint    *GeoPoint `json:"from_point"`
+	FromRadiusKm float64   `json:"from_radius_km" binding:"required_with=FromPoint,gte=0,lte=100"`
+	ToPoint      *GeoPoint `json:"to_point"`
+	ToRadiusKm   float64   `json:"to_radius_km" binding:"required_with=ToPoint,gte=0,lte=100"`
+
+	StartTime    *time.Time   `json:"start_time"`
+	StartTimeTo  *time.Time   `json:"start_time_to"`
+	Seats        int          `json:"seats" binding:"omitempty,min=1"`
+	MaxPrice     *int         `json:"max_price" binding:"omitempty,min=0"`
+	MinRating    *float64     `json:"min_rating" binding:"omitempty,gte=0,lte=5"`
+	VerifiedOnly bool         `json:"verified_only"`
+	Amenities    CarAmenities `json:"amenities"`
+
+	ExpiresAt *time.Time `json:"expires_at"`
+}
@@ -4,35 +4,115 @@ import (
 	"time"
 
 	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
 )
 
 type TripCreateRequest struct {
-	FromCity       string               `json:"from_city"`
-	ToCity         string               `json:"to_city"`
-	StartTime      time.Time            `json:"start_time"`
-	DurationMin    int                  `json:"duration_min"`
-	AvailableSeats int                  `json:"available_seats"`
-	Price          int                  `json:"price"`
-	TripStatus     constants.TripStatus `json:"trip_status"`
+	// CarID — автомобиль водителя; можно не указывать, если автомобиль один
+	CarID             uint                  `json:"car_id"`
+	FromCity          string                `json:"from_city"`
+	ToCity            string                `json:"to_city"`
+	StartTime         time.Time             `json:"start_time"`
+	DurationMin       int                   `json:"duration_min"`
+	AvailableSeats    int                   `json:"available_seats" binding:"omitempty,min=1"`
+	Price             int                   `json:"price" binding:"omitempty,min=0"`
+	BookingMode       constants.BookingMode `json:"booking_mode" binding:"omitempty,oneof=manual instant"`
+	PendingTimeoutMin int                   `json:"pending_timeout_min" binding:"omitempty,min=1"`
+	// Stops — промежуточные остановки по порядку, без городов отправления и прибытия
+	Stops []TripStopRequest `json:"stops" binding:"omitempty,dive"`
+	// DistanceKm — длина всего маршрута; нужна, чтобы делить цену по расстоянию
+	DistanceKm *int `json:"distance_km" binding:"omitempty,gt=0"`
+	// FromPoint и ToPoint — точные места посадки и высадки, необязательны
+	FromPoint *GeoPoint `json:"from_point"`
+	ToPoint   *GeoPoint `json:"to_point"`
+}
+
+type GeoPoint struct {
+	Lat float64 `json:"lat" binding:"gte=-90,lte=90"`
+	Lng float64 `json:"lng" binding:"gte=-180,lte=180"`
+}
+
+// GeoArea — круг поиска вокруг точки
+type GeoArea struct {
+	GeoPoint
+	RadiusKm float64
+}
+
+type TripStopRequest struct {
+	City        string    `json:"city" binding:"required"`
+	ArrivalTime time.Time `json:"arrival_time" binding:"required"`
+	// DistanceKm и Fare — расстояние и цена места от города отправления, необязательны
+	DistanceKm *int `json:"distance_km" binding:"omitempty,gte=0"`
+	Fare       *int `json:"fare" binding:"omitempty,gte=0"`
+
+	Point *GeoPoint `json:"point"`
+
+	// CityID — город справочника, заполняет сервис
+	CityID *uint `json:"-"`
 }
 
 type TripFilter struct {
 	FromCity       *string
 	ToCity         *string
 	StartTime      *time.Time
+	StartTimeTo    *time.Time
 	AvailableSeats *int
 	TripStatus     *constants.TripStatus
+	// TripID сужает поиск до одной поездки: так новая поездка проверяется по сохранённым поискам
+	TripID *uint
+	// IncludeFull — показывать и заполненные поездки, чтобы на них можно было встать в очередь
+	IncludeFull bool
+
+	// FromCityID и ToCityID — найденные по названиям города справочника, заполняет сервис
+	FromCityID *uint
+	ToCityID   *uint
+	// Origin и Destination — поиск по расстоянию от точек пассажира; важнее названий городов
+	Origin      *GeoArea
+	Destination *GeoArea
+
+	// удобства автомобиля; nil — не важно
+	AirConditioning *bool
+	LuggageSpace    *bool
+	ChildSeat       *bool
+	PetsAllowed     *bool
+	SmokingAllowed  *bool
+
+	// MaxPrice — цена всей поездки, а не участка
+	MaxPrice     *int
+	MinRating    *float64
+	VerifiedOnly bool
+
+	// Sort — порядок выдачи, по умолчанию по времени отправления; Desc разворачивает его
+	Sort constants.TripSort
+	Desc *bool
 
 	Page     int
 	PageSize int
+	// Cursor — позиция из next_cursor предыдущей страницы; важнее номера страницы
+	Cursor string
+}
+
+type TripListResponse struct {
+	Items      []models.Trip `json:"items"`
+	Total      int64         `json:"total"`
+	Page       int           `json:"page"`
+	PageSize   int           `json:"page_size"`
+	NextCursor string        `json:"next_cursor,omitempty"`
 }
 
 type TripUpdateRequest struct {
-	FromCity       *string               `json:"from_city"`
-	ToCity         *string               `json:"to_city"`
-	StartTime      *time.Time            `json:"start_time"`
-	DurationMin    *int                  `json:"duration_min"`
-	AvailableSeats *int                  `json:"available_seats"`
-	Price          *int                  `json:"price"`
-	TripStatus     *constants.TripStatus `json:"trip_status"`
+	FromCity          *string                `json:"from_city"`
+	ToCity            *string                `json:"to_city"`
+	StartTime         *time.Time             `json:"start_time"`
+	DurationMin       *int                   `json:"duration_min" binding:"omitempty,min=1"`
+	AvailableSeats    *int                   `json:"available_seats" binding:"omitempty,min=0"`
+	Price             *int                   `json:"price" binding:"omitempty,min=0"`
+	BookingMode       *constants.BookingMode `json:"booking_mode" binding:"omitempty,oneof=manual instant"`
+	PendingTimeoutMin *int                   `json:"pending_timeout_min" binding:"omitempty,min=1"`
+	FromPoint         *GeoPoint              `json:"from_point"`
+	ToPoint           *GeoPoint              `json:"to_point"`
+}
+
+type TripCancelRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
 }
@@ -0,0 +1,38 @@
+package dto
+
+import "github.com/mutsaevz/team-5-ambitious/internal/constants"
+
+// Даты — в формате 2006-01-02, время отправления — 15:04 в часовом поясе шаблона
+type TripTemplateCreateRequest struct {
+	// CarID — автомобиль водителя; можно не указывать, если автомобиль один
+	CarID             uint                  `json:"car_id"`
+	FromCity          string                `json:"from_city" binding:"required"`
+	ToCity            string                `json:"to_city" binding:"required"`
+	DurationMin       int                   `json:"duration_min" binding:"required,min=1"`
+	Price             int                   `json:"price" binding:"min=0"`
+	BookingMode       constants.BookingMode `json:"booking_mode" binding:"omitempty,oneof=manual instant"`
+	PendingTimeoutMin int                   `json:"pending_timeout_min" binding:"omitempty,min=1"`
+
+	Weekdays      []int    `json:"weekdays" binding:"required,min=1,dive,min=1,max=7"`
+	DepartureTime string   `json:"departure_time" binding:"required"`
+	Timezone      string   `json:"timezone"`
+	StartDate     string   `json:"start_date" binding:"required"`
+	EndDate       *string  `json:"end_date"`
+	Exceptions    []string `json:"exceptions"`
+}
+
+type TripTemplateUpdateRequest struct {
+	FromCity          *string                `json:"from_city"`
+	ToCity            *string                `json:"to_city"`
+	DurationMin       *int                   `json:"duration_min" binding:"omitempty,min=1"`
+	Price             *int                   `json:"price" binding:"omitempty,min=0"`
+	BookingMode       *constants.BookingMode `json:"booking_mode" binding:"omitempty,oneof=manual instant"`
+	PendingTimeoutMin *int                   `json:"pending_timeout_min" binding:"omitempty,min=1"`
+
+	Weekdays      []int    `json:"weekdays" binding:"omitempty,min=1,dive,min=1,max=7"`
+	DepartureTime *string  `json:"departure_time"`
+	Timezone      *string  `json:"timezone"`
+	StartDate     *string  `json:"start_date"`
+	EndDate       *string  `json:"end_date"`
+	Exceptions    []string `json:"exceptions"`
+}
@@ -1,12 +1,45 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+)
+
 type UserCreateRequest struct {
-	Name    string `json:"name"`
-	Phone   string `json:"phone"`
-	Balance int    `json:"balance"`
+	Name  string `json:"name"`
+	Phone string `json:"phone"`
 }
 
 type UserUpdateRequest struct {
 	Name  *string `json:"name"`
 	Phone *string `json:"phone"`
+	// PhoneCode — код, отправленный на новый номер; без него номер не меняется
+	PhoneCode *string `json:"phone_code"`
+}
+
+type UserPhoneCodeRequest struct {
+	Phone string `json:"phone" binding:"required"`
+}
+
+type UserRoleRequest struct {
+	Role constants.UserRole `json:"role" binding:"required"`
+}
+
+// UserPublic — профиль, который видят другие пользователи: без телефона и баланса
+type UserPublic struct {
+	ID                  uint               `json:"id"`
+	Name                string             `json:"name"`
+	Role                constants.UserRole `json:"role"`
+	DriverVerifiedUntil *time.Time         `json:"driver_verified_until,omitempty"`
+}
+
+func NewUserPublic(user *models.User) *UserPublic {
+	return &UserPublic{
+		ID:                  user.ID,
+		Name:                user.Name,
+		Role:                user.Role,
+		DriverVerifiedUntil: user.DriverVerifiedUntil,
+	}
 }
@@ -0,0 +1,14 @@
+package dto
+
+import "time"
+
+// VerificationSubmitRequest — поля multipart-формы; файлы передаются в полях license и registration
+type VerificationSubmitRequest struct {
+	CarID            uint      `form:"car_id"`
+	LicenseNumber    string    `form:"license_number" binding:"required,max=50"`
+	LicenseExpiresAt time.Time `form:"license_expires_at" binding:"required" time_format:"2006-01-02"`
+}
+
+type VerificationRejectRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
@@ -0,0 +1,7 @@
+package dto
+
+type WaitlistJoinRequest struct {
+	Seats int `json:"seats" binding:"omitempty,min=1"` // по умолчанию одно место
+	// AutoBook — бронировать сразу при освобождении мест, без отдельного подтверждения
+	AutoBook bool `json:"auto_book"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+type AuthCode struct {
+	Base
+
+	Phone     string     `json:"phone" gorm:"type:varchar(20);not null;index"`
+	CodeHash  string     `json:"-" gorm:"type:varchar(64);not null"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null;index"`
+	Attempts  int        `json:"attempts" gorm:"not null;default:0"`
+	UsedAt    *time.Time `json:"used_at"`
+}
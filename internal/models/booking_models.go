@@ -5,7 +5,21 @@ import "github.com/mutsaevz/team-5-ambitious/internal/constants"
 type Booking struct {
 	Base
 
-	TripID        uint   `json:"trip_id" gorm:"not null;index"`
-	PassengerID   uint   `json:"passenger_id" gorm:"not null;index"`
+	// одна активная заявка пассажира на поездку, гонки при создании ловит этот индекс
+	TripID      uint `json:"trip_id" gorm:"not null;index;uniqueIndex:idx_booking_active_passenger,priority:1,where:(booking_status = 'pending' OR booking_status = 'approved') AND deleted_at IS NULL"`
+	PassengerID uint `json:"passenger_id" gorm:"not null;index;uniqueIndex:idx_booking_active_passenger,priority:2"`
+	Seats       int  `json:"seats" gorm:"not null;default:1;check:seats > 0"`
+	// участок маршрута: позиции остановок посадки и высадки
+	FromStop      int                     `json:"from_stop" gorm:"not null;default:0"`
+	ToStop        int                     `json:"to_stop" gorm:"not null;default:1"`
 	BookingStatus constants.BookingStatus `json:"booking_status" gorm:"type:varchar(50);not null;index"`
+	// цена, зафиксированная при создании брони; изменение цены поездки на неё не влияет
+	PricePerSeat int `json:"price_per_seat" gorm:"not null;default:0;check:price_per_seat >= 0"`
+	TotalPrice   int `json:"total_price" gorm:"not null;default:0;check:total_price >= 0"`
+	Commission   int `json:"commission" gorm:"not null;default:0;check:commission >= 0"`
+	// скидка по промокоду: TotalPrice уже уменьшен на неё, разницу водителю доплачивает платформа
+	PromoCodeID *uint `json:"promo_code_id,omitempty"`
+	Discount    int   `json:"discount" gorm:"not null;default:0;check:discount >= 0"`
+	// PenaltyFreeCancel — водитель существенно изменил поездку, пассажир может отменить бронь без штрафа
+	PenaltyFreeCancel bool `json:"penalty_free_cancel" gorm:"not null;default:false"`
 }
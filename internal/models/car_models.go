@@ -7,4 +7,37 @@ type Car struct {
 	Brand    string `json:"brand" gorm:"type:varchar(255);not null"`
 	CarModel string `json:"car_model" gorm:"type:varchar(255);not null"`
 	Seats    int    `json:"seats" gorm:"not null;check:seats > 0"`
+
+	// Plate — госномер в нормализованном виде: латиница и цифры без пробелов;
+	// у автомобилей, добавленных до появления номеров, пуст
+	Plate *string `json:"plate,omitempty" gorm:"type:varchar(20);uniqueIndex:idx_cars_plate,where:deleted_at IS NULL"`
+	Color string  `json:"color,omitempty" gorm:"type:varchar(50)"`
+	Year  int     `json:"year,omitempty" gorm:"not null;default:0"`
+
+	Amenities CarAmenities `json:"amenities" gorm:"embedded"`
+
+	// Verified — регистрация автомобиля подтверждена; сбрасывается при смене номера
+	Verified bool `json:"verified" gorm:"not null;default:false"`
+
+	Photos []CarPhoto `json:"photos,omitempty" gorm:"foreignKey:CarID"`
+}
+
+// CarAmenities — удобства в салоне; по ним фильтруется поиск поездок
+type CarAmenities struct {
+	AirConditioning bool `json:"air_conditioning" gorm:"not null;default:false"`
+	LuggageSpace    bool `json:"luggage_space" gorm:"not null;default:false"`
+	ChildSeat       bool `json:"child_seat" gorm:"not null;default:false"`
+	PetsAllowed     bool `json:"pets_allowed" gorm:"not null;default:false"`
+	SmokingAllowed  bool `json:"smoking_allowed" gorm:"not null;default:false"`
+}
+
+// CarPhoto — фотография автомобиля; сам файл лежит в хранилище под ключом Key
+type CarPhoto struct {
+	Base
+
+	CarID       uint   `json:"car_id" gorm:"not null;index"`
+	Key         string `json:"-" gorm:"type:varchar(255);not null"`
+	URL         string `json:"url" gorm:"-"`
+	ContentType string `json:"content_type" gorm:"type:varchar(50);not null"`
+	Size        int64  `json:"size" gorm:"not null"`
 }
@@ -0,0 +1,21 @@
+package models
+
+// City — населённый пункт справочника. Поездки и остановки ссылаются на него по ID,
+// чтобы «Грозный», «грозный» и «Grozny» были одним городом
+type City struct {
+	Base
+
+	Name   string `json:"name" gorm:"type:varchar(100);not null;uniqueIndex:idx_city_name_region,priority:1"`
+	Region string `json:"region" gorm:"type:varchar(100);not null;default:'';uniqueIndex:idx_city_name_region,priority:2"`
+
+	// Aliases — другие названия: латиницей, сокращения, прежние имена.
+	// Транслитерацию канонического названия добавлять не нужно, она учитывается при поиске
+	Aliases []CityAlias `json:"aliases,omitempty" gorm:"foreignKey:CityID"`
+}
+
+type CityAlias struct {
+	Base
+
+	CityID uint   `json:"-" gorm:"not null;index"`
+	Name   string `json:"name" gorm:"type:varchar(100);not null"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "github.com/mutsaevz/team-5-ambitious/internal/constants"
+
+// LedgerEntry — одна проводка двойной записи. Проводки одной операции
+// имеют общий TransactionID, а сумма их Amount равна нулю.
+type LedgerEntry struct {
+	Base
+
+	TransactionID string                  `json:"transaction_id" gorm:"type:varchar(32);not null;index"`
+	UserID        *uint                   `json:"user_id" gorm:"index"`
+	Account       constants.LedgerAccount `json:"account" gorm:"type:varchar(30);not null;index"`
+	Amount        int                     `json:"amount" gorm:"not null"`
+	Kind          constants.LedgerKind    `json:"kind" gorm:"type:varchar(50);not null"`
+	BookingID     *uint                   `json:"booking_id" gorm:"index"`
+	PaymentID     *uint                   `json:"payment_id" gorm:"index"`
+}
@@ -6,4 +6,6 @@ type Page struct {
 	TripID   *uint
 	AuthorID *uint
 	LastID   *uint
+	// Cursor — позиция из next_cursor предыдущей страницы; важнее номера страницы
+	Cursor string
 }
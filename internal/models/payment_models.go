@@ -0,0 +1,15 @@
+package models
+
+import "github.com/mutsaevz/team-5-ambitious/internal/constants"
+
+type PaymentIntent struct {
+	Base
+
+	UserID        uint                    `json:"user_id" gorm:"not null;index"`
+	Kind          constants.PaymentKind   `json:"kind" gorm:"type:varchar(20);not null"`
+	Amount        int                     `json:"amount" gorm:"not null;check:amount > 0"`
+	Status        constants.PaymentStatus `json:"status" gorm:"type:varchar(20);not null;index"`
+	Provider      string                  `json:"provider" gorm:"type:varchar(50);not null"`
+	ProviderRef   *string                 `json:"provider_ref" gorm:"type:varchar(100);uniqueIndex"`
+	FailureReason string                  `json:"failure_reason,omitempty" gorm:"type:varchar(255)"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+)
+
+// PromoCode — маркетинговый код скидки на бронь
+type PromoCode struct {
+	Base
+
+	Code          string                      `json:"code" gorm:"type:varchar(50);not null;uniqueIndex"`
+	DiscountType  constants.PromoDiscountType `json:"discount_type" gorm:"type:varchar(20);not null"`
+	DiscountValue int                         `json:"discount_value" gorm:"not null;check:discount_value > 0"`
+	// MaxUses — общий лимит использований, 0 — без ограничений; PerUserLimit — на одного пассажира
+	MaxUses       int        `json:"max_uses" gorm:"not null;default:0;check:max_uses >= 0"`
+	PerUserLimit  int        `json:"per_user_limit" gorm:"not null;default:1;check:per_user_limit >= 0"`
+	FirstTripOnly bool       `json:"first_trip_only" gorm:"not null;default:false"`
+	ValidFrom     *time.Time `json:"valid_from"`
+	ValidUntil    *time.Time `json:"valid_until"`
+	Active        bool       `json:"active" gorm:"not null;default:true"`
+}
+
+// PromoRedemption — применение кода к брони. Запись удаляется, когда бронь
+// отклоняют, отменяют или она истекает: после этого код можно применить снова.
+type PromoRedemption struct {
+	Base
+
+	PromoCodeID uint `json:"promo_code_id" gorm:"not null;index"`
+	UserID      uint `json:"user_id" gorm:"not null;index"`
+	BookingID   uint `json:"booking_id" gorm:"not null;uniqueIndex"`
+	Amount      int  `json:"amount" gorm:"not null;check:amount >= 0"`
+}
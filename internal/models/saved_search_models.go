@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// SavedSearch — подписка пассажира на поиск поездок: о новой подходящей поездке приходит уведомление.
+// Незаданные условия не ограничивают поиск; после ExpiresAt подписка не срабатывает
+type SavedSearch struct {
+	Base
+
+	UserID uint `json:"user_id" gorm:"not null;index"`
+
+	FromCity   *string `json:"from_city,omitempty" gorm:"type:varchar(100)"`
+	ToCity     *string `json:"to_city,omitempty" gorm:"type:varchar(100)"`
+	FromCityID *uint   `json:"from_city_id,omitempty" gorm:"index"`
+	ToCityID   *uint   `json:"to_city_id,omitempty" gorm:"index"`
+
+	// поиск по расстоянию от точек пассажира; важнее городов
+	FromLat      *float64 `json:"from_lat,omitempty"`
+	FromLng      *float64 `json:"from_lng,omitempty"`
+	FromRadiusKm *float64 `json:"from_radius_km,omitempty"`
+	ToLat        *float64 `json:"to_lat,omitempty"`
+	ToLng        *float64 `json:"to_lng,omitempty"`
+	ToRadiusKm   *float64 `json:"to_radius_km,omitempty"`
+
+	StartTime    *time.Time `json:"start_time,omitempty"`
+	StartTimeTo  *time.Time `json:"start_time_to,omitempty"`
+	Seats        int        `json:"seats" gorm:"not null;default:1;check:seats > 0"`
+	MaxPrice     *int       `json:"max_price,omitempty"`
+	MinRating    *float64   `json:"min_rating,omitempty"`
+	VerifiedOnly bool       `json:"verified_only" gorm:"not null;default:false"`
+
+	AirConditioning *bool `json:"air_conditioning,omitempty"`
+	LuggageSpace    *bool `json:"luggage_space,omitempty"`
+	ChildSeat       *bool `json:"child_seat,omitempty"`
+	PetsAllowed     *bool `json:"pets_allowed,omitempty"`
+	SmokingAllowed  *bool `json:"smoking_allowed,omitempty"`
+
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null;index"`
+}
+
+// SavedSearchMatch — поездка, о которой подписчик уже получил уведомление; повторно не сообщаем
+type SavedSearchMatch struct {
+	Base
+
+	SavedSearchID uint `json:"saved_search_id" gorm:"not null;uniqueIndex:idx_saved_search_match,priority:1"`
+	TripID        uint `json:"trip_id" gorm:"not null;uniqueIndex:idx_saved_search_match,priority:2"`
+}
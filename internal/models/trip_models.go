@@ -2,20 +2,51 @@ package models
 
 import (
 	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
 )
 
 type Trip struct {
 	Base
 
-	DriverID       uint      `json:"driver_id" gorm:"not null;index"`
-	CarID          uint      `json:"car_id" gorm:"not null;index"`
-	FromCity       string    `json:"from_city" gorm:"type:varchar(100);not null;index"`
-	ToCity         string    `json:"to_city" gorm:"type:varchar(100);not null;index"`
-	StartTime      time.Time `json:"start_time" gorm:"not null;index"`
-	DurationMin    int       `json:"duration_min" gorm:"not null"`
-	TotalSeats     int       `json:"total_seats" gorm:"not null;check:total_seats > 0"`
-	AvailableSeats int       `json:"available_seats" gorm:"not null;index;check:available_seats >= 0"`
-	Price          int       `json:"price" gorm:"not null;check:price >= 0"`
-	TripStatus     string    `json:"trip_status" gorm:"type:varchar(50);not null;index"`
-	AvgRating      float64   `json:"avg_rating" gorm:"default:0.0;check:avg_rating >= 0 AND avg_rating <= 5"`
+	DriverID       uint                  `json:"driver_id" gorm:"not null;index"`
+	CarID          uint                  `json:"car_id" gorm:"not null;index"`
+	FromCity       string                `json:"from_city" gorm:"type:varchar(100);not null;index"`
+	ToCity         string                `json:"to_city" gorm:"type:varchar(100);not null;index"`
+	StartTime      time.Time             `json:"start_time" gorm:"not null;index;index:idx_trip_status_start,priority:2"`
+	DurationMin    int                   `json:"duration_min" gorm:"not null"`
+	TotalSeats     int                   `json:"total_seats" gorm:"not null;check:total_seats > 0"`
+	AvailableSeats int                   `json:"available_seats" gorm:"not null;index;check:available_seats >= 0"`
+	Price          int                   `json:"price" gorm:"not null;index;check:price >= 0"`
+	TripStatus     string                `json:"trip_status" gorm:"type:varchar(50);not null;index;index:idx_trip_status_start,priority:1"`
+	BookingMode    constants.BookingMode `json:"booking_mode" gorm:"type:varchar(20);not null;default:'manual'"`
+	// PendingTimeoutMin — срок ответа на заявку в минутах, 0 — общий из конфигурации
+	PendingTimeoutMin int `json:"pending_timeout_min" gorm:"not null;default:0;check:pending_timeout_min >= 0"`
+	// TemplateID — регулярный рейс, по которому создана поездка; TemplateDate — дата
+	// отправления в часовом поясе шаблона: на одну дату рейс даёт не больше одной поездки
+	TemplateID   *uint   `json:"template_id,omitempty" gorm:"index;uniqueIndex:idx_trip_template_date,priority:1,where:deleted_at IS NULL"`
+	TemplateDate *string `json:"template_date,omitempty" gorm:"type:varchar(10);uniqueIndex:idx_trip_template_date,priority:2"`
+	AvgRating    float64 `json:"avg_rating" gorm:"default:0.0;index;check:avg_rating >= 0 AND avg_rating <= 5"`
+
+	// DriverVerified — у водителя действующая проверка; не хранится, заполняется при выдаче
+	DriverVerified bool `json:"driver_verified" gorm:"-"`
+
+	// FromCityID и ToCityID — города из справочника; у старых поездок могут быть не заданы
+	FromCityID *uint `json:"from_city_id,omitempty" gorm:"index"`
+	ToCityID   *uint `json:"to_city_id,omitempty" gorm:"index"`
+
+	// точки посадки и высадки внутри городов; у поездок без координат не заданы
+	FromLat *float64 `json:"from_lat,omitempty" gorm:"index:idx_trip_from_point,priority:1"`
+	FromLng *float64 `json:"from_lng,omitempty" gorm:"index:idx_trip_from_point,priority:2"`
+	ToLat   *float64 `json:"to_lat,omitempty" gorm:"index:idx_trip_to_point,priority:1"`
+	ToLng   *float64 `json:"to_lng,omitempty" gorm:"index:idx_trip_to_point,priority:2"`
+
+	// фактические отметки жизненного цикла: StartTime — плановое отправление,
+	// FinishedAt — время завершения или отмены
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+	CancelReason string     `json:"cancel_reason,omitempty" gorm:"type:varchar(500)"`
+
+	// Stops — маршрут по порядку; у поездок, созданных до появления остановок, пуст
+	Stops []TripStop `json:"stops,omitempty" gorm:"foreignKey:TripID"`
 }
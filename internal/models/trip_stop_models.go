@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// TripStop — точка маршрута поездки. Позиция 0 — город отправления, последняя — город прибытия.
+// BookedSeats — сколько мест занято на участке от этой остановки до следующей.
+// DistanceKm и Fare — расстояние и цена места от города отправления, если их указал водитель.
+// Lat и Lng — точка посадки на остановке, если водитель её указал.
+type TripStop struct {
+	Base
+
+	TripID      uint      `json:"trip_id" gorm:"not null;index;uniqueIndex:idx_trip_stop_position,priority:1"`
+	Position    int       `json:"position" gorm:"not null;uniqueIndex:idx_trip_stop_position,priority:2"`
+	City        string    `json:"city" gorm:"type:varchar(100);not null;index"`
+	CityID      *uint     `json:"city_id,omitempty" gorm:"index"`
+	Lat         *float64  `json:"lat,omitempty" gorm:"index:idx_trip_stop_point,priority:1"`
+	Lng         *float64  `json:"lng,omitempty" gorm:"index:idx_trip_stop_point,priority:2"`
+	ArrivalTime time.Time `json:"arrival_time" gorm:"not null"`
+	BookedSeats int       `json:"booked_seats" gorm:"not null;default:0;check:booked_seats >= 0"`
+	DistanceKm  *int      `json:"distance_km,omitempty" gorm:"check:distance_km >= 0"`
+	Fare        *int      `json:"fare,omitempty" gorm:"check:fare >= 0"`
+}
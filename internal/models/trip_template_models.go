@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+)
+
+// TripTemplate — регулярный рейс водителя, по которому планировщик создаёт конкретные поездки
+type TripTemplate struct {
+	Base
+
+	DriverID    uint   `json:"driver_id" gorm:"not null;index"`
+	CarID       uint   `json:"car_id" gorm:"not null;index"`
+	FromCity    string `json:"from_city" gorm:"type:varchar(100);not null"`
+	ToCity      string `json:"to_city" gorm:"type:varchar(100);not null"`
+	FromCityID  *uint  `json:"from_city_id,omitempty"`
+	ToCityID    *uint  `json:"to_city_id,omitempty"`
+	DurationMin int    `json:"duration_min" gorm:"not null"`
+	Price       int    `json:"price" gorm:"not null;check:price >= 0"`
+
+	BookingMode       constants.BookingMode `json:"booking_mode" gorm:"type:varchar(20);not null;default:'manual'"`
+	PendingTimeoutMin int                   `json:"pending_timeout_min" gorm:"not null;default:0"`
+
+	// правило повторения: дни недели (1 — понедельник … 7 — воскресенье), время отправления
+	// в часовом поясе шаблона, период действия и даты-исключения в формате 2006-01-02
+	Weekdays      []int      `json:"weekdays" gorm:"serializer:json;type:jsonb;not null"`
+	DepartureTime string     `json:"departure_time" gorm:"type:varchar(5);not null"`
+	Timezone      string     `json:"timezone" gorm:"type:varchar(64);not null;default:'Europe/Moscow'"`
+	StartDate     time.Time  `json:"start_date" gorm:"type:date;not null"`
+	EndDate       *time.Time `json:"end_date" gorm:"type:date"`
+	Exceptions    []string   `json:"exceptions" gorm:"serializer:json;type:jsonb"`
+
+	Active bool `json:"active" gorm:"not null;default:true;index"`
+}
@@ -1,9 +1,25 @@
 package models
 
+import (
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+)
+
 type User struct {
 	Base
 
-	Name    string `json:"name" gorm:"type:varchar(255);not null"`
-	Phone   string `json:"phone" gorm:"type:varchar(20);not null;unique;index"`
-	Balance int    `json:"balance" gorm:"not null;default:0;check:balance >= 0"`
+	Name    string             `json:"name" gorm:"type:varchar(255);not null"`
+	Phone   string             `json:"phone" gorm:"type:varchar(20);not null;unique;index"`
+	Balance int                `json:"balance" gorm:"not null;default:0;check:balance >= 0"`
+	Role    constants.UserRole `json:"role" gorm:"type:varchar(20);not null;default:'passenger';index"`
+
+	// ReferralCode выдаётся по запросу; ReferredByID — кто пригласил пользователя.
+	// ReferralRewarded — бонусы за приглашение уже начислены.
+	ReferralCode     *string `json:"referral_code,omitempty" gorm:"type:varchar(20);uniqueIndex"`
+	ReferredByID     *uint   `json:"referred_by_id,omitempty" gorm:"index"`
+	ReferralRewarded bool    `json:"-" gorm:"not null;default:false"`
+
+	// DriverVerifiedUntil — до какой даты действует подтверждённое удостоверение водителя
+	DriverVerifiedUntil *time.Time `json:"driver_verified_until,omitempty"`
 }
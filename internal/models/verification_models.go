@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+)
+
+// VerificationRequest — заявка водителя на проверку удостоверения и автомобиля.
+// Файлы документов лежат в закрытом хранилище и доступны только администратору
+type VerificationRequest struct {
+	Base
+
+	DriverID uint `json:"driver_id" gorm:"not null;index"`
+	CarID    uint `json:"car_id" gorm:"not null;index"`
+	// Plate — номер автомобиля на момент подачи: документы подтверждают именно его
+	Plate *string `json:"plate,omitempty" gorm:"type:varchar(20)"`
+
+	LicenseNumber    string    `json:"license_number" gorm:"type:varchar(50);not null"`
+	LicenseExpiresAt time.Time `json:"license_expires_at" gorm:"not null;index"`
+
+	LicenseKey              string `json:"-" gorm:"type:varchar(255);not null"`
+	LicenseContentType      string `json:"-" gorm:"type:varchar(50);not null"`
+	RegistrationKey         string `json:"-" gorm:"type:varchar(255);not null"`
+	RegistrationContentType string `json:"-" gorm:"type:varchar(50);not null"`
+
+	Status       constants.VerificationStatus `json:"status" gorm:"type:varchar(20);not null;default:'pending';index"`
+	RejectReason string                       `json:"reject_reason,omitempty" gorm:"type:varchar(500)"`
+	ReviewerID   *uint                        `json:"reviewer_id,omitempty"`
+	ReviewedAt   *time.Time                   `json:"reviewed_at,omitempty"`
+	SubmittedAt  time.Time                    `json:"submitted_at" gorm:"not null;index"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+)
+
+// WaitlistEntry — место пассажира в очереди на заполненную поездку; порядок очереди — по ID
+type WaitlistEntry struct {
+	Base
+
+	TripID         uint                     `json:"trip_id" gorm:"not null;index"`
+	PassengerID    uint                     `json:"passenger_id" gorm:"not null;index"`
+	Seats          int                      `json:"seats" gorm:"not null;default:1;check:seats > 0"`
+	AutoBook       bool                     `json:"auto_book" gorm:"not null;default:false"`
+	Status         constants.WaitlistStatus `json:"status" gorm:"type:varchar(20);not null;index"`
+	OfferExpiresAt *time.Time               `json:"offer_expires_at"`
+	BookingID      *uint                    `json:"booking_id"`
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"gorm.io/gorm"
+)
+
+type AuthCodeRepository interface {
+	Create(code *models.AuthCode) error
+
+	GetActiveByPhone(phone string, now time.Time) (*models.AuthCode, error)
+
+	TakeAttempt(code *models.AuthCode, since time.Time, limit int) (bool, error)
+
+	MarkUsed(id uint, at time.Time) error
+
+	CountSince(phone string, since time.Time) (int64, error)
+}
+
+type gormAuthCodeRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewAuthCodeRepository(db *gorm.DB, logger *slog.Logger) AuthCodeRepository {
+	return &gormAuthCodeRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *gormAuthCodeRepository) Create(code *models.AuthCode) error {
+	op := "repository.auth_code.create"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.String("phone", code.Phone),
+	)
+
+	if err := r.db.Create(code).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *gormAuthCodeRepository) GetActiveByPhone(phone string, now time.Time) (*models.AuthCode, error) {
+	op := "repository.auth_code.get_active_by_phone"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.String("phone", phone),
+	)
+
+	var code models.AuthCode
+
+	if err := r.db.
+		Where("phone = ? AND used_at IS NULL AND expires_at > ?", phone, now).
+		Order("id DESC").
+		First(&code).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return &code, nil
+}
+
+// TakeAttempt атомарно расходует попытку ввода кода. Попытки суммируются по всем
+// непогашенным кодам номера, выданным начиная с since: проверка и увеличение счётчика —
+// один UPDATE, поэтому параллельные попытки не проскочат лимит. false — лимит исчерпан
+func (r *gormAuthCodeRepository) TakeAttempt(code *models.AuthCode, since time.Time, limit int) (bool, error) {
+	op := "repository.auth_code.take_attempt"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("code_id", uint64(code.ID)),
+	)
+
+	result := r.db.Model(&models.AuthCode{}).
+		Where(`id = ? AND attempts + (
+			SELECT COALESCE(SUM(o.attempts), 0) FROM auth_codes o
+			WHERE o.phone = ? AND o.id <> ? AND o.used_at IS NULL AND o.created_at >= ? AND o.deleted_at IS NULL
+		) < ?`, code.ID, code.Phone, code.ID, since, limit).
+		Update("attempts", gorm.Expr("attempts + 1"))
+
+	if result.Error != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", result.Error))
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (r *gormAuthCodeRepository) MarkUsed(id uint, at time.Time) error {
+	op := "repository.auth_code.mark_used"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("code_id", uint64(id)),
+	)
+
+	// условие на used_at не даёт двум параллельным входам погасить один код дважды
+	result := r.db.Model(&models.AuthCode{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+
+	if result.Error != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", result.Error))
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// CountSince считает коды, выданные на номер начиная с since
+func (r *gormAuthCodeRepository) CountSince(phone string, since time.Time) (int64, error) {
+	op := "repository.auth_code.count_since"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.String("phone", phone),
+	)
+
+	var count int64
+
+	if err := r.db.Model(&models.AuthCode{}).
+		Where("phone = ? AND created_at >= ?", phone, since).
+		Count(&count).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return 0, err
+	}
+
+	return count, nil
+}
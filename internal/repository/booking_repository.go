@@ -1,23 +1,43 @@
 package repository
 
 import (
+	"errors"
 	"log/slog"
+	"time"
 
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
 	"github.com/mutsaevz/team-5-ambitious/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type BookingRepository interface {
 	Create(booking *models.Booking) error
 
-	List(filter models.Page) ([]models.Booking, error)
+	List(filter models.Page) (*dto.Page[models.Booking], error)
+
+	// ListByUser — брони пассажира и брони на поездки, где он водитель
+	ListByUser(userID uint, filter models.Page) (*dto.Page[models.Booking], error)
 
 	GetByID(id uint) (*models.Booking, error)
 
+	GetByIDForUpdate(id uint) (*models.Booking, error)
+
 	GetAllPendingBookingsByTripID(driverID, tripID uint) ([]models.Booking, error)
 
 	Exists(tripID uint, passengerID uint) (bool, error)
 
+	ListByTripAndStatus(tripID uint, status constants.BookingStatus) ([]models.Booking, error)
+
+	ListActiveByTripForUpdate(tripID uint) ([]models.Booking, error)
+
+	MarkPenaltyFree(ids []uint) error
+
+	ListExpirable(now time.Time, defaultTimeout time.Duration) ([]models.Booking, error)
+
+	CountCompletedByPassenger(passengerID uint) (int64, error)
+
 	Update(booking *models.Booking) error
 
 	Delete(id uint) error
@@ -47,6 +67,9 @@ func (r *gormBookingRepository) Create(booking *models.Booking) error {
 	)
 
 	if err := r.DB.Create(booking).Error; err != nil {
+		if isUniqueViolation(err) {
+			return ErrAlreadyExists
+		}
 		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
 		return err
 	}
@@ -54,13 +77,41 @@ func (r *gormBookingRepository) Create(booking *models.Booking) error {
 	return nil
 }
 
-func (r *gormBookingRepository) List(filter models.Page) ([]models.Booking, error) {
+func (r *gormBookingRepository) List(filter models.Page) (*dto.Page[models.Booking], error) {
 
 	op := "repository.booking.list"
 
 	r.logger.Debug("db call", slog.String("op", op))
 
-	var bookings []models.Booking
+	page := filter.Page
+	pageSize := filter.PageSize
+
+	if page < 1 {
+		page = 1
+	}
+
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 100
+	}
+
+	bookings, err := byID(false, func(b *models.Booking) uint { return b.ID }).
+		page(r.DB.Model(&models.Booking{}), filter.Cursor, page, pageSize)
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return bookings, nil
+
+}
+
+func (r *gormBookingRepository) ListByUser(userID uint, filter models.Page) (*dto.Page[models.Booking], error) {
+	op := "repository.booking.list_by_user"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("user_id", uint64(userID)),
+	)
 
 	page := filter.Page
 	pageSize := filter.PageSize
@@ -73,15 +124,17 @@ func (r *gormBookingRepository) List(filter models.Page) ([]models.Booking, erro
 		pageSize = 100
 	}
 
-	offset := (page - 1) * pageSize
+	query := r.DB.Model(&models.Booking{}).
+		Where("passenger_id = ? OR trip_id IN (SELECT id FROM trips WHERE driver_id = ? AND deleted_at IS NULL)", userID, userID)
 
-	if err := r.DB.Offset(offset).Limit(pageSize).Find(&bookings).Error; err != nil {
+	bookings, err := byID(false, func(b *models.Booking) uint { return b.ID }).
+		page(query, filter.Cursor, page, pageSize)
+	if err != nil {
 		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
 		return nil, err
 	}
 
 	return bookings, nil
-
 }
 
 func (r *gormBookingRepository) GetByID(id uint) (*models.Booking, error) {
@@ -96,6 +149,31 @@ func (r *gormBookingRepository) GetByID(id uint) (*models.Booking, error) {
 	var booking models.Booking
 
 	if err := r.DB.Where("id = ?", id).First(&booking).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+	return &booking, nil
+}
+
+// GetByIDForUpdate читает бронь с блокировкой строки до конца транзакции
+func (r *gormBookingRepository) GetByIDForUpdate(id uint) (*models.Booking, error) {
+
+	op := "repository.booking.get_by_id_for_update"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("booking_id", uint64(id)),
+	)
+
+	var booking models.Booking
+
+	if err := r.DB.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&booking).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
 		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
 		return nil, err
 	}
@@ -113,13 +191,17 @@ func (r *gormBookingRepository) GetAllPendingBookingsByTripID(driverID, tripID u
 
 	var bookings []models.Booking
 
-	if err := r.DB.Where("driver_id = ? AND trip_id = ? AND booking_status = ?", driverID, tripID, "pending").Find(&bookings).Error; err != nil {
+	if err := r.DB.
+		Joins("JOIN trips ON trips.id = bookings.trip_id").
+		Where("trips.driver_id = ? AND bookings.trip_id = ? AND bookings.booking_status = ?", driverID, tripID, constants.BookingPending).
+		Find(&bookings).Error; err != nil {
 		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
 		return nil, err
 	}
 	return bookings, nil
 }
 
+// Exists проверяет, есть ли у пассажира активная (ожидающая или подтверждённая) бронь на поездку
 func (r *gormBookingRepository) Exists(tripID uint, passengerID uint) (bool, error) {
 
 	op := "repository.booking.exists"
@@ -133,7 +215,9 @@ func (r *gormBookingRepository) Exists(tripID uint, passengerID uint) (bool, err
 	var count int64
 
 	if err := r.DB.Model(&models.Booking{}).
-		Where("trip_id = ? AND passenger_id = ?", tripID, passengerID).
+		Where("trip_id = ? AND passenger_id = ? AND booking_status IN ?",
+			tripID, passengerID,
+			[]constants.BookingStatus{constants.BookingPending, constants.BookingApproved}).
 		Count(&count).Error; err != nil {
 		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
 		return false, err
@@ -144,6 +228,120 @@ func (r *gormBookingRepository) Exists(tripID uint, passengerID uint) (bool, err
 	return exists, nil
 }
 
+func (r *gormBookingRepository) ListByTripAndStatus(tripID uint, status constants.BookingStatus) ([]models.Booking, error) {
+
+	op := "repository.booking.list_by_trip_and_status"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("trip_id", uint64(tripID)),
+		slog.String("status", string(status)),
+	)
+
+	var bookings []models.Booking
+
+	if err := r.DB.Where("trip_id = ? AND booking_status = ?", tripID, status).Find(&bookings).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return bookings, nil
+}
+
+// ListActiveByTripForUpdate блокирует ожидающие и одобренные брони поездки до конца транзакции
+func (r *gormBookingRepository) ListActiveByTripForUpdate(tripID uint) ([]models.Booking, error) {
+
+	op := "repository.booking.list_active_by_trip_for_update"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("trip_id", uint64(tripID)),
+	)
+
+	var bookings []models.Booking
+
+	if err := r.DB.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("trip_id = ? AND booking_status IN ?", tripID,
+			[]constants.BookingStatus{constants.BookingPending, constants.BookingApproved}).
+		Order("id ASC").
+		Find(&bookings).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return bookings, nil
+}
+
+// MarkPenaltyFree разрешает пассажирам отменить брони без штрафа
+func (r *gormBookingRepository) MarkPenaltyFree(ids []uint) error {
+
+	op := "repository.booking.mark_penalty_free"
+
+	r.logger.Debug("db call", slog.String("op", op), slog.Int("bookings", len(ids)))
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err := r.DB.Model(&models.Booking{}).
+		Where("id IN ?", ids).
+		Update("penalty_free_cancel", true).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+// ListExpirable возвращает заявки, которые пора перевести в expired: срок ответа
+// истёк (свой у поездки или defaultTimeout) либо поездка больше не опубликована
+func (r *gormBookingRepository) ListExpirable(now time.Time, defaultTimeout time.Duration) ([]models.Booking, error) {
+
+	op := "repository.booking.list_expirable"
+
+	r.logger.Debug("db call", slog.String("op", op))
+
+	var bookings []models.Booking
+
+	// удалённые поездки тоже попадают в выборку: их заявки больше некому подтверждать
+	if err := r.DB.
+		Joins("JOIN trips ON trips.id = bookings.trip_id").
+		Where("bookings.booking_status = ?", constants.BookingPending).
+		Where(`(trips.trip_status <> ? OR trips.deleted_at IS NOT NULL OR
+			bookings.created_at + make_interval(mins => CASE WHEN trips.pending_timeout_min > 0
+				THEN trips.pending_timeout_min ELSE ? END) <= ?)`,
+			constants.TripPublished, int(defaultTimeout.Minutes()), now).
+		Find(&bookings).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return bookings, nil
+}
+
+// CountCompletedByPassenger — сколько завершённых поездок у пассажира по одобренным броням
+func (r *gormBookingRepository) CountCompletedByPassenger(passengerID uint) (int64, error) {
+	op := "repository.booking.count_completed_by_passenger"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("passenger_id", uint64(passengerID)),
+	)
+
+	var count int64
+
+	if err := r.DB.Model(&models.Booking{}).
+		Joins("JOIN trips ON trips.id = bookings.trip_id").
+		Where("bookings.passenger_id = ? AND bookings.booking_status = ? AND trips.trip_status = ?",
+			passengerID, constants.BookingApproved, constants.TripCompleted).
+		Count(&count).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return 0, err
+	}
+
+	return count, nil
+}
+
 func (r *gormBookingRepository) Update(booking *models.Booking) error {
 
 	op := "repository.booking.update"
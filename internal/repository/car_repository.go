@@ -4,22 +4,44 @@ import (
 	"errors"
 	"log/slog"
 
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
 	"github.com/mutsaevz/team-5-ambitious/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CarRepository interface {
 	Create(car *models.Car) error
 
-	List(filter models.Page) ([]models.Car, error)
+	List(filter models.Page) (*dto.Page[models.Car], error)
 
-	GetByOwner(id uint) (*models.Car, error)
+	ListByOwner(ownerID uint) ([]models.Car, error)
 
 	Update(car *models.Car) (*models.Car, error)
 
 	Delete(id uint) error
 
 	GetByID(id uint) (*models.Car, error)
+
+	GetByIDForUpdate(id uint) (*models.Car, error)
+
+	GetByPlate(plate string) (*models.Car, error)
+
+	// MaxActiveTripSeats — наибольшее число мест среди опубликованных и идущих поездок автомобиля
+	MaxActiveTripSeats(carID uint) (int, error)
+
+	CountPhotos(carID uint) (int64, error)
+
+	CreatePhoto(photo *models.CarPhoto) error
+
+	GetPhoto(carID, photoID uint) (*models.CarPhoto, error)
+
+	DeletePhoto(photo *models.CarPhoto) error
+
+	SetVerified(id uint, verified bool) error
+
+	WithDB(db *gorm.DB) CarRepository
 }
 
 type gormCarRepository struct {
@@ -45,6 +67,10 @@ func (r *gormCarRepository) Create(car *models.Car) error {
 	err := r.db.Create(car).Error
 
 	if err != nil {
+		// номер успел занять параллельный запрос
+		if isUniqueViolation(err) {
+			return ErrAlreadyExists
+		}
 		r.logger.Error(
 			"Ошибка при создании автомобиля",
 			slog.String("error", err.Error()),
@@ -59,18 +85,26 @@ func (r *gormCarRepository) Create(car *models.Car) error {
 	return nil
 }
 
-func (r *gormCarRepository) GetByOwner(id uint) (*models.Car, error) {
-	var car models.Car
+func (r *gormCarRepository) ListByOwner(ownerID uint) ([]models.Car, error) {
+	r.logger.Info(
+		"Запрос автомобилей владельца",
+		slog.Uint64("owner_id", uint64(ownerID)),
+	)
 
-	if err := r.db.Where("owner_id = ?", id).First(&car).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrNotFound
-		}
+	var cars []models.Car
+
+	if err := r.db.Where("owner_id = ?", ownerID).Preload("Photos", orderPhotos).Order("id").Find(&cars).Error; err != nil {
+		r.logger.Error(
+			"Ошибка при получении автомобилей владельца",
+			slog.Uint64("owner_id", uint64(ownerID)),
+			slog.String("error", err.Error()),
+		)
 		return nil, err
 	}
 
-	return &car, nil
+	return cars, nil
 }
+
 func (r *gormCarRepository) GetByID(id uint) (*models.Car, error) {
 	r.logger.Info(
 		"Запрос автомобиля по ID",
@@ -79,7 +113,7 @@ func (r *gormCarRepository) GetByID(id uint) (*models.Car, error) {
 
 	var car models.Car
 
-	if err := r.db.First(&car, id).Error; err != nil {
+	if err := r.db.Preload("Photos", orderPhotos).First(&car, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			r.logger.Warn(
 				"Автомобиль не найден",
@@ -106,11 +140,9 @@ func (r *gormCarRepository) GetByID(id uint) (*models.Car, error) {
 	return &car, nil
 }
 
-func (r *gormCarRepository) List(filter models.Page) ([]models.Car, error) {
+func (r *gormCarRepository) List(filter models.Page) (*dto.Page[models.Car], error) {
 	r.logger.Info("Запрос списка автомобилей")
 
-	var cars []models.Car
-
 	page := filter.Page
 	pageSize := filter.PageSize
 
@@ -122,9 +154,9 @@ func (r *gormCarRepository) List(filter models.Page) ([]models.Car, error) {
 		pageSize = 100
 	}
 
-	offset := (page - 1) * pageSize
-
-	if err := r.db.Offset(offset).Limit(pageSize).Find(&cars).Error; err != nil {
+	cars, err := byID(false, func(c *models.Car) uint { return c.ID }).
+		page(r.db.Model(&models.Car{}).Preload("Photos", orderPhotos), filter.Cursor, page, pageSize)
+	if err != nil {
 		r.logger.Error(
 			"Ошибка при получении списка автомобилей",
 			slog.String("error", err.Error()),
@@ -134,7 +166,7 @@ func (r *gormCarRepository) List(filter models.Page) ([]models.Car, error) {
 
 	r.logger.Info(
 		"Список автомобилей успешно получен",
-		slog.Int("count", len(cars)),
+		slog.Int("count", len(cars.Items)),
 	)
 
 	return cars, nil
@@ -146,7 +178,27 @@ func (r *gormCarRepository) Update(car *models.Car) (*models.Car, error) {
 		slog.Uint64("car_id", uint64(car.ID)),
 	)
 
-	if err := r.db.Save(car).Error; err != nil {
+	// пишутся только редактируемые поля: отметку о проверке могли поставить, пока шла правка,
+	// поэтому её сбрасывает сама база и только если номер действительно сменился.
+	// Фотографии меняются только через CreatePhoto и DeletePhoto
+	err := r.db.Model(&models.Car{}).Where("id = ?", car.ID).Updates(map[string]any{
+		"brand":            car.Brand,
+		"car_model":        car.CarModel,
+		"seats":            car.Seats,
+		"plate":            car.Plate,
+		"color":            car.Color,
+		"year":             car.Year,
+		"air_conditioning": car.Amenities.AirConditioning,
+		"luggage_space":    car.Amenities.LuggageSpace,
+		"child_seat":       car.Amenities.ChildSeat,
+		"pets_allowed":     car.Amenities.PetsAllowed,
+		"smoking_allowed":  car.Amenities.SmokingAllowed,
+		"verified":         gorm.Expr("CASE WHEN plate IS DISTINCT FROM ? THEN false ELSE verified END", car.Plate),
+	}).Error
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrAlreadyExists
+		}
 		r.logger.Error(
 			"Ошибка при обновлении автомобиля",
 			slog.Uint64("car_id", uint64(car.ID)),
@@ -160,7 +212,7 @@ func (r *gormCarRepository) Update(car *models.Car) (*models.Car, error) {
 		slog.Uint64("car_id", uint64(car.ID)),
 	)
 
-	return car, nil
+	return r.GetByID(car.ID)
 }
 
 func (r *gormCarRepository) Delete(id uint) error {
@@ -185,3 +237,159 @@ func (r *gormCarRepository) Delete(id uint) error {
 
 	return nil
 }
+
+// GetByIDForUpdate читает автомобиль с блокировкой строки до конца транзакции
+func (r *gormCarRepository) GetByIDForUpdate(id uint) (*models.Car, error) {
+	var car models.Car
+
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&car, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+
+		r.logger.Error(
+			"Ошибка при получении автомобиля с блокировкой",
+			slog.Uint64("car_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	return &car, nil
+}
+
+func (r *gormCarRepository) GetByPlate(plate string) (*models.Car, error) {
+	var car models.Car
+
+	if err := r.db.Where("plate = ?", plate).First(&car).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		r.logger.Error(
+			"Ошибка при поиске автомобиля по номеру",
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	return &car, nil
+}
+
+func (r *gormCarRepository) MaxActiveTripSeats(carID uint) (int, error) {
+	var seats int
+
+	if err := r.db.Model(&models.Trip{}).
+		Select("COALESCE(MAX(total_seats), 0)").
+		Where("car_id = ? AND trip_status IN ?", carID,
+			[]constants.TripStatus{constants.TripPublished, constants.TripInProgress}).
+		Scan(&seats).Error; err != nil {
+		r.logger.Error(
+			"Ошибка при подсчёте мест в поездках автомобиля",
+			slog.Uint64("car_id", uint64(carID)),
+			slog.String("error", err.Error()),
+		)
+		return 0, err
+	}
+
+	return seats, nil
+}
+
+func (r *gormCarRepository) CountPhotos(carID uint) (int64, error) {
+	var count int64
+
+	if err := r.db.Model(&models.CarPhoto{}).Where("car_id = ?", carID).Count(&count).Error; err != nil {
+		r.logger.Error(
+			"Ошибка при подсчёте фотографий автомобиля",
+			slog.Uint64("car_id", uint64(carID)),
+			slog.String("error", err.Error()),
+		)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *gormCarRepository) CreatePhoto(photo *models.CarPhoto) error {
+	r.logger.Info(
+		"Добавление фотографии автомобиля",
+		slog.Uint64("car_id", uint64(photo.CarID)),
+	)
+
+	if err := r.db.Create(photo).Error; err != nil {
+		r.logger.Error(
+			"Ошибка при добавлении фотографии автомобиля",
+			slog.Uint64("car_id", uint64(photo.CarID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+
+	return nil
+}
+
+func (r *gormCarRepository) GetPhoto(carID, photoID uint) (*models.CarPhoto, error) {
+	var photo models.CarPhoto
+
+	if err := r.db.Where("car_id = ?", carID).First(&photo, photoID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		r.logger.Error(
+			"Ошибка при получении фотографии автомобиля",
+			slog.Uint64("photo_id", uint64(photoID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	return &photo, nil
+}
+
+func (r *gormCarRepository) DeletePhoto(photo *models.CarPhoto) error {
+	r.logger.Info(
+		"Удаление фотографии автомобиля",
+		slog.Uint64("photo_id", uint64(photo.ID)),
+	)
+
+	// файл удаляется из хранилища, поэтому и запись удаляется безвозвратно
+	if err := r.db.Unscoped().Delete(photo).Error; err != nil {
+		r.logger.Error(
+			"Ошибка при удалении фотографии автомобиля",
+			slog.Uint64("photo_id", uint64(photo.ID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+
+	return nil
+}
+
+func (r *gormCarRepository) SetVerified(id uint, verified bool) error {
+	r.logger.Info(
+		"Изменение отметки о проверке автомобиля",
+		slog.Uint64("car_id", uint64(id)),
+		slog.Bool("verified", verified),
+	)
+
+	if err := r.db.Model(&models.Car{}).Where("id = ?", id).Update("verified", verified).Error; err != nil {
+		r.logger.Error(
+			"Ошибка при изменении отметки о проверке автомобиля",
+			slog.Uint64("car_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+
+	return nil
+}
+
+func (r *gormCarRepository) WithDB(db *gorm.DB) CarRepository {
+	return &gormCarRepository{
+		db:     db,
+		logger: r.logger,
+	}
+}
+
+func orderPhotos(db *gorm.DB) *gorm.DB {
+	return db.Order("id ASC")
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"gorm.io/gorm"
+)
+
+type CityRepository interface {
+	// Create сохраняет город вместе с его названиями-синонимами
+	Create(city *models.City) error
+
+	GetByID(id uint) (*models.City, error)
+
+	// ListAll — весь справочник с синонимами; поиск по нему идёт в памяти
+	ListAll() ([]models.City, error)
+
+	Count() (int64, error)
+
+	CreateAlias(alias *models.CityAlias) error
+
+	// UnresolvedNames — названия городов в поездках, остановках и шаблонах без ссылки на справочник
+	UnresolvedNames() ([]string, error)
+
+	// AssignCity проставляет город справочника везде, где он записан названием name
+	AssignCity(name string, cityID uint) error
+}
+
+type gormCityRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewCityRepository(db *gorm.DB, logger *slog.Logger) CityRepository {
+	return &gormCityRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *gormCityRepository) Create(city *models.City) error {
+	op := "repository.city.create"
+
+	r.logger.Debug("db call", slog.String("op", op), slog.String("name", city.Name))
+
+	if err := r.db.Create(city).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *gormCityRepository) GetByID(id uint) (*models.City, error) {
+	op := "repository.city.get_by_id"
+
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("city_id", uint64(id)))
+
+	var city models.City
+
+	if err := r.db.Preload("Aliases").First(&city, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return &city, nil
+}
+
+func (r *gormCityRepository) ListAll() ([]models.City, error) {
+	op := "repository.city.list_all"
+
+	r.logger.Debug("db call", slog.String("op", op))
+
+	var cities []models.City
+
+	if err := r.db.Preload("Aliases").Order("id ASC").Find(&cities).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return cities, nil
+}
+
+func (r *gormCityRepository) Count() (int64, error) {
+	op := "repository.city.count"
+
+	r.logger.Debug("db call", slog.String("op", op))
+
+	var count int64
+
+	if err := r.db.Model(&models.City{}).Count(&count).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *gormCityRepository) CreateAlias(alias *models.CityAlias) error {
+	op := "repository.city.create_alias"
+
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("city_id", uint64(alias.CityID)))
+
+	if err := r.db.Create(alias).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *gormCityRepository) UnresolvedNames() ([]string, error) {
+	op := "repository.city.unresolved_names"
+
+	r.logger.Debug("db call", slog.String("op", op))
+
+	var names []string
+
+	if err := r.db.Raw(`
+		SELECT from_city FROM trips WHERE from_city_id IS NULL AND deleted_at IS NULL
+		UNION SELECT to_city FROM trips WHERE to_city_id IS NULL AND deleted_at IS NULL
+		UNION SELECT city FROM trip_stops WHERE city_id IS NULL AND deleted_at IS NULL
+		UNION SELECT from_city FROM trip_templates WHERE from_city_id IS NULL AND deleted_at IS NULL
+		UNION SELECT to_city FROM trip_templates WHERE to_city_id IS NULL AND deleted_at IS NULL`).
+		Scan(&names).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return names, nil
+}
+
+func (r *gormCityRepository) AssignCity(name string, cityID uint) error {
+	op := "repository.city.assign_city"
+
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("city_id", uint64(cityID)))
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		updates := []struct {
+			model  any
+			name   string
+			column string
+		}{
+			{&models.Trip{}, "from_city", "from_city_id"},
+			{&models.Trip{}, "to_city", "to_city_id"},
+			{&models.TripStop{}, "city", "city_id"},
+			{&models.TripTemplate{}, "from_city", "from_city_id"},
+			{&models.TripTemplate{}, "to_city", "to_city_id"},
+		}
+
+		for _, u := range updates {
+			if err := tx.Model(u.model).
+				Where(u.name+" = ? AND "+u.column+" IS NULL", name).
+				Update(u.column, cityID).Error; err != nil {
+				r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+				return err
+			}
+		}
+
+		return nil
+	})
+}
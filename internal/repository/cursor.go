@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// cursor — позиция последней выданной записи: значение колонки сортировки и id для равных значений.
+// Клиент получает его непрозрачной строкой и возвращает как есть
+type cursor struct {
+	Key json.RawMessage `json:"k,omitempty"`
+	ID  uint            `json:"i"`
+}
+
+func encodeCursor(key any, id uint) (string, error) {
+	raw, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(cursor{Key: raw, ID: id})
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor[K any](token string) (K, uint, error) {
+	var (
+		key K
+		c   cursor
+	)
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return key, 0, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == 0 {
+		return key, 0, ErrInvalidCursor
+	}
+	if len(c.Key) > 0 {
+		if err := json.Unmarshal(c.Key, &key); err != nil {
+			return key, 0, ErrInvalidCursor
+		}
+	}
+
+	return key, c.ID, nil
+}
+
+// keyset — порядок обхода списка по курсору: колонка сортировки, направление
+// и как получить у записи значение колонки и id
+type keyset[T any, K any] struct {
+	column string
+	desc   bool
+	key    func(item *T) (K, uint)
+}
+
+// byID — обход по id, когда у списка нет другой сортировки
+func byID[T any](desc bool, id func(item *T) uint) keyset[T, uint] {
+	return keyset[T, uint]{
+		column: "id",
+		desc:   desc,
+		key: func(item *T) (uint, uint) {
+			v := id(item)
+			return v, v
+		},
+	}
+}
+
+// page выбирает до pageSize записей после курсора и курсор следующей страницы.
+// Без курсора страница отсчитывается через offset, чтобы старые клиенты продолжали работать
+func (k keyset[T, K]) page(query *gorm.DB, token string, page, pageSize int) (*dto.Page[T], error) {
+	dir, cmp := "ASC", ">"
+	if k.desc {
+		dir, cmp = "DESC", "<"
+	}
+
+	if token != "" {
+		key, id, err := decodeCursor[K](token)
+		if err != nil {
+			return nil, err
+		}
+		if k.column == "id" {
+			query = query.Where("id "+cmp+" ?", id)
+		} else {
+			query = query.Where("("+k.column+", id) "+cmp+" (?, ?)", key, id)
+		}
+	} else if page > 1 {
+		query = query.Offset((page - 1) * pageSize)
+	}
+
+	order := "id " + dir
+	if k.column != "id" {
+		order = k.column + " " + dir + ", " + order
+	}
+
+	// лишняя запись показывает, есть ли следующая страница
+	items := make([]T, 0, pageSize+1)
+	if err := query.Order(order).Limit(pageSize + 1).Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	result := &dto.Page[T]{Items: items}
+	if len(items) > pageSize {
+		result.Items = items[:pageSize]
+
+		key, id := k.key(&items[pageSize-1])
+		next, err := encodeCursor(key, id)
+		if err != nil {
+			return nil, err
+		}
+		result.NextCursor = next
+	}
+
+	return result, nil
+}
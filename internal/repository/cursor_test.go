@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	t.Run("int key", func(t *testing.T) {
+		token, err := encodeCursor(1500, 42)
+		if err != nil {
+			t.Fatalf("encode: %v", err)
+		}
+		key, id, err := decodeCursor[int](token)
+		if err != nil || key != 1500 || id != 42 {
+			t.Errorf("decode = %d, %d, %v; want 1500, 42, nil", key, id, err)
+		}
+	})
+
+	t.Run("float key", func(t *testing.T) {
+		token, err := encodeCursor(4.5, 7)
+		if err != nil {
+			t.Fatalf("encode: %v", err)
+		}
+		key, id, err := decodeCursor[float64](token)
+		if err != nil || key != 4.5 || id != 7 {
+			t.Errorf("decode = %v, %d, %v; want 4.5, 7, nil", key, id, err)
+		}
+	})
+
+	t.Run("time key", func(t *testing.T) {
+		start := time.Date(2026, 10, 19, 8, 30, 0, 0, time.UTC)
+
+		token, err := encodeCursor(start, 3)
+		if err != nil {
+			t.Fatalf("encode: %v", err)
+		}
+		key, id, err := decodeCursor[time.Time](token)
+		if err != nil || !key.Equal(start) || id != 3 {
+			t.Errorf("decode = %v, %d, %v; want %v, 3, nil", key, id, err, start)
+		}
+	})
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	cases := []struct {
+		name  string
+		token string
+	}{
+		{name: "empty", token: ""},
+		{name: "not base64", token: "!!!"},
+		{name: "not json", token: encode("cursor")},
+		{name: "zero id", token: encode(`{"k":10,"i":0}`)},
+		{name: "key of another type", token: encode(`{"k":"x","i":1}`)},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, _, err := decodeCursor[int](c.token); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("decodeCursor error = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
@@ -1,8 +1,24 @@
 package repository
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
 
 // Общие sentinel-ошибки, возвращаемые слоями репозиториев
 var (
-	ErrNotFound = errors.New("resource not found")
+	ErrNotFound          = errors.New("resource not found")
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrInvalidSeats      = errors.New("invalid seat count")
+	ErrAlreadyExists     = errors.New("resource already exists")
 )
+
+// pgUniqueViolation - код ошибки Postgres при нарушении уникального индекса
+const pgUniqueViolation = "23505"
+
+// isUniqueViolation сообщает, что запись упёрлась в уникальный индекс
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation
+}
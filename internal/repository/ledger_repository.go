@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"log/slog"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"gorm.io/gorm"
+)
+
+type LedgerRepository interface {
+	CreateEntries(entries []models.LedgerEntry) error
+
+	ListByUser(userID uint, filter models.Page) (*dto.Page[models.LedgerEntry], error)
+
+	SumByBooking(bookingID uint, account constants.LedgerAccount) (int, error)
+
+	WithDB(db *gorm.DB) LedgerRepository
+}
+
+type gormLedgerRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewLedgerRepository(db *gorm.DB, logger *slog.Logger) LedgerRepository {
+	return &gormLedgerRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *gormLedgerRepository) CreateEntries(entries []models.LedgerEntry) error {
+	op := "repository.ledger.create_entries"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Int("count", len(entries)),
+	)
+
+	if err := r.db.Create(&entries).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *gormLedgerRepository) ListByUser(userID uint, filter models.Page) (*dto.Page[models.LedgerEntry], error) {
+	op := "repository.ledger.list_by_user"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("user_id", uint64(userID)),
+	)
+
+	page := filter.Page
+	pageSize := filter.PageSize
+
+	if page < 1 {
+		page = 1
+	}
+
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 100
+	}
+
+	entries, err := byID(true, func(e *models.LedgerEntry) uint { return e.ID }).
+		page(r.db.Model(&models.LedgerEntry{}).Where("user_id = ?", userID), filter.Cursor, page, pageSize)
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *gormLedgerRepository) SumByBooking(bookingID uint, account constants.LedgerAccount) (int, error) {
+	op := "repository.ledger.sum_by_booking"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("booking_id", uint64(bookingID)),
+	)
+
+	var sum int
+
+	if err := r.db.Model(&models.LedgerEntry{}).
+		Where("booking_id = ? AND account = ?", bookingID, account).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&sum).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return 0, err
+	}
+
+	return sum, nil
+}
+
+func (r *gormLedgerRepository) WithDB(db *gorm.DB) LedgerRepository {
+	return &gormLedgerRepository{
+		db:     db,
+		logger: r.logger,
+	}
+}
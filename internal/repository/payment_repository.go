@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"gorm.io/gorm"
+)
+
+type PaymentRepository interface {
+	Create(intent *models.PaymentIntent) error
+
+	GetByProviderRef(provider, ref string) (*models.PaymentIntent, error)
+
+	SetProviderRef(id uint, ref string) error
+
+	// Finalize переводит платёж из pending в итоговый статус; false — платёж уже обработан
+	Finalize(id uint, status constants.PaymentStatus, reason string) (bool, error)
+
+	ListByUser(userID uint, filter models.Page) (*dto.Page[models.PaymentIntent], error)
+
+	WithDB(db *gorm.DB) PaymentRepository
+}
+
+type gormPaymentRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewPaymentRepository(db *gorm.DB, logger *slog.Logger) PaymentRepository {
+	return &gormPaymentRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *gormPaymentRepository) Create(intent *models.PaymentIntent) error {
+	op := "repository.payment.create"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("user_id", uint64(intent.UserID)),
+		slog.String("kind", string(intent.Kind)),
+	)
+
+	if err := r.db.Create(intent).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *gormPaymentRepository) GetByProviderRef(provider, ref string) (*models.PaymentIntent, error) {
+	op := "repository.payment.get_by_provider_ref"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.String("provider", provider),
+		slog.String("ref", ref),
+	)
+
+	var intent models.PaymentIntent
+
+	if err := r.db.Where("provider = ? AND provider_ref = ?", provider, ref).First(&intent).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return &intent, nil
+}
+
+func (r *gormPaymentRepository) SetProviderRef(id uint, ref string) error {
+	op := "repository.payment.set_provider_ref"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("payment_id", uint64(id)),
+	)
+
+	if err := r.db.Model(&models.PaymentIntent{}).
+		Where("id = ?", id).
+		Update("provider_ref", ref).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *gormPaymentRepository) Finalize(id uint, status constants.PaymentStatus, reason string) (bool, error) {
+	op := "repository.payment.finalize"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("payment_id", uint64(id)),
+		slog.String("status", string(status)),
+	)
+
+	result := r.db.Model(&models.PaymentIntent{}).
+		Where("id = ? AND status = ?", id, constants.PaymentPending).
+		Updates(map[string]any{
+			"status":         status,
+			"failure_reason": reason,
+		})
+
+	if result.Error != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", result.Error))
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *gormPaymentRepository) ListByUser(userID uint, filter models.Page) (*dto.Page[models.PaymentIntent], error) {
+	op := "repository.payment.list_by_user"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("user_id", uint64(userID)),
+	)
+
+	page := filter.Page
+	pageSize := filter.PageSize
+
+	if page < 1 {
+		page = 1
+	}
+
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 100
+	}
+
+	intents, err := byID(true, func(i *models.PaymentIntent) uint { return i.ID }).
+		page(r.db.Model(&models.PaymentIntent{}).Where("user_id = ?", userID), filter.Cursor, page, pageSize)
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return intents, nil
+}
+
+func (r *gormPaymentRepository) WithDB(db *gorm.DB) PaymentRepository {
+	return &gormPaymentRepository{
+		db:     db,
+		logger: r.logger,
+	}
+}
@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PromoRepository interface {
+	Create(promo *models.PromoCode) error
+
+	GetByID(id uint) (*models.PromoCode, error)
+
+	GetByCode(code string) (*models.PromoCode, error)
+
+	GetByCodeForUpdate(code string) (*models.PromoCode, error)
+
+	List(filter models.Page) (*dto.Page[models.PromoCode], error)
+
+	Update(promo *models.PromoCode) error
+
+	CountRedemptions(promoID uint) (int64, error)
+
+	CountUserRedemptions(promoID, userID uint) (int64, error)
+
+	CreateRedemption(redemption *models.PromoRedemption) error
+
+	// DeleteRedemption возвращает код в оборот, когда бронь перестала быть активной
+	DeleteRedemption(bookingID uint) error
+
+	WithDB(db *gorm.DB) PromoRepository
+}
+
+type gormPromoRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewPromoRepository(db *gorm.DB, logger *slog.Logger) PromoRepository {
+	return &gormPromoRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// использованием кода считается только применение к активной брони
+var redeemedBookingStatuses = []constants.BookingStatus{
+	constants.BookingPending,
+	constants.BookingApproved,
+}
+
+func (r *gormPromoRepository) Create(promo *models.PromoCode) error {
+	op := "repository.promo.create"
+
+	r.logger.Debug("db call", slog.String("op", op), slog.String("code", promo.Code))
+
+	if err := r.db.Create(promo).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *gormPromoRepository) GetByID(id uint) (*models.PromoCode, error) {
+	op := "repository.promo.get_by_id"
+
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("promo_id", uint64(id)))
+
+	var promo models.PromoCode
+
+	if err := r.db.First(&promo, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return &promo, nil
+}
+
+func (r *gormPromoRepository) GetByCode(code string) (*models.PromoCode, error) {
+	return r.getByCode(r.db, "repository.promo.get_by_code", code)
+}
+
+// GetByCodeForUpdate читает код с блокировкой строки: так проверка лимитов
+// и запись применения не гонятся с параллельными бронями
+func (r *gormPromoRepository) GetByCodeForUpdate(code string) (*models.PromoCode, error) {
+	return r.getByCode(r.db.Clauses(clause.Locking{Strength: "UPDATE"}), "repository.promo.get_by_code_for_update", code)
+}
+
+func (r *gormPromoRepository) getByCode(db *gorm.DB, op, code string) (*models.PromoCode, error) {
+	r.logger.Debug("db call", slog.String("op", op), slog.String("code", code))
+
+	var promo models.PromoCode
+
+	if err := db.Where("code = ?", code).First(&promo).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return &promo, nil
+}
+
+func (r *gormPromoRepository) List(filter models.Page) (*dto.Page[models.PromoCode], error) {
+	op := "repository.promo.list"
+
+	r.logger.Debug("db call", slog.String("op", op))
+
+	page := filter.Page
+	pageSize := filter.PageSize
+
+	if page < 1 {
+		page = 1
+	}
+
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 100
+	}
+
+	promos, err := byID(true, func(p *models.PromoCode) uint { return p.ID }).
+		page(r.db.Model(&models.PromoCode{}), filter.Cursor, page, pageSize)
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return promos, nil
+}
+
+func (r *gormPromoRepository) Update(promo *models.PromoCode) error {
+	op := "repository.promo.update"
+
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("promo_id", uint64(promo.ID)))
+
+	if err := r.db.Save(promo).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *gormPromoRepository) CountRedemptions(promoID uint) (int64, error) {
+	return r.countRedemptions("repository.promo.count_redemptions",
+		"promo_redemptions.promo_code_id = ?", promoID)
+}
+
+func (r *gormPromoRepository) CountUserRedemptions(promoID, userID uint) (int64, error) {
+	return r.countRedemptions("repository.promo.count_user_redemptions",
+		"promo_redemptions.promo_code_id = ? AND promo_redemptions.user_id = ?", promoID, userID)
+}
+
+func (r *gormPromoRepository) countRedemptions(op string, query string, args ...any) (int64, error) {
+	r.logger.Debug("db call", slog.String("op", op))
+
+	var count int64
+
+	if err := r.db.Model(&models.PromoRedemption{}).
+		Joins("JOIN bookings ON bookings.id = promo_redemptions.booking_id AND bookings.deleted_at IS NULL").
+		Where(query, args...).
+		Where("bookings.booking_status IN ?", redeemedBookingStatuses).
+		Count(&count).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *gormPromoRepository) CreateRedemption(redemption *models.PromoRedemption) error {
+	op := "repository.promo.create_redemption"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("promo_id", uint64(redemption.PromoCodeID)),
+		slog.Uint64("booking_id", uint64(redemption.BookingID)),
+	)
+
+	if err := r.db.Create(redemption).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *gormPromoRepository) DeleteRedemption(bookingID uint) error {
+	op := "repository.promo.delete_redemption"
+
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("booking_id", uint64(bookingID)))
+
+	if err := r.db.Where("booking_id = ?", bookingID).Delete(&models.PromoRedemption{}).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *gormPromoRepository) WithDB(db *gorm.DB) PromoRepository {
+	return &gormPromoRepository{
+		db:     db,
+		logger: r.logger,
+	}
+}
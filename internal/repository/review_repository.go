@@ -12,7 +12,7 @@ import (
 type ReviewRepository interface {
 	Create(review *models.Review) error
 
-	List(filter models.Page) ([]dto.ReviewListItem, error)
+	List(filter models.Page) (*dto.Page[dto.ReviewListItem], error)
 
 	GetByID(id uint) (*models.Review, error)
 
@@ -53,7 +53,7 @@ func (r *gormReviewRepository) Create(review *models.Review) error {
 	return nil
 }
 
-func (r *gormReviewRepository) List(filter models.Page) ([]dto.ReviewListItem, error) {
+func (r *gormReviewRepository) List(filter models.Page) (*dto.Page[dto.ReviewListItem], error) {
 	op := "repository.review.list"
 	r.logger.Debug("db call", slog.String("op", op))
 
@@ -70,7 +70,6 @@ func (r *gormReviewRepository) List(filter models.Page) ([]dto.ReviewListItem, e
 		pageSize = 1000
 	}
 
-	reviews := make([]dto.ReviewListItem, 0, pageSize)
 	db := r.DB.Model(&models.Review{})
 
 	if filter.TripID != nil {
@@ -80,16 +79,14 @@ func (r *gormReviewRepository) List(filter models.Page) ([]dto.ReviewListItem, e
 		db = db.Where("author_id = ?", *filter.AuthorID)
 	}
 
-	if filter.LastID != nil {
+	if filter.LastID != nil && filter.Cursor == "" {
 		db = db.Where("id < ?", *filter.LastID)
-	} else if page > 1 {
-		db = db.Offset((page - 1) * pageSize)
+		page = 1
 	}
 
-	if err := db.
-		Order("id DESC").
-		Limit(pageSize).
-		Find(&reviews).Error; err != nil {
+	reviews, err := byID(true, func(item *dto.ReviewListItem) uint { return item.ID }).
+		page(db, filter.Cursor, page, pageSize)
+	if err != nil {
 		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
 		return nil, err
 	}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SavedSearchRepository interface {
+	Create(search *models.SavedSearch) error
+
+	GetByID(id uint) (*models.SavedSearch, error)
+
+	ListByUser(userID uint) ([]models.SavedSearch, error)
+
+	CountActiveByUser(userID uint, now time.Time) (int64, error)
+
+	// ListActiveForCities — действующие подписки, которые могут совпасть с маршрутом через cityIDs:
+	// без города в справочнике или с городом из списка
+	ListActiveForCities(cityIDs []uint, now time.Time) ([]models.SavedSearch, error)
+
+	// RecordMatch запоминает, что о поездке сообщено; false — уже сообщали
+	RecordMatch(searchID, tripID uint) (bool, error)
+
+	Delete(id uint) error
+}
+
+type gormSavedSearchRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewSavedSearchRepository(db *gorm.DB, logger *slog.Logger) SavedSearchRepository {
+	return &gormSavedSearchRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *gormSavedSearchRepository) Create(search *models.SavedSearch) error {
+	op := "repository.saved_search.create"
+
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("user_id", uint64(search.UserID)))
+
+	if err := r.db.Create(search).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *gormSavedSearchRepository) GetByID(id uint) (*models.SavedSearch, error) {
+	op := "repository.saved_search.get_by_id"
+
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("saved_search_id", uint64(id)))
+
+	var search models.SavedSearch
+
+	if err := r.db.First(&search, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return &search, nil
+}
+
+func (r *gormSavedSearchRepository) ListByUser(userID uint) ([]models.SavedSearch, error) {
+	op := "repository.saved_search.list_by_user"
+
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("user_id", uint64(userID)))
+
+	var list []models.SavedSearch
+
+	if err := r.db.
+		Where("user_id = ?", userID).
+		Order("id DESC").
+		Find(&list).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (r *gormSavedSearchRepository) CountActiveByUser(userID uint, now time.Time) (int64, error) {
+	op := "repository.saved_search.count_active_by_user"
+
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("user_id", uint64(userID)))
+
+	var count int64
+
+	if err := r.db.Model(&models.SavedSearch{}).
+		Where("user_id = ? AND expires_at > ?", userID, now).
+		Count(&count).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *gormSavedSearchRepository) ListActiveForCities(cityIDs []uint, now time.Time) ([]models.SavedSearch, error) {
+	op := "repository.saved_search.list_active_for_cities"
+
+	r.logger.Debug("db call", slog.String("op", op), slog.Int("cities", len(cityIDs)))
+
+	var list []models.SavedSearch
+
+	if err := r.db.
+		Where("expires_at > ?", now).
+		Where("from_city_id IS NULL OR from_city_id IN ?", cityIDs).
+		Where("to_city_id IS NULL OR to_city_id IN ?", cityIDs).
+		Order("id ASC").
+		Find(&list).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (r *gormSavedSearchRepository) RecordMatch(searchID, tripID uint) (bool, error) {
+	op := "repository.saved_search.record_match"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("saved_search_id", uint64(searchID)),
+		slog.Uint64("trip_id", uint64(tripID)),
+	)
+
+	res := r.db.
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.SavedSearchMatch{SavedSearchID: searchID, TripID: tripID})
+	if res.Error != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", res.Error))
+		return false, res.Error
+	}
+
+	return res.RowsAffected == 1, nil
+}
+
+func (r *gormSavedSearchRepository) Delete(id uint) error {
+	op := "repository.saved_search.delete"
+
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("saved_search_id", uint64(id)))
+
+	if err := r.db.Delete(&models.SavedSearch{}, id).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
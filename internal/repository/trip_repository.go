@@ -3,17 +3,21 @@ package repository
 import (
 	"errors"
 	"log/slog"
+	"math"
 	"time"
 
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
 	"github.com/mutsaevz/team-5-ambitious/internal/dto"
 	"github.com/mutsaevz/team-5-ambitious/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TripRepository interface {
 	Create(trip *models.Trip) error
 
-	List(filter dto.TripFilter) ([]models.Trip, error)
+	// List возвращает страницу поездок и общее число подходящих под фильтр
+	List(filter dto.TripFilter) (*dto.Page[models.Trip], int64, error)
 
 	GetByID(id uint) (*models.Trip, error)
 
@@ -29,7 +33,27 @@ type TripRepository interface {
 
 	IsPassenger(tripID, userID uint) (bool, error)
 
-	UpdateTripStatuses(now time.Time) error
+	StartDueTrips(now time.Time) error
+
+	ListDueForCompletion(now time.Time) ([]models.Trip, error)
+
+	SaveStatus(trip *models.Trip, from constants.TripStatus) (bool, error)
+
+	ReleaseSeats(id uint, n int) error
+
+	ReserveSeats(id uint, n int) (bool, error)
+
+	GetByIDForUpdate(id uint) (*models.Trip, error)
+
+	ListFutureByTemplate(templateID uint, from time.Time) ([]models.Trip, error)
+
+	HasActiveBookings(id uint) (bool, error)
+
+	ReserveLegs(id uint, from, to, n int) (bool, error)
+
+	ReleaseLegs(id uint, from, to, n int) error
+
+	SyncAvailableSeats(id uint) (int, error)
 }
 
 type gormTripRepository struct {
@@ -47,61 +71,261 @@ func NewTripRepository(db *gorm.DB, logger *slog.Logger) TripRepository {
 func (r *gormTripRepository) Create(trip *models.Trip) error {
 
 	if err := r.db.Create(trip).Error; err != nil {
+		if isUniqueViolation(err) {
+			return ErrAlreadyExists
+		}
 		return err
 	}
 
 	return nil
 }
 
-func (r *gormTripRepository) List(filter dto.TripFilter) ([]models.Trip, error) {
-	var list []models.Trip
+func (r *gormTripRepository) List(filter dto.TripFilter) (*dto.Page[models.Trip], int64, error) {
+	op := "repository.trip.list"
 
-	query := r.db.Model(&models.Trip{}).
-		Where("available_seats > 0")
+	var total int64
 
-	if filter.FromCity != nil {
-		query = query.Where("from_city = ?", *filter.FromCity)
-	}
+	query := r.db.Model(&models.Trip{})
 
-	if filter.ToCity != nil {
-		query = query.Where("to_city = ?", *filter.ToCity)
+	// сколько мест должно быть свободно на искомом участке
+	need := 1
+	if filter.IncludeFull {
+		need = 0
 	}
-
 	if filter.AvailableSeats != nil {
-		query = query.Where("available_seats >= ?", *filter.AvailableSeats)
+		need = *filter.AvailableSeats
+	}
+
+	if filter.FromCity != nil || filter.ToCity != nil || filter.Origin != nil || filter.Destination != nil {
+		query = routeSegmentScope(query, filter, need)
+	} else if need > 0 {
+		query = query.Where("available_seats >= ?", need)
 	}
 
 	if filter.StartTime != nil {
 		query = query.Where("start_time >= ?", *filter.StartTime)
 	}
+	if filter.StartTimeTo != nil {
+		query = query.Where("start_time <= ?", *filter.StartTimeTo)
+	}
+
+	if filter.MaxPrice != nil {
+		query = query.Where("price <= ?", *filter.MaxPrice)
+	}
+	if filter.MinRating != nil {
+		query = query.Where("avg_rating >= ?", *filter.MinRating)
+	}
+
+	if filter.VerifiedOnly {
+		query = query.Where("EXISTS (SELECT 1 FROM users u WHERE u.id = trips.driver_id AND u.driver_verified_until > ?)", time.Now())
+	}
 
+	// без явного статуса в поиске участвуют только опубликованные поездки
+	status := constants.TripPublished
 	if filter.TripStatus != nil {
-		query = query.Where("trip_status = ?", *filter.TripStatus)
+		status = *filter.TripStatus
 	}
+	query = query.Where("trip_status = ?", status)
+
+	if filter.TripID != nil {
+		query = query.Where("trips.id = ?", *filter.TripID)
+	}
+
+	query = carAmenitiesScope(query, filter)
 
-	page := filter.Page
-	pageSize := filter.PageSize
+	// Session — чтобы подсчёт и выборка не делили одно состояние запроса
+	query = query.Session(&gorm.Session{})
 
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, 0, err
+	}
+
+	page, pageSize := NormalizePage(filter.Page, filter.PageSize)
+
+	list, err := tripPage(query.Preload("Stops", orderStops), filter, page, pageSize)
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, 0, err
+	}
+
+	return list, total, nil
+}
+
+// NormalizePage приводит номер и размер страницы к допустимым: страницы с 1, до 100 записей
+func NormalizePage(page, pageSize int) (int, int) {
 	if page < 1 {
 		page = 1
 	}
 	if pageSize <= 0 || pageSize > 100 {
 		pageSize = 50
 	}
+	return page, pageSize
+}
 
-	offset := (page - 1) * pageSize
+// tripPage выбирает страницу поездок в порядке filter.Sort; по умолчанию ближайшие
+// отправления первыми, по рейтингу — лучшие первыми
+func tripPage(query *gorm.DB, filter dto.TripFilter, page, pageSize int) (*dto.Page[models.Trip], error) {
+	desc := func(def bool) bool {
+		if filter.Desc != nil {
+			return *filter.Desc
+		}
+		return def
+	}
 
-	if err := query.Offset(offset).Limit(pageSize).Find(&list).Error; err != nil {
-		return nil, err
+	switch filter.Sort {
+	case constants.TripSortPrice:
+		return keyset[models.Trip, int]{
+			column: "price",
+			desc:   desc(false),
+			key:    func(t *models.Trip) (int, uint) { return t.Price, t.ID },
+		}.page(query, filter.Cursor, page, pageSize)
+	case constants.TripSortRating:
+		return keyset[models.Trip, float64]{
+			column: "avg_rating",
+			desc:   desc(true),
+			key:    func(t *models.Trip) (float64, uint) { return t.AvgRating, t.ID },
+		}.page(query, filter.Cursor, page, pageSize)
+	default:
+		return keyset[models.Trip, time.Time]{
+			column: "start_time",
+			desc:   desc(false),
+			key:    func(t *models.Trip) (time.Time, uint) { return t.StartTime, t.ID },
+		}.page(query, filter.Cursor, page, pageSize)
+	}
+}
+
+// routeSegmentScope оставляет поездки, маршрут которых проходит через from и затем через to
+// и на этом участке свободно не меньше need мест. Поездки без остановок сравниваются
+// по городам отправления и прибытия. Точка пассажира важнее города: ищутся остановки
+// в пределах радиуса; город из справочника ищется по ID, иначе — по названию.
+func routeSegmentScope(query *gorm.DB, filter dto.TripFilter, need int) *gorm.DB {
+	segment := "a.trip_id = trips.id AND a.deleted_at IS NULL AND b.deleted_at IS NULL"
+	legacy := "NOT EXISTS (SELECT 1 FROM trip_stops s WHERE s.trip_id = trips.id AND s.deleted_at IS NULL)"
+
+	var segmentArgs, legacyArgs []any
+
+	if filter.Origin != nil {
+		cond, condArgs := geoRadius("a.lat", "a.lng", *filter.Origin)
+		segment += " AND " + cond
+		segmentArgs = append(segmentArgs, condArgs...)
+		cond, condArgs = geoRadius("trips.from_lat", "trips.from_lng", *filter.Origin)
+		legacy += " AND " + cond
+		legacyArgs = append(legacyArgs, condArgs...)
+	} else if filter.FromCityID != nil {
+		segment += " AND a.city_id = ?"
+		legacy += " AND trips.from_city_id = ?"
+		segmentArgs = append(segmentArgs, *filter.FromCityID)
+		legacyArgs = append(legacyArgs, *filter.FromCityID)
+	} else if filter.FromCity != nil {
+		segment += " AND a.city = ?"
+		legacy += " AND trips.from_city = ?"
+		segmentArgs = append(segmentArgs, *filter.FromCity)
+		legacyArgs = append(legacyArgs, *filter.FromCity)
+	}
+	if filter.Destination != nil {
+		cond, condArgs := geoRadius("b.lat", "b.lng", *filter.Destination)
+		segment += " AND " + cond
+		segmentArgs = append(segmentArgs, condArgs...)
+		cond, condArgs = geoRadius("trips.to_lat", "trips.to_lng", *filter.Destination)
+		legacy += " AND " + cond
+		legacyArgs = append(legacyArgs, condArgs...)
+	} else if filter.ToCityID != nil {
+		segment += " AND b.city_id = ?"
+		legacy += " AND trips.to_city_id = ?"
+		segmentArgs = append(segmentArgs, *filter.ToCityID)
+		legacyArgs = append(legacyArgs, *filter.ToCityID)
+	} else if filter.ToCity != nil {
+		segment += " AND b.city = ?"
+		legacy += " AND trips.to_city = ?"
+		segmentArgs = append(segmentArgs, *filter.ToCity)
+		legacyArgs = append(legacyArgs, *filter.ToCity)
+	}
+
+	// на участке a→b свободно столько мест, сколько осталось на самом загруженном перегоне
+	segment += ` AND trips.total_seats - (
+		SELECT COALESCE(MAX(l.booked_seats), 0) FROM trip_stops l
+		WHERE l.trip_id = trips.id AND l.deleted_at IS NULL
+			AND l.position >= a.position AND l.position < b.position) >= ?`
+	legacy += " AND trips.available_seats >= ?"
+	segmentArgs = append(segmentArgs, need)
+	legacyArgs = append(legacyArgs, need)
+
+	args := append(segmentArgs, legacyArgs...)
+
+	return query.Where(`(EXISTS (
+		SELECT 1 FROM trip_stops a
+		JOIN trip_stops b ON b.trip_id = a.trip_id AND b.position > a.position
+		WHERE `+segment+`) OR (`+legacy+`))`, args...)
+}
+
+const (
+	earthRadiusKm = 6371.0
+	kmPerDegree   = 111.045
+)
+
+// geoRadius — условие «точка (lat, lng) не дальше area.RadiusKm от центра area».
+// Прямоугольник по широте и долготе отсекает лишнее по индексу, точное расстояние
+// считается по формуле гаверсинусов; PostGIS не нужен. Точки без координат не подходят.
+func geoRadius(lat, lng string, area dto.GeoArea) (string, []any) {
+	dLat := area.RadiusKm / kmPerDegree
+
+	cond := lat + " BETWEEN ? AND ?"
+	args := []any{area.Lat - dLat, area.Lat + dLat}
+
+	// у полюсов и при переходе через 180-й меридиан прямоугольник по долготе не строится
+	if cos := math.Cos(area.Lat * math.Pi / 180); cos > 0.01 {
+		dLng := area.RadiusKm / (kmPerDegree * cos)
+		if area.Lng-dLng >= -180 && area.Lng+dLng <= 180 {
+			cond += " AND " + lng + " BETWEEN ? AND ?"
+			args = append(args, area.Lng-dLng, area.Lng+dLng)
+		}
+	}
+
+	cond += " AND 2 * ? * ASIN(SQRT(LEAST(1, POWER(SIN(RADIANS(" + lat + " - ?) / 2), 2) + COS(RADIANS(?)) * COS(RADIANS(" + lat + ")) * POWER(SIN(RADIANS(" + lng + " - ?) / 2), 2)))) <= ?"
+	args = append(args, earthRadiusKm, area.Lat, area.Lat, area.Lng, area.RadiusKm)
+
+	return "(" + cond + ")", args
+}
+
+// carAmenitiesScope оставляет поездки на автомобилях с запрошенными удобствами
+func carAmenitiesScope(query *gorm.DB, filter dto.TripFilter) *gorm.DB {
+	amenities := []struct {
+		column string
+		value  *bool
+	}{
+		{"air_conditioning", filter.AirConditioning},
+		{"luggage_space", filter.LuggageSpace},
+		{"child_seat", filter.ChildSeat},
+		{"pets_allowed", filter.PetsAllowed},
+		{"smoking_allowed", filter.SmokingAllowed},
+	}
+
+	cond := "c.id = trips.car_id"
+	var args []any
+
+	for _, a := range amenities {
+		if a.value != nil {
+			cond += " AND c." + a.column + " = ?"
+			args = append(args, *a.value)
+		}
+	}
+
+	if len(args) == 0 {
+		return query
 	}
 
-	return list, nil
+	return query.Where("EXISTS (SELECT 1 FROM cars c WHERE "+cond+")", args...)
+}
+
+func orderStops(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC")
 }
 
 func (r *gormTripRepository) GetByID(id uint) (*models.Trip, error) {
 	var trip models.Trip
 
-	if err := r.db.First(&trip, id).Error; err != nil {
+	if err := r.db.Preload("Stops", orderStops).First(&trip, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrNotFound
 		}
@@ -119,11 +343,67 @@ func (r *gormTripRepository) Update(trip *models.Trip) error {
 		slog.Uint64("trip_id", uint64(trip.ID)),
 	)
 
-	return r.db.
+	// Select("*") — иначе Updates пропускает нулевые значения (например, available_seats = 0);
+	// остановки меняются своими методами, поэтому ассоциации не сохраняем
+	if err := r.db.
 		Model(&models.Trip{}).
 		Where("id = ?", trip.ID).
+		Select("*").
+		Omit("id", "created_at", clause.Associations).
 		Updates(trip).
-		Error
+		Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	return r.syncEndpointStops(trip)
+}
+
+// syncEndpointStops держит первую и последнюю остановку в согласии с городами и временем поездки;
+// промежуточные остановки сдвигаются вместе со временем отправления
+func (r *gormTripRepository) syncEndpointStops(trip *models.Trip) error {
+	op := "repository.trip.sync_endpoint_stops"
+
+	if err := r.db.Exec(`UPDATE trip_stops SET arrival_time = arrival_time + (?::timestamptz - (
+			SELECT origin.arrival_time FROM trip_stops origin
+			WHERE origin.trip_id = trip_stops.trip_id AND origin.position = 0 AND origin.deleted_at IS NULL))
+		WHERE trip_id = ? AND position > 0 AND deleted_at IS NULL
+			AND position < (SELECT MAX(position) FROM trip_stops WHERE trip_id = ? AND deleted_at IS NULL)`,
+		trip.StartTime, trip.ID, trip.ID).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	if err := r.db.Model(&models.TripStop{}).
+		Where("trip_id = ? AND position = 0", trip.ID).
+		Updates(map[string]any{
+			"city":         trip.FromCity,
+			"city_id":      trip.FromCityID,
+			"lat":          trip.FromLat,
+			"lng":          trip.FromLng,
+			"arrival_time": trip.StartTime,
+		}).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	arrival := trip.StartTime.Add(time.Duration(trip.DurationMin) * time.Minute)
+
+	if err := r.db.Model(&models.TripStop{}).
+		Where("trip_id = ? AND position = (SELECT MAX(position) FROM trip_stops WHERE trip_id = ? AND deleted_at IS NULL) AND position > 0",
+			trip.ID, trip.ID).
+		Updates(map[string]any{
+			"city":         trip.ToCity,
+			"city_id":      trip.ToCityID,
+			"lat":          trip.ToLat,
+			"lng":          trip.ToLng,
+			"arrival_time": arrival,
+		}).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	return nil
 }
 
 func (r *gormTripRepository) Delete(id uint) error {
@@ -184,26 +464,249 @@ func (r *gormTripRepository) IsPassenger(tripID, userID uint) (bool, error) {
 	return count > 0, nil
 }
 
-func (r *gormTripRepository) UpdateTripStatuses(now time.Time) error {
-	op := "repository.trip.update_statuses"
+// StartDueTrips переводит в пути поездки, отправление которых наступило до now,
+// а водитель так и не начал их сам
+func (r *gormTripRepository) StartDueTrips(now time.Time) error {
+	op := "repository.trip.start_due_trips"
 
 	if err := r.db.Model(&models.Trip{}).
-		Where("trip_status = ?", "published").
+		Where("trip_status = ?", constants.TripPublished).
 		Where("start_time <= ?", now).
-		Update("trip_status", "in_progress").
+		Updates(map[string]any{
+			"trip_status": constants.TripInProgress,
+			"started_at":  gorm.Expr("start_time"),
+		}).
 		Error; err != nil {
 		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
 		return err
 	}
 
-	if err := r.db.Model(&models.Trip{}).
-		Where("trip_status = ?", "in_progress").
-		Where("start_time + (duration_min * interval '1 minute') <= ?", now).
-		Update("trip_status", "completed").
+	return nil
+}
+
+func (r *gormTripRepository) ListDueForCompletion(now time.Time) ([]models.Trip, error) {
+	op := "repository.trip.list_due_for_completion"
+
+	var trips []models.Trip
+
+	if err := r.db.
+		Where("trip_status = ?", constants.TripInProgress).
+		Where("COALESCE(started_at, start_time) + (duration_min * interval '1 minute') <= ?", now).
+		Find(&trips).
 		Error; err != nil {
 		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
-		return err
+		return nil, err
+	}
+
+	return trips, nil
+}
+
+// SaveStatus сохраняет статус поездки вместе с отметками жизненного цикла,
+// только если поездка всё ещё в статусе from
+func (r *gormTripRepository) SaveStatus(trip *models.Trip, from constants.TripStatus) (bool, error) {
+	op := "repository.trip.save_status"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("trip_id", uint64(trip.ID)),
+		slog.String("to", trip.TripStatus),
+	)
+
+	result := r.db.Model(&models.Trip{}).
+		Where("id = ? AND trip_status = ?", trip.ID, from).
+		Updates(map[string]any{
+			"trip_status":   trip.TripStatus,
+			"started_at":    trip.StartedAt,
+			"finished_at":   trip.FinishedAt,
+			"cancel_reason": trip.CancelReason,
+		})
+
+	if result.Error != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", result.Error))
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// ReleaseSeats возвращает n мест в продажу, не превышая total_seats
+func (r *gormTripRepository) ReleaseSeats(id uint, n int) error {
+	op := "repository.trip.release_seats"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("trip_id", uint64(id)),
+		slog.Int("seats", n),
+	)
+
+	result := r.db.Model(&models.Trip{}).
+		Where("id = ? AND available_seats + ? <= total_seats", id, n).
+		Update("available_seats", gorm.Expr("available_seats + ?", n))
+
+	if result.Error != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", result.Error))
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrInvalidSeats
 	}
 
 	return nil
 }
+
+// ReserveSeats списывает n мест одним условным UPDATE, поэтому параллельные
+// подтверждения не могут увести available_seats в минус
+func (r *gormTripRepository) ReserveSeats(id uint, n int) (bool, error) {
+	op := "repository.trip.reserve_seats"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("trip_id", uint64(id)),
+		slog.Int("seats", n),
+	)
+
+	result := r.db.Model(&models.Trip{}).
+		Where("id = ? AND available_seats >= ?", id, n).
+		Update("available_seats", gorm.Expr("available_seats - ?", n))
+
+	if result.Error != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", result.Error))
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// GetByIDForUpdate читает поездку с блокировкой строки до конца транзакции
+func (r *gormTripRepository) GetByIDForUpdate(id uint) (*models.Trip, error) {
+	op := "repository.trip.get_by_id_for_update"
+
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("trip_id", uint64(id)))
+
+	var trip models.Trip
+
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Stops", orderStops).First(&trip, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return &trip, nil
+}
+
+// ListFutureByTemplate возвращает ещё не начавшиеся поездки регулярного рейса
+func (r *gormTripRepository) ListFutureByTemplate(templateID uint, from time.Time) ([]models.Trip, error) {
+	op := "repository.trip.list_future_by_template"
+
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("template_id", uint64(templateID)))
+
+	var trips []models.Trip
+
+	if err := r.db.
+		Where("template_id = ? AND start_time >= ? AND trip_status = ?", templateID, from, constants.TripPublished).
+		Order("start_time ASC").
+		Find(&trips).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return trips, nil
+}
+
+// HasActiveBookings проверяет, есть ли на поездке ожидающие или подтверждённые брони
+func (r *gormTripRepository) HasActiveBookings(id uint) (bool, error) {
+	op := "repository.trip.has_active_bookings"
+
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("trip_id", uint64(id)))
+
+	var count int64
+
+	if err := r.db.Model(&models.Booking{}).
+		Where("trip_id = ? AND booking_status IN ?", id,
+			[]constants.BookingStatus{constants.BookingPending, constants.BookingApproved}).
+		Count(&count).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// ReserveLegs занимает n мест на перегонах [from, to). Если хотя бы на одном перегоне
+// мест не хватает, возвращает false — вызывающий обязан откатить транзакцию,
+// потому что остальные перегоны уже увеличены
+func (r *gormTripRepository) ReserveLegs(id uint, from, to, n int) (bool, error) {
+	op := "repository.trip.reserve_legs"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("trip_id", uint64(id)),
+		slog.Int("from", from),
+		slog.Int("to", to),
+		slog.Int("seats", n),
+	)
+
+	result := r.db.Model(&models.TripStop{}).
+		Where(`trip_id = ? AND position >= ? AND position < ?
+			AND booked_seats + ? <= (SELECT total_seats FROM trips WHERE trips.id = trip_stops.trip_id)`,
+			id, from, to, n).
+		Update("booked_seats", gorm.Expr("booked_seats + ?", n))
+
+	if result.Error != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", result.Error))
+		return false, result.Error
+	}
+
+	return result.RowsAffected == int64(to-from), nil
+}
+
+// ReleaseLegs освобождает n мест на перегонах [from, to)
+func (r *gormTripRepository) ReleaseLegs(id uint, from, to, n int) error {
+	op := "repository.trip.release_legs"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("trip_id", uint64(id)),
+		slog.Int("from", from),
+		slog.Int("to", to),
+		slog.Int("seats", n),
+	)
+
+	result := r.db.Model(&models.TripStop{}).
+		Where("trip_id = ? AND position >= ? AND position < ? AND booked_seats >= ?", id, from, to, n).
+		Update("booked_seats", gorm.Expr("booked_seats - ?", n))
+
+	if result.Error != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", result.Error))
+		return result.Error
+	}
+
+	if result.RowsAffected != int64(to-from) {
+		return ErrInvalidSeats
+	}
+
+	return nil
+}
+
+// SyncAvailableSeats пересчитывает available_seats поездки с остановками как число мест,
+// свободных на всём маршруте, и возвращает новое значение
+func (r *gormTripRepository) SyncAvailableSeats(id uint) (int, error) {
+	op := "repository.trip.sync_available_seats"
+
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("trip_id", uint64(id)))
+
+	var available int
+
+	if err := r.db.Raw(`UPDATE trips SET available_seats = total_seats - (
+			SELECT COALESCE(MAX(booked_seats), 0) FROM trip_stops
+			WHERE trip_stops.trip_id = trips.id AND trip_stops.deleted_at IS NULL)
+		WHERE id = ? RETURNING available_seats`, id).Scan(&available).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return 0, err
+	}
+
+	return available, nil
+}
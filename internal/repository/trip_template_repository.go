@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"gorm.io/gorm"
+)
+
+type TripTemplateRepository interface {
+	Create(template *models.TripTemplate) error
+
+	GetByID(id uint) (*models.TripTemplate, error)
+
+	ListByDriver(driverID uint) ([]models.TripTemplate, error)
+
+	ListActive() ([]models.TripTemplate, error)
+
+	Update(template *models.TripTemplate) error
+
+	WithDB(db *gorm.DB) TripTemplateRepository
+}
+
+type gormTripTemplateRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewTripTemplateRepository(db *gorm.DB, logger *slog.Logger) TripTemplateRepository {
+	return &gormTripTemplateRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *gormTripTemplateRepository) Create(template *models.TripTemplate) error {
+	op := "repository.trip_template.create"
+
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("driver_id", uint64(template.DriverID)))
+
+	if err := r.db.Create(template).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *gormTripTemplateRepository) GetByID(id uint) (*models.TripTemplate, error) {
+	op := "repository.trip_template.get_by_id"
+
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("template_id", uint64(id)))
+
+	var template models.TripTemplate
+
+	if err := r.db.First(&template, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return &template, nil
+}
+
+func (r *gormTripTemplateRepository) ListByDriver(driverID uint) ([]models.TripTemplate, error) {
+	op := "repository.trip_template.list_by_driver"
+
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("driver_id", uint64(driverID)))
+
+	var templates []models.TripTemplate
+
+	if err := r.db.Where("driver_id = ?", driverID).Order("id ASC").Find(&templates).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+func (r *gormTripTemplateRepository) ListActive() ([]models.TripTemplate, error) {
+	op := "repository.trip_template.list_active"
+
+	r.logger.Debug("db call", slog.String("op", op))
+
+	var templates []models.TripTemplate
+
+	if err := r.db.Where("active = ?", true).Find(&templates).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+func (r *gormTripTemplateRepository) Update(template *models.TripTemplate) error {
+	op := "repository.trip_template.update"
+
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("template_id", uint64(template.ID)))
+
+	if err := r.db.Save(template).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *gormTripTemplateRepository) WithDB(db *gorm.DB) TripTemplateRepository {
+	return &gormTripTemplateRepository{
+		db:     db,
+		logger: r.logger,
+	}
+}
@@ -3,7 +3,9 @@ package repository
 import (
 	"errors"
 	"log/slog"
+	"time"
 
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
 	"github.com/mutsaevz/team-5-ambitious/internal/models"
 	"gorm.io/gorm"
 )
@@ -11,13 +13,32 @@ import (
 type UserRepository interface {
 	Create(user *models.User) error
 
-	List(filter models.Page) ([]models.User, error)
+	List(filter models.Page) (*dto.Page[models.User], error)
 
 	GetByID(id uint) (*models.User, error)
 
+	GetByPhone(phone string) (*models.User, error)
+
 	Update(id uint, user *models.User) error
 
+	AdjustBalance(id uint, delta int) error
+
+	GetByReferralCode(code string) (*models.User, error)
+
+	SetReferralCode(id uint, code string) (bool, error)
+
+	SetReferrer(id, referrerID uint) (bool, error)
+
+	MarkReferralRewarded(id uint) (bool, error)
+
+	SetDriverVerifiedUntil(id uint, until time.Time) error
+
+	// ListVerifiedDriverIDs — те из ids, у кого проверка водителя действует на момент now
+	ListVerifiedDriverIDs(ids []uint, now time.Time) ([]uint, error)
+
 	Delete(id uint) error
+
+	WithDB(db *gorm.DB) UserRepository
 }
 
 type gormUserRepository struct {
@@ -51,15 +72,13 @@ func (r *gormUserRepository) Create(user *models.User) error {
 	return nil
 }
 
-func (r *gormUserRepository) List(filter models.Page) ([]models.User, error) {
+func (r *gormUserRepository) List(filter models.Page) (*dto.Page[models.User], error) {
 	op := "repository.user.list"
 
 	r.logger.Debug("db call",
 		slog.String("op", op),
 	)
 
-	var users []models.User
-
 	page := filter.Page
 	pageSize := filter.PageSize
 
@@ -71,9 +90,9 @@ func (r *gormUserRepository) List(filter models.Page) ([]models.User, error) {
 		pageSize = 100
 	}
 
-	offset := (page - 1) * pageSize
-
-	if err := r.db.Offset(offset).Limit(pageSize).Find(&users).Error; err != nil {
+	users, err := byID(false, func(u *models.User) uint { return u.ID }).
+		page(r.db.Model(&models.User{}), filter.Cursor, page, pageSize)
+	if err != nil {
 		r.logger.Error("db error",
 			slog.String("op", op),
 			slog.Any("error", err),
@@ -109,6 +128,30 @@ func (r *gormUserRepository) GetByID(id uint) (*models.User, error) {
 	return user, nil
 }
 
+func (r *gormUserRepository) GetByPhone(phone string) (*models.User, error) {
+	op := "repository.user.get_by_phone"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+	)
+
+	var user models.User
+
+	if err := r.db.Where("phone = ?", phone).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+
+		r.logger.Error("db error",
+			slog.String("op", op),
+			slog.Any("error", err),
+		)
+		return nil, err
+	}
+
+	return &user, nil
+}
+
 func (r gormUserRepository) Update(id uint, user *models.User) error {
 	op := "repository.user.update"
 
@@ -117,7 +160,8 @@ func (r gormUserRepository) Update(id uint, user *models.User) error {
 		slog.String("user_name", user.Name),
 	)
 
-	if err := r.db.Model(&models.User{}).Where("id = ?", id).Updates(user).Error; err != nil {
+	// баланс меняется только через AdjustBalance (проводки леджера)
+	if err := r.db.Model(&models.User{}).Where("id = ?", id).Omit("balance").Updates(user).Error; err != nil {
 		r.logger.Error("db error",
 			slog.String("op", op),
 			slog.Any("error", err),
@@ -129,6 +173,120 @@ func (r gormUserRepository) Update(id uint, user *models.User) error {
 	return nil
 }
 
+// AdjustBalance атомарно меняет баланс; списание сверх остатка возвращает ErrInsufficientFunds
+func (r *gormUserRepository) AdjustBalance(id uint, delta int) error {
+	op := "repository.user.adjust_balance"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("user_id", uint64(id)),
+		slog.Int("delta", delta),
+	)
+
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND balance + ? >= 0", id, delta).
+		Update("balance", gorm.Expr("balance + ?", delta))
+
+	if result.Error != nil {
+		r.logger.Error("db error",
+			slog.String("op", op),
+			slog.Any("error", result.Error),
+		)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrInsufficientFunds
+	}
+
+	return nil
+}
+
+func (r *gormUserRepository) GetByReferralCode(code string) (*models.User, error) {
+	op := "repository.user.get_by_referral_code"
+
+	r.logger.Debug("db call", slog.String("op", op))
+
+	var user models.User
+
+	if err := r.db.Where("referral_code = ?", code).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// SetReferralCode выдаёт код, только если у пользователя его ещё нет
+func (r *gormUserRepository) SetReferralCode(id uint, code string) (bool, error) {
+	return r.setOnce("repository.user.set_referral_code", id, "referral_code IS NULL", "referral_code", code)
+}
+
+// SetReferrer запоминает пригласившего, только если он ещё не задан
+func (r *gormUserRepository) SetReferrer(id, referrerID uint) (bool, error) {
+	return r.setOnce("repository.user.set_referrer", id, "referred_by_id IS NULL", "referred_by_id", referrerID)
+}
+
+// MarkReferralRewarded отмечает начисление бонусов; false — их уже начислили
+func (r *gormUserRepository) MarkReferralRewarded(id uint) (bool, error) {
+	return r.setOnce("repository.user.mark_referral_rewarded", id, "referral_rewarded = false", "referral_rewarded", true)
+}
+
+func (r *gormUserRepository) SetDriverVerifiedUntil(id uint, until time.Time) error {
+	op := "repository.user.set_driver_verified_until"
+
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("user_id", uint64(id)))
+
+	if err := r.db.Model(&models.User{}).
+		Where("id = ?", id).
+		Update("driver_verified_until", until).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *gormUserRepository) ListVerifiedDriverIDs(ids []uint, now time.Time) ([]uint, error) {
+	op := "repository.user.list_verified_driver_ids"
+
+	r.logger.Debug("db call", slog.String("op", op), slog.Int("count", len(ids)))
+
+	var verified []uint
+
+	if len(ids) == 0 {
+		return verified, nil
+	}
+
+	if err := r.db.Model(&models.User{}).
+		Where("id IN ? AND driver_verified_until > ?", ids, now).
+		Pluck("id", &verified).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return verified, nil
+}
+
+func (r *gormUserRepository) setOnce(op string, id uint, guard string, column string, value any) (bool, error) {
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("user_id", uint64(id)))
+
+	result := r.db.Model(&models.User{}).
+		Where("id = ?", id).
+		Where(guard).
+		Update(column, value)
+
+	if result.Error != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", result.Error))
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
 func (r *gormUserRepository) Delete(id uint) error {
 	op := "repository.user.delete"
 
@@ -146,3 +304,10 @@ func (r *gormUserRepository) Delete(id uint) error {
 
 	return nil
 }
+
+func (r *gormUserRepository) WithDB(db *gorm.DB) UserRepository {
+	return &gormUserRepository{
+		db:     db,
+		logger: r.logger,
+	}
+}
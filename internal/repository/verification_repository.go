@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type VerificationRepository interface {
+	Create(req *models.VerificationRequest) error
+
+	GetByID(id uint) (*models.VerificationRequest, error)
+
+	GetByIDForUpdate(id uint) (*models.VerificationRequest, error)
+
+	// List — очередь заявок, старые первыми; status == nil — заявки в любом статусе
+	List(status *constants.VerificationStatus, filter models.Page) (*dto.Page[models.VerificationRequest], error)
+
+	ListByDriver(driverID uint) ([]models.VerificationRequest, error)
+
+	HasPending(driverID, carID uint) (bool, error)
+
+	Update(req *models.VerificationRequest) error
+
+	// ExpireDue переводит одобренные заявки с истёкшим удостоверением в expired
+	ExpireDue(now time.Time) (int64, error)
+
+	WithDB(db *gorm.DB) VerificationRepository
+}
+
+type gormVerificationRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewVerificationRepository(db *gorm.DB, logger *slog.Logger) VerificationRepository {
+	return &gormVerificationRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *gormVerificationRepository) Create(req *models.VerificationRequest) error {
+	op := "repository.verification.create"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("driver_id", uint64(req.DriverID)),
+		slog.Uint64("car_id", uint64(req.CarID)),
+	)
+
+	if err := r.db.Create(req).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *gormVerificationRepository) GetByID(id uint) (*models.VerificationRequest, error) {
+	return r.getByID(r.db, "repository.verification.get_by_id", id)
+}
+
+func (r *gormVerificationRepository) GetByIDForUpdate(id uint) (*models.VerificationRequest, error) {
+	return r.getByID(r.db.Clauses(clause.Locking{Strength: "UPDATE"}), "repository.verification.get_by_id_for_update", id)
+}
+
+func (r *gormVerificationRepository) getByID(db *gorm.DB, op string, id uint) (*models.VerificationRequest, error) {
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("verification_id", uint64(id)))
+
+	var req models.VerificationRequest
+
+	if err := db.First(&req, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return &req, nil
+}
+
+func (r *gormVerificationRepository) List(status *constants.VerificationStatus, filter models.Page) (*dto.Page[models.VerificationRequest], error) {
+	op := "repository.verification.list"
+
+	r.logger.Debug("db call", slog.String("op", op))
+
+	page := filter.Page
+	pageSize := filter.PageSize
+
+	if page < 1 {
+		page = 1
+	}
+
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 100
+	}
+
+	query := r.db.Model(&models.VerificationRequest{})
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+
+	oldestFirst := keyset[models.VerificationRequest, time.Time]{
+		column: "submitted_at",
+		key: func(v *models.VerificationRequest) (time.Time, uint) {
+			return v.SubmittedAt, v.ID
+		},
+	}
+
+	list, err := oldestFirst.page(query, filter.Cursor, page, pageSize)
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (r *gormVerificationRepository) ListByDriver(driverID uint) ([]models.VerificationRequest, error) {
+	op := "repository.verification.list_by_driver"
+
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("driver_id", uint64(driverID)))
+
+	var list []models.VerificationRequest
+
+	if err := r.db.
+		Where("driver_id = ?", driverID).
+		Order("submitted_at DESC, id DESC").
+		Find(&list).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (r *gormVerificationRepository) HasPending(driverID, carID uint) (bool, error) {
+	op := "repository.verification.has_pending"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("driver_id", uint64(driverID)),
+		slog.Uint64("car_id", uint64(carID)),
+	)
+
+	var count int64
+
+	if err := r.db.Model(&models.VerificationRequest{}).
+		Where("driver_id = ? AND car_id = ? AND status = ?", driverID, carID, constants.VerificationPending).
+		Count(&count).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (r *gormVerificationRepository) Update(req *models.VerificationRequest) error {
+	op := "repository.verification.update"
+
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("verification_id", uint64(req.ID)))
+
+	if err := r.db.Save(req).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *gormVerificationRepository) ExpireDue(now time.Time) (int64, error) {
+	op := "repository.verification.expire_due"
+
+	r.logger.Debug("db call", slog.String("op", op))
+
+	result := r.db.Model(&models.VerificationRequest{}).
+		Where("status = ? AND license_expires_at <= ?", constants.VerificationApproved, now).
+		Update("status", constants.VerificationExpired)
+
+	if result.Error != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", result.Error))
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (r *gormVerificationRepository) WithDB(db *gorm.DB) VerificationRepository {
+	return &gormVerificationRepository{
+		db:     db,
+		logger: r.logger,
+	}
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WaitlistRepository interface {
+	Create(entry *models.WaitlistEntry) error
+
+	GetByIDForUpdate(id uint) (*models.WaitlistEntry, error)
+
+	ExistsActive(tripID, passengerID uint) (bool, error)
+
+	ListActiveByTrip(tripID uint) ([]models.WaitlistEntry, error)
+
+	ListExpiredOffers(now time.Time) ([]models.WaitlistEntry, error)
+
+	OfferedSeats(tripID uint, now time.Time) (int, error)
+
+	Update(entry *models.WaitlistEntry) error
+
+	WithDB(db *gorm.DB) WaitlistRepository
+}
+
+type gormWaitlistRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewWaitlistRepository(db *gorm.DB, logger *slog.Logger) WaitlistRepository {
+	return &gormWaitlistRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+var activeWaitlistStatuses = []constants.WaitlistStatus{
+	constants.WaitlistWaiting,
+	constants.WaitlistOffered,
+}
+
+func (r *gormWaitlistRepository) Create(entry *models.WaitlistEntry) error {
+	op := "repository.waitlist.create"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("trip_id", uint64(entry.TripID)),
+	)
+
+	if err := r.db.Create(entry).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+// GetByIDForUpdate читает запись очереди с блокировкой строки до конца транзакции
+func (r *gormWaitlistRepository) GetByIDForUpdate(id uint) (*models.WaitlistEntry, error) {
+	op := "repository.waitlist.get_by_id_for_update"
+
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("entry_id", uint64(id)))
+
+	var entry models.WaitlistEntry
+
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&entry, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// ExistsActive проверяет, стоит ли пассажир уже в очереди на поездку
+func (r *gormWaitlistRepository) ExistsActive(tripID, passengerID uint) (bool, error) {
+	op := "repository.waitlist.exists_active"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("trip_id", uint64(tripID)),
+		slog.Uint64("passenger_id", uint64(passengerID)),
+	)
+
+	var count int64
+
+	if err := r.db.Model(&models.WaitlistEntry{}).
+		Where("trip_id = ? AND passenger_id = ? AND status IN ?", tripID, passengerID, activeWaitlistStatuses).
+		Count(&count).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// ListActiveByTrip возвращает очередь поездки в порядке записи
+func (r *gormWaitlistRepository) ListActiveByTrip(tripID uint) ([]models.WaitlistEntry, error) {
+	op := "repository.waitlist.list_active_by_trip"
+
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("trip_id", uint64(tripID)))
+
+	var entries []models.WaitlistEntry
+
+	if err := r.db.
+		Where("trip_id = ? AND status IN ?", tripID, activeWaitlistStatuses).
+		Order("id ASC").
+		Find(&entries).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *gormWaitlistRepository) ListExpiredOffers(now time.Time) ([]models.WaitlistEntry, error) {
+	op := "repository.waitlist.list_expired_offers"
+
+	r.logger.Debug("db call", slog.String("op", op))
+
+	var entries []models.WaitlistEntry
+
+	if err := r.db.
+		Where("status = ? AND offer_expires_at <= ?", constants.WaitlistOffered, now).
+		Find(&entries).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// OfferedSeats считает места под ещё действующими предложениями очереди
+func (r *gormWaitlistRepository) OfferedSeats(tripID uint, now time.Time) (int, error) {
+	op := "repository.waitlist.offered_seats"
+
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("trip_id", uint64(tripID)))
+
+	var seats int
+
+	if err := r.db.Model(&models.WaitlistEntry{}).
+		Select("COALESCE(SUM(seats), 0)").
+		Where("trip_id = ? AND status = ? AND offer_expires_at > ?", tripID, constants.WaitlistOffered, now).
+		Scan(&seats).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return 0, err
+	}
+
+	return seats, nil
+}
+
+func (r *gormWaitlistRepository) Update(entry *models.WaitlistEntry) error {
+	op := "repository.waitlist.update"
+
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("entry_id", uint64(entry.ID)))
+
+	if err := r.db.Save(entry).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *gormWaitlistRepository) WithDB(db *gorm.DB) WaitlistRepository {
+	return &gormWaitlistRepository{
+		db:     db,
+		logger: r.logger,
+	}
+}
@@ -0,0 +1,247 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+)
+
+var (
+	ErrInvalidCode        = errors.New("invalid or expired code")
+	ErrTooManyAttempts    = errors.New("too many attempts")
+	ErrTooManyRequests    = errors.New("too many code requests, try again later")
+	ErrPhoneNotRegistered = errors.New("phone is not registered")
+)
+
+const authCodeDigits = 6
+
+type AuthService interface {
+	RequestCode(phone string) error
+
+	Login(phone, code string) (*dto.TokenPair, error)
+
+	Refresh(refreshToken string) (*dto.TokenPair, error)
+
+	PhoneVerifier
+}
+
+// PhoneVerifier подтверждает владение номером одноразовым кодом
+type PhoneVerifier interface {
+	SendCode(phone string) error
+
+	VerifyCode(phone, code string) error
+}
+
+type authService struct {
+	codeRepo    repository.AuthCodeRepository
+	userRepo    repository.UserRepository
+	tokens      TokenService
+	sender      CodeSender
+	codeTTL     time.Duration
+	maxAttempts int
+	cooldown    time.Duration
+	window      time.Duration
+	maxRequests int
+	logger      *slog.Logger
+}
+
+func NewAuthService(
+	codeRepo repository.AuthCodeRepository,
+	userRepo repository.UserRepository,
+	tokens TokenService,
+	sender CodeSender,
+	codeTTL time.Duration,
+	maxAttempts int,
+	cooldown time.Duration,
+	window time.Duration,
+	maxRequests int,
+	logger *slog.Logger,
+) AuthService {
+	return &authService{
+		codeRepo:    codeRepo,
+		userRepo:    userRepo,
+		tokens:      tokens,
+		sender:      sender,
+		codeTTL:     codeTTL,
+		maxAttempts: maxAttempts,
+		cooldown:    cooldown,
+		window:      window,
+		maxRequests: maxRequests,
+		logger:      logger,
+	}
+}
+
+func (s *authService) RequestCode(phone string) error {
+	phone = normalizePhone(phone)
+
+	if _, err := s.userRepo.GetByPhone(phone); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrPhoneNotRegistered
+		}
+		return err
+	}
+
+	return s.SendCode(phone)
+}
+
+// SendCode выдаёт код на номер, если он не запрашивался слишком часто
+func (s *authService) SendCode(phone string) error {
+	op := "service.auth.SendCode"
+
+	phone = normalizePhone(phone)
+	now := time.Now().UTC()
+
+	recent, err := s.codeRepo.CountSince(phone, now.Add(-s.cooldown))
+	if err != nil {
+		return err
+	}
+	sent, err := s.codeRepo.CountSince(phone, now.Add(-s.window))
+	if err != nil {
+		return err
+	}
+	if recent > 0 || sent >= int64(s.maxRequests) {
+		s.logger.Warn("auth code rate limited", slog.String("op", op), slog.String("phone", phone))
+		return ErrTooManyRequests
+	}
+
+	code, err := generateCode(authCodeDigits)
+	if err != nil {
+		return err
+	}
+
+	authCode := &models.AuthCode{
+		Phone:     phone,
+		CodeHash:  hashCode(phone, code),
+		ExpiresAt: now.Add(s.codeTTL),
+	}
+
+	if err := s.codeRepo.Create(authCode); err != nil {
+		s.logger.Error("failed to store auth code", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	if err := s.sender.Send(phone, code); err != nil {
+		s.logger.Error("failed to send auth code", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+func (s *authService) Login(phone, code string) (*dto.TokenPair, error) {
+	op := "service.auth.Login"
+
+	phone = normalizePhone(phone)
+
+	if err := s.VerifyCode(phone, code); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByPhone(phone)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrPhoneNotRegistered
+		}
+		return nil, err
+	}
+
+	s.logger.Info("user logged in", slog.String("op", op), slog.Uint64("user_id", uint64(user.ID)))
+	return s.tokens.Issue(user.ID)
+}
+
+// VerifyCode гасит действующий код номера. Попытка расходуется до сравнения кода,
+// а попытки суммируются по всем непогашенным кодам за окно, поэтому ни параллельные
+// запросы, ни новый код не дают продолжить перебор сверх лимита
+func (s *authService) VerifyCode(phone, code string) error {
+	op := "service.auth.VerifyCode"
+
+	phone = normalizePhone(phone)
+	now := time.Now().UTC()
+
+	authCode, err := s.codeRepo.GetActiveByPhone(phone, now)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrInvalidCode
+		}
+		return err
+	}
+
+	allowed, err := s.codeRepo.TakeAttempt(authCode, now.Add(-s.window), s.maxAttempts)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrTooManyAttempts
+	}
+
+	if subtle.ConstantTimeCompare([]byte(authCode.CodeHash), []byte(hashCode(phone, code))) != 1 {
+		s.logger.Warn("invalid auth code", slog.String("op", op), slog.String("phone", phone))
+		return ErrInvalidCode
+	}
+
+	// код уже погасил параллельный запрос
+	if err := s.codeRepo.MarkUsed(authCode.ID, now); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrInvalidCode
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *authService) Refresh(refreshToken string) (*dto.TokenPair, error) {
+	userID, err := s.tokens.ParseRefresh(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	return s.tokens.Issue(userID)
+}
+
+func normalizePhone(phone string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '(', ')':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(phone))
+}
+
+func generateCode(digits int) (string, error) {
+	max := big.NewInt(1)
+	for i := 0; i < digits; i++ {
+		max.Mul(max, big.NewInt(10))
+	}
+
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", digits, n), nil
+}
+
+func hashCode(phone, code string) string {
+	sum := sha256.Sum256([]byte(phone + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrInvalidBlobKey = errors.New("invalid blob key")
+
+// BlobStore — хранилище загружаемых файлов (фотографий автомобилей)
+type BlobStore interface {
+	Put(key string, r io.Reader) error
+
+	Open(key string) (io.ReadCloser, error)
+
+	Delete(key string) error
+
+	// URL — публичный адрес файла для клиента
+	URL(key string) string
+}
+
+// LocalBlobStore хранит файлы в каталоге на диске; раздачу каталога по baseURL
+// настраивает сервер, закрытые файлы читаются только через Open
+type LocalBlobStore struct {
+	dir     string
+	baseURL string
+	logger  *slog.Logger
+}
+
+func NewLocalBlobStore(dir, baseURL string, logger *slog.Logger) (*LocalBlobStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &LocalBlobStore{
+		dir:     dir,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		logger:  logger,
+	}, nil
+}
+
+func (s *LocalBlobStore) Put(key string, r io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// пишем во временный файл, чтобы по ключу не оказался недописанный файл
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	s.logger.Debug("blob stored", slog.String("key", key))
+	return nil
+}
+
+func (s *LocalBlobStore) Open(key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.Open(path)
+}
+
+func (s *LocalBlobStore) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalBlobStore) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+// path не даёт ключу выйти за пределы каталога хранилища
+func (s *LocalBlobStore) path(key string) (string, error) {
+	if key == "" || !filepath.IsLocal(key) {
+		return "", ErrInvalidBlobKey
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+// sniffContentType определяет формат файла по первым байтам;
+// возвращённый reader читает файл с начала
+func sniffContentType(r io.Reader) (io.Reader, string, error) {
+	br := bufio.NewReaderSize(r, 512)
+
+	head, err := br.Peek(512)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, "", err
+	}
+
+	return br, http.DetectContentType(head), nil
+}
+
+// readLimited читает файл целиком, если он не больше limit байт, иначе возвращает tooLarge;
+// лишний байт сверх лимита отличает файл ровно на limit от обрезанного
+func readLimited(r io.Reader, limit int64, tooLarge error) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, tooLarge
+	}
+	return data, nil
+}
+
+// newBlobName — случайное имя файла, не угадываемое по соседним ключам
+func newBlobName() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestReadLimited(t *testing.T) {
+	errTooLarge := errors.New("too large")
+
+	cases := []struct {
+		name    string
+		size    int
+		wantErr error
+	}{
+		{name: "empty", size: 0},
+		{name: "below limit", size: 9},
+		{name: "exactly limit", size: 10},
+		{name: "one byte over", size: 11, wantErr: errTooLarge},
+		{name: "far over", size: 1000, wantErr: errTooLarge},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			data, err := readLimited(strings.NewReader(strings.Repeat("x", c.size)), 10, errTooLarge)
+			if !errors.Is(err, c.wantErr) {
+				t.Fatalf("error = %v, want %v", err, c.wantErr)
+			}
+			if err == nil && len(data) != c.size {
+				t.Errorf("read %d bytes, want %d", len(data), c.size)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+)
+
+// BookingExpiryWorker переводит в expired заявки, на которые водитель
+// не ответил вовремя или чья поездка уже не принимает брони
+type BookingExpiryWorker struct {
+	repo     repository.BookingRepository
+	bookings BookingService
+	timeout  time.Duration
+	logger   *slog.Logger
+	tick     time.Duration
+}
+
+func NewBookingExpiryWorker(
+	repo repository.BookingRepository,
+	bookings BookingService,
+	timeout time.Duration,
+	logger *slog.Logger,
+	tick time.Duration,
+) *BookingExpiryWorker {
+	return &BookingExpiryWorker{
+		repo:     repo,
+		bookings: bookings,
+		timeout:  timeout,
+		logger:   logger,
+		tick:     tick,
+	}
+}
+
+func (w *BookingExpiryWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.tick)
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				w.logger.Info("booking expiry worker stopped")
+				return
+
+			case <-ticker.C:
+				w.run(time.Now().UTC())
+			}
+		}
+	}()
+}
+
+func (w *BookingExpiryWorker) run(now time.Time) {
+	due, err := w.repo.ListExpirable(now, w.timeout)
+	if err != nil {
+		w.logger.Error(
+			"failed to list expirable bookings",
+			slog.Any("error", err),
+		)
+		return
+	}
+
+	for _, booking := range due {
+		if err := w.bookings.Expire(booking.ID); err != nil {
+			w.logger.Error(
+				"failed to expire booking",
+				slog.Uint64("booking_id", uint64(booking.ID)),
+				slog.Any("error", err),
+			)
+		}
+	}
+}
@@ -2,8 +2,8 @@ package services
 
 import (
 	"errors"
-	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/mutsaevz/team-5-ambitious/internal/constants"
 	"github.com/mutsaevz/team-5-ambitious/internal/dto"
@@ -12,27 +12,50 @@ import (
 	"gorm.io/gorm"
 )
 
+var (
+	ErrBookingActive     = errors.New("active booking must be cancelled first")
+	ErrTripNotBookable   = errors.New("trip is not open for booking")
+	ErrSelfBooking       = errors.New("driver cannot book own trip")
+	ErrDuplicateBooking  = errors.New("passenger already has an active booking for this trip")
+	ErrInvalidSeatsCount = errors.New("invalid seats count")
+)
+
 type BookingService interface {
-	Create(req *dto.BookingCreateRequest) (*models.Booking, error)
+	Create(passengerID uint, req *dto.BookingCreateRequest) (*models.Booking, error)
+
+	// Quote считает цену брони до её создания; та же цена фиксируется при Create
+	Quote(req *dto.BookingQuoteRequest) (*dto.PriceQuote, error)
 
-	List(filter models.Page) ([]models.Booking, error)
+	// List — все брони для администратора, иначе только брони, где пользователь пассажир или водитель
+	List(actorID uint, filter models.Page) (*dto.Page[models.Booking], error)
 
 	Approve(bookingID uint, driverID uint) error
 
 	Rejected(bookingID uint, driverID uint) error
 
-	GetByID(id uint) (*models.Booking, error)
+	Cancel(bookingID uint, actorID uint) (*models.Booking, error)
+
+	Expire(bookingID uint) error
+
+	GetByID(actorID, id uint) (*models.Booking, error)
 
 	GetAllPendingBookingsByTripID(driverID, tripID uint) ([]models.Booking, error)
 
-	Update(id uint, req *dto.BookingUpdateRequest) (*models.Booking, error)
+	Update(actorID, id uint, req *dto.BookingUpdateRequest) (*models.Booking, error)
 
-	Delete(id uint) error
+	Delete(actorID, id uint) error
 }
 
 type bookingService struct {
 	bookingRepo repository.BookingRepository
 	tripRepo    repository.TripRepository
+	userRepo    repository.UserRepository
+	policy      Policy
+	pricing     PricingEngine
+	promo       PromoService
+	machine     *bookingStateMachine
+	notifier    Notifier
+	waitlist    WaitlistService
 	db          *gorm.DB
 	logger      *slog.Logger
 }
@@ -40,31 +63,86 @@ type bookingService struct {
 func NewBookingService(
 	bookingRepo repository.BookingRepository,
 	tripRepo repository.TripRepository,
+	userRepo repository.UserRepository,
+	promoRepo repository.PromoRepository,
+	ledger LedgerService,
+	policy Policy,
+	cancellation CancellationPolicy,
+	pricing PricingEngine,
+	promo PromoService,
+	notifier Notifier,
+	waitlist WaitlistService,
 	db *gorm.DB,
 	logger *slog.Logger,
 ) BookingService {
 	return &bookingService{
 		bookingRepo: bookingRepo,
 		tripRepo:    tripRepo,
+		userRepo:    userRepo,
+		policy:      policy,
+		pricing:     pricing,
+		promo:       promo,
+		machine:     newBookingStateMachine(bookingRepo, tripRepo, promoRepo, ledger, cancellation, logger),
+		notifier:    notifier,
+		waitlist:    waitlist,
 		db:          db,
 		logger:      logger,
 	}
 }
 
 func (s *bookingService) Create(
+	passengerID uint,
 	req *dto.BookingCreateRequest,
 ) (*models.Booking, error) {
 	op := "service.booking.Create"
 
 	s.logger.Debug(" call", slog.String("op", op))
 
+	seats := req.Seats
+	if seats == 0 {
+		seats = 1
+	}
+
 	booking := &models.Booking{
 		TripID:        req.TripID,
-		PassengerID:   req.PassengerID,
+		PassengerID:   passengerID,
+		Seats:         seats,
 		BookingStatus: constants.BookingPending,
 	}
 
-	if err := s.bookingRepo.Create(booking); err != nil {
+	if err := s.policy.Authorize(passengerID, ActionCreate, BookingAccess{Booking: booking}); err != nil {
+		return nil, err
+	}
+
+	trip, err := s.tripRepo.GetByID(req.TripID)
+	if err != nil {
+		return nil, err
+	}
+
+	if booking.FromStop, booking.ToStop, err = resolveSegment(trip, req.FromStop, req.ToStop); err != nil {
+		return nil, err
+	}
+
+	if err := s.validateCreate(booking, trip); err != nil {
+		s.logger.Warn("booking rejected", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	applyQuote(booking, s.pricing.Quote(trip, booking.FromStop, booking.ToStop, booking.Seats, time.Now()))
+
+	if trip.BookingMode == constants.BookingModeInstant {
+		if err := s.createInstant(booking, req.PromoCode); err != nil {
+			s.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
+			return nil, err
+		}
+		s.logger.Info("booking created and approved", slog.String("op", op), slog.Uint64("booking_id", uint64(booking.ID)))
+		return booking, nil
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		return s.insert(tx, booking, req.PromoCode)
+	})
+	if err != nil {
 		s.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
 		return nil, err
 	}
@@ -72,82 +150,288 @@ func (s *bookingService) Create(
 	return booking, nil
 }
 
-func (s *bookingService) Approve(bookingID, driverID uint) error {
-	return s.db.Transaction(func(tx *gorm.DB) error {
-		bookingRepo := s.bookingRepo.WithDB(tx)
-		tripRepo := s.tripRepo.WithDB(tx)
+func (s *bookingService) Quote(req *dto.BookingQuoteRequest) (*dto.PriceQuote, error) {
+	op := "service.booking.Quote"
 
-		booking, err := bookingRepo.GetByID(bookingID)
-		if err != nil {
+	s.logger.Debug(" call", slog.String("op", op), slog.Uint64("trip_id", uint64(req.TripID)))
+
+	seats := req.Seats
+	if seats == 0 {
+		seats = 1
+	}
+
+	trip, err := s.tripRepo.GetByID(req.TripID)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to, err := resolveSegment(trip, req.FromStop, req.ToStop)
+	if err != nil {
+		return nil, err
+	}
+
+	if seats > segmentFree(trip, from, to) {
+		return nil, ErrInvalidSeatsCount
+	}
+
+	quote := s.pricing.Quote(trip, from, to, seats, time.Now())
+
+	if req.PromoCode != "" {
+		if err := s.promo.Preview(req.PromoCode, &quote); err != nil {
+			return nil, err
+		}
+	}
+
+	return &quote, nil
+}
+
+// insert сохраняет заявку вместе с применением промокода
+func (s *bookingService) insert(tx *gorm.DB, booking *models.Booking, promoCode string) error {
+	if promoCode != "" {
+		if err := s.promo.Apply(tx, promoCode, booking); err != nil {
 			return err
 		}
+	}
 
-		if booking.BookingStatus != constants.BookingPending {
-			return errors.New("booking is not pending")
+	if err := s.bookingRepo.WithDB(tx).Create(booking); err != nil {
+		// параллельная заявка того же пассажира успела раньше
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			return ErrDuplicateBooking
 		}
+		return err
+	}
+
+	return s.promo.Record(tx, booking)
+}
 
-		trip, err := tripRepo.GetByID(booking.TripID)
+// createInstant создаёт заявку и сразу подтверждает её от имени системы:
+// если мест или денег не хватает, откатывается и сама заявка
+func (s *bookingService) createInstant(booking *models.Booking, promoCode string) error {
+	var notifications []Notification
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		trip, err := s.tripRepo.WithDB(tx).GetByIDForUpdate(booking.TripID)
 		if err != nil {
 			return err
 		}
 
-		if trip.DriverID != driverID {
-			return errors.New("forbidden")
+		// система подтверждает и на начавшейся поездке, поэтому статус перепроверяется под блокировкой
+		if trip.TripStatus != string(constants.TripPublished) {
+			return ErrTripNotBookable
 		}
 
-		if trip.AvailableSeats <= 0 {
-			return errors.New("no available seats")
+		if err := s.insert(tx, booking, promoCode); err != nil {
+			return err
 		}
 
-		// Водитель одобряет
-		trip.AvailableSeats--
-		booking.BookingStatus = constants.BookingApproved
-
-		err = tripRepo.Update(trip)
-		if err != nil {
+		if err := s.checkOfferedSeats(tx, booking, trip); err != nil {
 			return err
 		}
 
-		err = bookingRepo.Update(booking)
+		result, err := s.machine.Transition(tx, booking, trip, constants.BookingApproved, actorSystem)
 		if err != nil {
 			return err
 		}
 
+		notifications = result.notifications
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	dispatch(s.notifier, s.logger, notifications)
+	return nil
+}
+
+// validateCreate проверяет, можно ли вообще подать заявку на поездку
+func (s *bookingService) validateCreate(booking *models.Booking, trip *models.Trip) error {
+	if trip.TripStatus != string(constants.TripPublished) {
+		return ErrTripNotBookable
+	}
+
+	if trip.DriverID == booking.PassengerID {
+		return ErrSelfBooking
+	}
+
+	free, err := s.bookableSeats(s.db, booking, trip)
+	if err != nil {
+		return err
+	}
+	if booking.Seats < 1 || booking.Seats > free {
+		return ErrInvalidSeatsCount
+	}
+
+	exists, err := s.bookingRepo.Exists(booking.TripID, booking.PassengerID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrDuplicateBooking
+	}
+
+	return nil
+}
+
+func (s *bookingService) Approve(bookingID, driverID uint) error {
+	_, err := s.transition(bookingID, driverID, ActionManage, func(bookingActor) constants.BookingStatus {
+		return constants.BookingApproved
+	})
+	return err
 }
 
 func (s *bookingService) Rejected(bookingID uint, driverID uint) error {
-	return s.db.Transaction(func(tx *gorm.DB) error {
-		bookingRepo := s.bookingRepo.WithDB(tx)
-		tripRepo := s.tripRepo.WithDB(tx)
+	_, err := s.transition(bookingID, driverID, ActionManage, func(bookingActor) constants.BookingStatus {
+		return constants.BookingRejected
+	})
+	return err
+}
 
-		booking, err := bookingRepo.GetByID(bookingID)
+func (s *bookingService) Cancel(bookingID uint, actorID uint) (*models.Booking, error) {
+	return s.transition(bookingID, actorID, ActionCancel, func(actor bookingActor) constants.BookingStatus {
+		if actor == actorPassenger {
+			return constants.BookingCancelledByPassenger
+		}
+		return constants.BookingCancelledByDriver
+	})
+}
+
+// Expire закрывает просроченную заявку от имени системы
+func (s *bookingService) Expire(bookingID uint) error {
+	var notifications []Notification
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		booking, err := s.bookingRepo.WithDB(tx).GetByIDForUpdate(bookingID)
 		if err != nil {
-			return fmt.Errorf("booking not found: %w", err)
+			return err
 		}
 
-		trip, err := tripRepo.GetByID(booking.TripID)
+		trip, err := s.tripRepo.WithDB(tx).GetByIDForUpdate(booking.TripID)
+		if errors.Is(err, repository.ErrNotFound) {
+			// поездку удалили: у ожидающей заявки нет ни мест, ни денег на поездке
+			trip = &models.Trip{Base: models.Base{ID: booking.TripID}}
+		} else if err != nil {
+			return err
+		}
+
+		result, err := s.machine.Transition(tx, booking, trip, constants.BookingExpired, actorSystem)
 		if err != nil {
-			return fmt.Errorf("trip not found: %w", err)
+			return err
+		}
+
+		notifications = result.notifications
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	dispatch(s.notifier, s.logger, notifications)
+	return nil
+}
+
+// transition проводит бронь через машину состояний в одной транзакции,
+// а уведомления отправляет после её фиксации
+func (s *bookingService) transition(
+	bookingID, actorID uint,
+	action Action,
+	target func(actor bookingActor) constants.BookingStatus,
+) (*models.Booking, error) {
+	var (
+		booking       *models.Booking
+		notifications []Notification
+		released      bool
+	)
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+
+		// блокируем бронь, затем поездку — всегда в этом порядке, чтобы
+		// параллельные переходы не читали устаревший статус и число мест
+		booking, err = s.bookingRepo.WithDB(tx).GetByIDForUpdate(bookingID)
+		if err != nil {
+			return err
+		}
+
+		trip, err := s.tripRepo.WithDB(tx).GetByIDForUpdate(booking.TripID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.policy.Authorize(actorID, action, BookingAccess{Booking: booking, Trip: trip}); err != nil {
+			return err
 		}
 
-		if trip.DriverID != driverID {
-			return errors.New("forbidden")
+		actor, err := s.userRepo.GetByID(actorID)
+		if err != nil {
+			return err
 		}
 
-		if booking.BookingStatus != constants.BookingPending {
-			return errors.New("booking is not pending")
+		kind, ok := resolveBookingActor(actor, booking, trip)
+		if !ok {
+			return ErrForbidden
 		}
 
-		booking.BookingStatus = constants.BookingRejected
+		to := target(kind)
+		if to == constants.BookingApproved && booking.BookingStatus == constants.BookingPending {
+			if err := s.checkOfferedSeats(tx, booking, trip); err != nil {
+				return err
+			}
+		}
 
-		if err := bookingRepo.Update(booking); err != nil {
+		result, err := s.machine.Transition(tx, booking, trip, to, kind)
+		if err != nil {
 			return err
 		}
 
+		notifications = result.notifications
+		released = result.seatsReleased
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	dispatch(s.notifier, s.logger, notifications)
+
+	if released {
+		s.promoteWaitlist(booking.TripID)
+	}
+
+	return booking, nil
+}
+
+// bookableSeats — свободные места участка брони за вычетом обещанных очереди
+func (s *bookingService) bookableSeats(tx *gorm.DB, booking *models.Booking, trip *models.Trip) (int, error) {
+	offered, err := s.waitlist.OfferedSeats(tx, trip.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	// предложение очереди выдаётся на весь маршрут и занимает каждый перегон
+	return segmentFree(trip, booking.FromStop, booking.ToStop) - offered, nil
+}
+
+// checkOfferedSeats вызывается под блокировкой поездки перед подтверждением брони
+func (s *bookingService) checkOfferedSeats(tx *gorm.DB, booking *models.Booking, trip *models.Trip) error {
+	free, err := s.bookableSeats(tx, booking, trip)
+	if err != nil {
+		return err
+	}
+	if booking.Seats > free {
+		return ErrNoAvailableSeats
+	}
+	return nil
+}
+
+// promoteWaitlist отдаёт освободившиеся места очереди; сбой не отменяет уже проведённый переход
+func (s *bookingService) promoteWaitlist(tripID uint) {
+	if err := s.waitlist.Promote(tripID); err != nil {
+		s.logger.Error("failed to promote waitlist",
+			slog.Uint64("trip_id", uint64(tripID)),
+			slog.Any("error", err),
+		)
+	}
 }
 
 func (s *bookingService) GetAllPendingBookingsByTripID(driverID, tripID uint) ([]models.Booking, error) {
@@ -156,6 +440,15 @@ func (s *bookingService) GetAllPendingBookingsByTripID(driverID, tripID uint) ([
 
 	s.logger.Debug(" call", slog.String("op", op), slog.Uint64("trip_id", uint64(tripID)))
 
+	trip, err := s.tripRepo.GetByID(tripID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.policy.Authorize(driverID, ActionManage, trip); err != nil {
+		return nil, err
+	}
+
 	bookings, err := s.bookingRepo.GetAllPendingBookingsByTripID(driverID, tripID)
 	if err != nil {
 		s.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
@@ -165,22 +458,35 @@ func (s *bookingService) GetAllPendingBookingsByTripID(driverID, tripID uint) ([
 	return bookings, nil
 }
 
-func (s *bookingService) List(filter models.Page) ([]models.Booking, error) {
+func (s *bookingService) List(actorID uint, filter models.Page) (*dto.Page[models.Booking], error) {
 
 	op := "service.booking.list"
 
-	s.logger.Debug(" call", slog.String("op", op))
+	s.logger.Debug(" call", slog.String("op", op), slog.Uint64("actor_id", uint64(actorID)))
 
-	bookings, err := s.bookingRepo.List(filter)
+	actor, err := s.userRepo.GetByID(actorID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrUnauthenticated
+		}
+		return nil, err
+	}
+
+	var bookings *dto.Page[models.Booking]
+	if actor.Role == constants.RoleAdmin {
+		bookings, err = s.bookingRepo.List(filter)
+	} else {
+		bookings, err = s.bookingRepo.ListByUser(actorID, filter)
+	}
 	if err != nil {
 		s.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
 		return nil, err
 	}
-	s.logger.Info("bookings listed", slog.String("op", op), slog.Int("count", len(bookings)))
+	s.logger.Info("bookings listed", slog.String("op", op), slog.Int("count", len(bookings.Items)))
 	return bookings, nil
 }
 
-func (s *bookingService) GetByID(id uint) (*models.Booking, error) {
+func (s *bookingService) GetByID(actorID, id uint) (*models.Booking, error) {
 	op := "service.booking.GetByID"
 
 	s.logger.Debug(" call", slog.String("op", op), slog.Uint64("booking_id", uint64(id)))
@@ -190,36 +496,61 @@ func (s *bookingService) GetByID(id uint) (*models.Booking, error) {
 		s.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
 		return nil, err
 	}
+
+	trip, err := s.tripRepo.GetByID(booking.TripID)
+	if err != nil {
+		return nil, err
+	}
+
+	// бронь видят только её пассажир, водитель поездки и администратор
+	if err := s.policy.Authorize(actorID, ActionView, BookingAccess{Booking: booking, Trip: trip}); err != nil {
+		return nil, err
+	}
+
 	return booking, nil
 }
 
-func (s *bookingService) Update(id uint, req *dto.BookingUpdateRequest) (*models.Booking, error) {
+func (s *bookingService) Update(actorID, id uint, req *dto.BookingUpdateRequest) (*models.Booking, error) {
 	op := "service.booking.Update"
 
 	s.logger.Debug(" call", slog.String("op", op), slog.Uint64("booking_id", uint64(id)))
 
-	booking, err := s.bookingRepo.GetByID(id)
-	if err != nil {
-		s.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
-		return nil, err
-	}
-	if req.BookingStatus != nil {
-		booking.BookingStatus = *req.BookingStatus
+	if req.BookingStatus == nil {
+		return s.bookingRepo.GetByID(id)
 	}
 
-	if err := s.bookingRepo.Update(booking); err != nil {
+	// смена статуса через PATCH подчиняется тем же правилам, что и отдельные команды
+	booking, err := s.transition(id, actorID, ActionUpdate, func(bookingActor) constants.BookingStatus {
+		return *req.BookingStatus
+	})
+	if err != nil {
 		s.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
 		return nil, err
 	}
+
 	s.logger.Info("booking updated", slog.String("op", op), slog.Uint64("booking_id", uint64(id)))
-	return booking, err
+	return booking, nil
 }
 
-func (s *bookingService) Delete(id uint) error {
+func (s *bookingService) Delete(actorID, id uint) error {
 
 	op := "service.booking.Delete"
 	s.logger.Debug(" call", slog.String("op", op), slog.Uint64("booking_id", uint64(id)))
 
+	booking, err := s.bookingRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.policy.Authorize(actorID, ActionDelete, BookingAccess{Booking: booking}); err != nil {
+		return err
+	}
+
+	// активная бронь держит место и деньги — сначала её нужно отменить
+	if booking.BookingStatus == constants.BookingPending || booking.BookingStatus == constants.BookingApproved {
+		return ErrBookingActive
+	}
+
 	if err := s.bookingRepo.Delete(id); err != nil {
 		s.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
 		return err
@@ -0,0 +1,298 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidTransition = errors.New("invalid booking status transition")
+	ErrNoAvailableSeats  = errors.New("no available seats")
+)
+
+// bookingActor — в какой роли участник меняет статус брони
+type bookingActor string
+
+const (
+	actorPassenger bookingActor = "passenger"
+	actorDriver    bookingActor = "driver"
+	actorSystem    bookingActor = "system" // воркеры и каскадные операции
+	actorAdmin     bookingActor = "admin"
+)
+
+type bookingTransition struct {
+	actors []bookingActor
+	// переход возможен только пока поездка не началась
+	requiresPublishedTrip bool
+}
+
+// bookingTransitions — единственное описание допустимых переходов брони
+var bookingTransitions = map[constants.BookingStatus]map[constants.BookingStatus]bookingTransition{
+	constants.BookingPending: {
+		constants.BookingApproved:             {actors: []bookingActor{actorDriver, actorSystem}, requiresPublishedTrip: true},
+		constants.BookingRejected:             {actors: []bookingActor{actorDriver, actorSystem}},
+		constants.BookingCancelledByPassenger: {actors: []bookingActor{actorPassenger}, requiresPublishedTrip: true},
+		constants.BookingCancelledByDriver:    {actors: []bookingActor{actorDriver, actorSystem}},
+		constants.BookingExpired:              {actors: []bookingActor{actorSystem}},
+	},
+	constants.BookingApproved: {
+		constants.BookingCancelledByPassenger: {actors: []bookingActor{actorPassenger}, requiresPublishedTrip: true},
+		constants.BookingCancelledByDriver:    {actors: []bookingActor{actorDriver, actorSystem}},
+	},
+}
+
+// bookingStateMachine проверяет переходы и выполняет их побочные эффекты
+// (места в поездке, деньги, уведомления) внутри транзакции вызывающего
+type bookingStateMachine struct {
+	bookingRepo  repository.BookingRepository
+	tripRepo     repository.TripRepository
+	promoRepo    repository.PromoRepository
+	ledger       LedgerService
+	cancellation CancellationPolicy
+	logger       *slog.Logger
+	now          func() time.Time
+}
+
+type transitionResult struct {
+	notifications []Notification
+	// seatsReleased — переход вернул места в продажу, очередь поездки можно продвигать
+	seatsReleased bool
+}
+
+func newBookingStateMachine(
+	bookingRepo repository.BookingRepository,
+	tripRepo repository.TripRepository,
+	promoRepo repository.PromoRepository,
+	ledger LedgerService,
+	cancellation CancellationPolicy,
+	logger *slog.Logger,
+) *bookingStateMachine {
+	return &bookingStateMachine{
+		bookingRepo:  bookingRepo,
+		tripRepo:     tripRepo,
+		promoRepo:    promoRepo,
+		ledger:       ledger,
+		cancellation: cancellation,
+		logger:       logger,
+		now:          time.Now,
+	}
+}
+
+// resolveBookingActor определяет роль пользователя по отношению к брони
+func resolveBookingActor(actor *models.User, booking *models.Booking, trip *models.Trip) (bookingActor, bool) {
+	switch {
+	case actor.ID == booking.PassengerID:
+		return actorPassenger, true
+	case actor.ID == trip.DriverID:
+		return actorDriver, true
+	case actor.Role == constants.RoleAdmin:
+		return actorAdmin, true
+	}
+	return "", false
+}
+
+func (m *bookingStateMachine) Transition(
+	tx *gorm.DB,
+	booking *models.Booking,
+	trip *models.Trip,
+	to constants.BookingStatus,
+	actor bookingActor,
+) (*transitionResult, error) {
+	from := booking.BookingStatus
+
+	t, ok := bookingTransitions[from][to]
+	if !ok || !t.allows(actor) {
+		return nil, fmt.Errorf("%w: %s -> %s by %s", ErrInvalidTransition, from, to, actor)
+	}
+
+	if t.requiresPublishedTrip && actor != actorSystem && trip.TripStatus != string(constants.TripPublished) {
+		return nil, fmt.Errorf("%w: trip is %s", ErrInvalidTransition, trip.TripStatus)
+	}
+
+	booking.BookingStatus = to
+
+	if err := m.bookingRepo.WithDB(tx).Update(booking); err != nil {
+		return nil, err
+	}
+
+	result := &transitionResult{}
+
+	var err error
+	switch to {
+	case constants.BookingApproved:
+		err = m.onApproved(tx, booking, trip, result)
+	case constants.BookingRejected:
+		err = m.onReleased(tx, booking, trip, from, booking.PassengerID, false, result)
+	case constants.BookingCancelledByPassenger:
+		err = m.onReleased(tx, booking, trip, from, trip.DriverID, !booking.PenaltyFreeCancel, result)
+	case constants.BookingCancelledByDriver, constants.BookingExpired:
+		err = m.onReleased(tx, booking, trip, from, booking.PassengerID, false, result)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	m.logger.Info("booking transition",
+		slog.Uint64("booking_id", uint64(booking.ID)),
+		slog.String("from", string(from)),
+		slog.String("to", string(to)),
+		slog.String("actor", string(actor)),
+	)
+
+	return result, nil
+}
+
+func (m *bookingStateMachine) onApproved(tx *gorm.DB, booking *models.Booking, trip *models.Trip, result *transitionResult) error {
+	if err := m.reserveSeats(tx, booking, trip); err != nil {
+		return err
+	}
+
+	// блокируем стоимость всех мест на кошельке пассажира до завершения поездки
+	if err := m.ledger.WithDB(tx).Hold(booking, bookingPrice(booking, trip)); err != nil {
+		return err
+	}
+
+	result.notify(booking.PassengerID, booking, "booking_approved", "Ваша бронь подтверждена")
+	return nil
+}
+
+// onReleased освобождает место и деньги брони, которая перестала быть активной
+func (m *bookingStateMachine) onReleased(
+	tx *gorm.DB,
+	booking *models.Booking,
+	trip *models.Trip,
+	from constants.BookingStatus,
+	notifyUserID uint,
+	applyPolicy bool,
+	result *transitionResult,
+) error {
+	if from == constants.BookingApproved {
+		if err := m.releaseSeats(tx, booking, trip); err != nil {
+			return err
+		}
+		result.seatsReleased = true
+	}
+
+	ledger := m.ledger.WithDB(tx)
+
+	held, err := ledger.HeldAmount(booking.ID)
+	if err != nil {
+		return err
+	}
+
+	// при отмене пассажиром возврат считает политика отмены, иначе возвращается всё
+	refund := held
+	if applyPolicy {
+		refund = m.cancellation.Refund(held, trip.StartTime, m.now().UTC())
+	}
+
+	if err := ledger.Release(booking, refund); err != nil {
+		return err
+	}
+
+	// удержанный штраф достаётся водителю
+	if err := ledger.Settle(booking, trip.DriverID, held-refund); err != nil {
+		return err
+	}
+
+	if booking.PromoCodeID != nil {
+		if err := m.promoRepo.WithDB(tx).DeleteRedemption(booking.ID); err != nil {
+			return err
+		}
+	}
+
+	result.notify(notifyUserID, booking, "booking_"+string(booking.BookingStatus), "Статус брони изменён: "+string(booking.BookingStatus))
+	return nil
+}
+
+// reserveSeats занимает места брони: у поездки с остановками — на перегонах её участка
+func (m *bookingStateMachine) reserveSeats(tx *gorm.DB, booking *models.Booking, trip *models.Trip) error {
+	tripRepo := m.tripRepo.WithDB(tx)
+
+	if len(trip.Stops) == 0 {
+		reserved, err := tripRepo.ReserveSeats(trip.ID, booking.Seats)
+		if err != nil {
+			return err
+		}
+		if !reserved {
+			return ErrNoAvailableSeats
+		}
+
+		trip.AvailableSeats -= booking.Seats
+		return nil
+	}
+
+	reserved, err := tripRepo.ReserveLegs(trip.ID, booking.FromStop, booking.ToStop, booking.Seats)
+	if err != nil {
+		return err
+	}
+	if !reserved {
+		return ErrNoAvailableSeats
+	}
+
+	return m.syncLegs(tripRepo, booking, trip, booking.Seats)
+}
+
+func (m *bookingStateMachine) releaseSeats(tx *gorm.DB, booking *models.Booking, trip *models.Trip) error {
+	tripRepo := m.tripRepo.WithDB(tx)
+
+	if len(trip.Stops) == 0 {
+		return tripRepo.ReleaseSeats(trip.ID, booking.Seats)
+	}
+
+	if err := tripRepo.ReleaseLegs(trip.ID, booking.FromStop, booking.ToStop, booking.Seats); err != nil {
+		return err
+	}
+
+	return m.syncLegs(tripRepo, booking, trip, -booking.Seats)
+}
+
+// syncLegs отражает изменение загрузки перегонов в загруженной поездке
+// и пересчитывает число мест, свободных на всём маршруте
+func (m *bookingStateMachine) syncLegs(tripRepo repository.TripRepository, booking *models.Booking, trip *models.Trip, delta int) error {
+	for i := range trip.Stops {
+		if trip.Stops[i].Position >= booking.FromStop && trip.Stops[i].Position < booking.ToStop {
+			trip.Stops[i].BookedSeats += delta
+		}
+	}
+
+	available, err := tripRepo.SyncAvailableSeats(trip.ID)
+	if err != nil {
+		return err
+	}
+
+	trip.AvailableSeats = available
+	return nil
+}
+
+func (t bookingTransition) allows(actor bookingActor) bool {
+	if actor == actorAdmin {
+		return true
+	}
+	for _, a := range t.actors {
+		if a == actor {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *transitionResult) notify(userID uint, booking *models.Booking, event, message string) {
+	bookingID := booking.ID
+	tripID := booking.TripID
+
+	r.notifications = append(r.notifications, Notification{
+		UserID:    userID,
+		Event:     event,
+		Message:   message,
+		TripID:    &tripID,
+		BookingID: &bookingID,
+	})
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+)
+
+func TestBookingTransitions(t *testing.T) {
+	cases := []struct {
+		from  constants.BookingStatus
+		to    constants.BookingStatus
+		actor bookingActor
+		want  bool
+	}{
+		{from: constants.BookingPending, to: constants.BookingApproved, actor: actorDriver, want: true},
+		{from: constants.BookingPending, to: constants.BookingApproved, actor: actorSystem, want: true},
+		{from: constants.BookingPending, to: constants.BookingApproved, actor: actorPassenger, want: false},
+		{from: constants.BookingPending, to: constants.BookingRejected, actor: actorDriver, want: true},
+		{from: constants.BookingPending, to: constants.BookingRejected, actor: actorPassenger, want: false},
+		{from: constants.BookingPending, to: constants.BookingCancelledByPassenger, actor: actorPassenger, want: true},
+		{from: constants.BookingPending, to: constants.BookingCancelledByPassenger, actor: actorDriver, want: false},
+		{from: constants.BookingPending, to: constants.BookingExpired, actor: actorSystem, want: true},
+		{from: constants.BookingPending, to: constants.BookingExpired, actor: actorDriver, want: false},
+		{from: constants.BookingApproved, to: constants.BookingCancelledByPassenger, actor: actorPassenger, want: true},
+		{from: constants.BookingApproved, to: constants.BookingCancelledByDriver, actor: actorDriver, want: true},
+		{from: constants.BookingApproved, to: constants.BookingRejected, actor: actorDriver, want: false},
+		{from: constants.BookingApproved, to: constants.BookingExpired, actor: actorSystem, want: false},
+		{from: constants.BookingRejected, to: constants.BookingApproved, actor: actorDriver, want: false},
+		{from: constants.BookingCancelledByPassenger, to: constants.BookingPending, actor: actorPassenger, want: false},
+		// администратор выполняет любой описанный переход, но не выходит за таблицу
+		{from: constants.BookingPending, to: constants.BookingExpired, actor: actorAdmin, want: true},
+		{from: constants.BookingExpired, to: constants.BookingApproved, actor: actorAdmin, want: false},
+	}
+
+	for _, c := range cases {
+		t.Run(string(c.from)+"->"+string(c.to)+" by "+string(c.actor), func(t *testing.T) {
+			transition, ok := bookingTransitions[c.from][c.to]
+			if got := ok && transition.allows(c.actor); got != c.want {
+				t.Errorf("allowed = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestBookingTransitionRequiresPublishedTrip(t *testing.T) {
+	machine := newBookingStateMachine(nil, nil, nil, nil, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	cases := []struct {
+		name  string
+		to    constants.BookingStatus
+		actor bookingActor
+	}{
+		{name: "driver approves", to: constants.BookingApproved, actor: actorDriver},
+		{name: "passenger cancels", to: constants.BookingCancelledByPassenger, actor: actorPassenger},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			booking := &models.Booking{BookingStatus: constants.BookingPending}
+			trip := &models.Trip{TripStatus: string(constants.TripInProgress)}
+
+			if _, err := machine.Transition(nil, booking, trip, c.to, c.actor); !errors.Is(err, ErrInvalidTransition) {
+				t.Errorf("error = %v, want ErrInvalidTransition", err)
+			}
+			if booking.BookingStatus != constants.BookingPending {
+				t.Errorf("status changed to %s", booking.BookingStatus)
+			}
+		})
+	}
+}
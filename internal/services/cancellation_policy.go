@@ -0,0 +1,37 @@
+package services
+
+import "time"
+
+// CancellationPolicy решает, какую часть оплаченной суммы вернуть пассажиру при отмене
+type CancellationPolicy interface {
+	Refund(paid int, startTime, now time.Time) int
+}
+
+// tieredCancellationPolicy: полный возврат задолго до отправления,
+// частичный — ближе к нему, без возврата — в последний момент
+type tieredCancellationPolicy struct {
+	fullRefundBefore    time.Duration
+	partialRefundBefore time.Duration
+	partialPercent      int
+}
+
+func NewCancellationPolicy(fullRefundBefore, partialRefundBefore time.Duration, partialPercent int) CancellationPolicy {
+	return &tieredCancellationPolicy{
+		fullRefundBefore:    fullRefundBefore,
+		partialRefundBefore: partialRefundBefore,
+		partialPercent:      partialPercent,
+	}
+}
+
+func (p *tieredCancellationPolicy) Refund(paid int, startTime, now time.Time) int {
+	left := startTime.Sub(now)
+
+	switch {
+	case left >= p.fullRefundBefore:
+		return paid
+	case left >= p.partialRefundBefore:
+		return paid * p.partialPercent / 100
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCancellationRefund(t *testing.T) {
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name    string
+		percent int
+		left    time.Duration
+		want    int
+	}{
+		{name: "well before departure", percent: 50, left: 48 * time.Hour, want: 1000},
+		{name: "full refund boundary", percent: 50, left: 24 * time.Hour, want: 1000},
+		{name: "partial refund", percent: 50, left: 10 * time.Hour, want: 500},
+		{name: "partial refund boundary", percent: 50, left: 2 * time.Hour, want: 500},
+		{name: "last moment", percent: 50, left: time.Hour, want: 0},
+		{name: "after departure", percent: 50, left: -time.Hour, want: 0},
+		{name: "zero partial percent", percent: 0, left: 10 * time.Hour, want: 0},
+		{name: "full partial percent", percent: 100, left: 10 * time.Hour, want: 1000},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			policy := NewCancellationPolicy(24*time.Hour, 2*time.Hour, c.percent)
+			if got := policy.Refund(1000, now.Add(c.left), now); got != c.want {
+				t.Errorf("Refund = %d, want %d", got, c.want)
+			}
+		})
+	}
+}
@@ -1,39 +1,92 @@
 package services
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
+	"strings"
+	"time"
 
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
 	"github.com/mutsaevz/team-5-ambitious/internal/dto"
 
 	"github.com/mutsaevz/team-5-ambitious/internal/models"
 	"github.com/mutsaevz/team-5-ambitious/internal/repository"
 )
 
+var (
+	ErrCarLimitReached = errors.New("car limit per driver reached")
+	ErrCarRequired     = errors.New("car_id is required: driver has several cars")
+	ErrInvalidCar      = errors.New("car does not belong to the driver")
+	ErrInvalidPlate    = errors.New("invalid license plate")
+	ErrPlateTaken      = errors.New("license plate is already registered")
+	ErrInvalidCarYear  = errors.New("invalid car year")
+	ErrCarSeatsInUse   = errors.New("car has active trips with more seats")
+
+	ErrCarPhotoLimit    = errors.New("car photo limit reached")
+	ErrCarPhotoTooLarge = errors.New("car photo is too large")
+	ErrUnsupportedPhoto = errors.New("photo must be a JPEG, PNG or WebP image")
+)
+
+// форматы фотографий и расширения файлов в хранилище
+var carPhotoTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
 type CarService interface {
 	Create(id uint, req dto.CarCreateRequest) (*models.Car, error)
 
-	List(filter models.Page) ([]models.Car, error)
+	List(filter models.Page) (*dto.Page[models.Car], error)
 
-	GetByOwner(id uint) (*models.Car, error)
+	ListByOwner(ownerID uint) ([]models.Car, error)
 
 	GetByID(id uint) (*models.Car, error)
 
-	Update(id uint, req dto.CarUpdateRequest) (*models.Car, error)
+	Update(actorID, id uint, req dto.CarUpdateRequest) (*models.Car, error)
 
-	Delete(id uint) error
+	Delete(actorID, id uint) error
+
+	// AddPhoto сохраняет фотографию размером size в хранилище и привязывает её к автомобилю
+	AddPhoto(actorID, carID uint, r io.Reader, size int64) (*models.CarPhoto, error)
+
+	DeletePhoto(actorID, carID, photoID uint) error
 }
 
 type carService struct {
 	carRepo  repository.CarRepository
 	userRepo repository.UserRepository
-	logger   *slog.Logger
+	policy   Policy
+	blobs    BlobStore
+	maxCars  int
+	// ограничения на фотографии: размер одной в байтах и количество на автомобиль
+	photoMaxSize int64
+	maxPhotos    int
+	logger       *slog.Logger
 }
 
-func NewCarService(carRepo repository.CarRepository, userRepo repository.UserRepository, logger *slog.Logger) CarService {
+func NewCarService(
+	carRepo repository.CarRepository,
+	userRepo repository.UserRepository,
+	policy Policy,
+	blobs BlobStore,
+	maxCars int,
+	photoMaxSize int64,
+	maxPhotos int,
+	logger *slog.Logger,
+) CarService {
 	return &carService{
-		carRepo:  carRepo,
-		userRepo: userRepo,
-		logger:   logger,
+		carRepo:      carRepo,
+		userRepo:     userRepo,
+		policy:       policy,
+		blobs:        blobs,
+		maxCars:      maxCars,
+		photoMaxSize: photoMaxSize,
+		maxPhotos:    maxPhotos,
+		logger:       logger,
 	}
 }
 
@@ -49,23 +102,62 @@ func (s *carService) Create(id uint, req dto.CarCreateRequest) (*models.Car, err
 		Brand:    req.Brand,
 		CarModel: req.CarModel,
 		Seats:    req.Seats,
+		Color:    strings.TrimSpace(req.Color),
+		Year:     req.Year,
+	}
+	applyAmenities(&car.Amenities, req.Amenities)
+
+	if err := s.policy.Authorize(id, ActionCreate, &car); err != nil {
+		return nil, err
+	}
+
+	if err := s.setPlate(&car, req.Plate); err != nil {
+		return nil, err
+	}
+	if err := validateCarYear(car.Year); err != nil {
+		return nil, err
+	}
+
+	cars, err := s.carRepo.ListByOwner(driver.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(cars) >= s.maxCars {
+		s.logger.Warn("Достигнут лимит автомобилей", slog.Uint64("user_id", uint64(driver.ID)), slog.Int("limit", s.maxCars))
+		return nil, ErrCarLimitReached
 	}
 
 	if err := s.carRepo.Create(&car); err != nil {
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			return nil, ErrPlateTaken
+		}
 		return nil, err
 	}
 
+	// владелец автомобиля становится водителем
+	if driver.Role == constants.RolePassenger {
+		driver.Role = constants.RoleDriver
+		if err := s.userRepo.Update(driver.ID, driver); err != nil {
+			s.logger.Error("Ошибка при назначении роли водителя", slog.Uint64("user_id", uint64(driver.ID)), slog.String("error", err.Error()))
+			return nil, err
+		}
+	}
+
 	return &car, nil
 }
 
-func (s *carService) GetByOwner(id uint) (*models.Car, error) {
-	car, err := s.carRepo.GetByOwner(id)
+func (s *carService) ListByOwner(ownerID uint) ([]models.Car, error) {
+	cars, err := s.carRepo.ListByOwner(ownerID)
 	if err != nil {
-		s.logger.Error("Ошибка при получении автомобиля по владельцу", slog.Uint64("owner_id", uint64(id)), slog.String("error", err.Error()))
+		s.logger.Error("Ошибка при получении автомобилей по владельцу", slog.Uint64("owner_id", uint64(ownerID)), slog.String("error", err.Error()))
 		return nil, err
 	}
 
-	return car, nil
+	for i := range cars {
+		s.fillPhotoURLs(&cars[i])
+	}
+
+	return cars, nil
 }
 
 func (s *carService) GetByID(id uint) (*models.Car, error) {
@@ -74,25 +166,35 @@ func (s *carService) GetByID(id uint) (*models.Car, error) {
 		s.logger.Error("Ошибка при получении автомобиля по ID", slog.Uint64("car_id", uint64(id)), slog.String("error", err.Error()))
 		return nil, err
 	}
+
+	s.fillPhotoURLs(car)
 	return car, nil
 }
 
-func (s *carService) List(filter models.Page) ([]models.Car, error) {
+func (s *carService) List(filter models.Page) (*dto.Page[models.Car], error) {
 	cars, err := s.carRepo.List(filter)
 	if err != nil {
 		s.logger.Error("Ошибка при получении списка автомобилей", slog.String("error", err.Error()))
 		return nil, err
 	}
+
+	for i := range cars.Items {
+		s.fillPhotoURLs(&cars.Items[i])
+	}
 	return cars, nil
 }
 
-func (s *carService) Update(id uint, req dto.CarUpdateRequest) (*models.Car, error) {
+func (s *carService) Update(actorID, id uint, req dto.CarUpdateRequest) (*models.Car, error) {
 	car, err := s.carRepo.GetByID(id)
 	if err != nil {
 		s.logger.Error("Автомобиль не найден для обновления", slog.Uint64("car_id", uint64(id)), slog.String("error", err.Error()))
 		return nil, err
 	}
 
+	if err := s.policy.Authorize(actorID, ActionUpdate, car); err != nil {
+		return nil, err
+	}
+
 	if req.Brand != nil {
 		car.Brand = *req.Brand
 	}
@@ -100,25 +202,56 @@ func (s *carService) Update(id uint, req dto.CarUpdateRequest) (*models.Car, err
 		car.CarModel = *req.CarModel
 	}
 	if req.Seats != nil {
+		// мест не может стать меньше, чем уже продаётся в поездках на этом автомобиле
+		inUse, err := s.carRepo.MaxActiveTripSeats(car.ID)
+		if err != nil {
+			return nil, err
+		}
+		if *req.Seats < inUse {
+			return nil, ErrCarSeatsInUse
+		}
 		car.Seats = *req.Seats
 	}
+	if req.Plate != nil {
+		if err := s.setPlate(car, *req.Plate); err != nil {
+			return nil, err
+		}
+	}
+	if req.Color != nil {
+		car.Color = strings.TrimSpace(*req.Color)
+	}
+	if req.Year != nil {
+		if err := validateCarYear(*req.Year); err != nil {
+			return nil, err
+		}
+		car.Year = *req.Year
+	}
+	applyAmenities(&car.Amenities, req.Amenities)
 
 	updatedCar, err := s.carRepo.Update(car)
 	if err != nil {
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			return nil, ErrPlateTaken
+		}
 		s.logger.Error("Ошибка при обновлении автомобиля", slog.Uint64("car_id", uint64(id)), slog.String("error", err.Error()))
 		return nil, err
 	}
 
+	s.fillPhotoURLs(updatedCar)
 	return updatedCar, nil
 }
 
-func (s *carService) Delete(id uint) error {
-	_, err := s.carRepo.GetByID(id)
+func (s *carService) Delete(actorID, id uint) error {
+	car, err := s.carRepo.GetByID(id)
 	if err != nil {
 		s.logger.Error("Автомобиль не найден для удаления", slog.Uint64("car_id", uint64(id)), slog.String("error", err.Error()))
 		return err
 	}
 
+	if err := s.policy.Authorize(actorID, ActionDelete, car); err != nil {
+		return err
+	}
+
 	if err := s.carRepo.Delete(id); err != nil {
 		s.logger.Error("Ошибка при удалении автомобиля", slog.Uint64("car_id", uint64(id)), slog.String("error", err.Error()))
 		return err
@@ -127,3 +260,208 @@ func (s *carService) Delete(id uint) error {
 	s.logger.Info("Автомобиль успешно удалён", slog.Uint64("car_id", uint64(id)))
 	return nil
 }
+
+func (s *carService) AddPhoto(actorID, carID uint, r io.Reader, size int64) (*models.CarPhoto, error) {
+	car, err := s.carRepo.GetByID(carID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.policy.Authorize(actorID, ActionUpdate, car); err != nil {
+		return nil, err
+	}
+
+	if size > s.photoMaxSize {
+		return nil, ErrCarPhotoTooLarge
+	}
+
+	count, err := s.carRepo.CountPhotos(carID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= int64(s.maxPhotos) {
+		return nil, ErrCarPhotoLimit
+	}
+
+	// формат определяется по содержимому, а не по заголовку клиента
+	body, contentType, err := sniffContentType(r)
+	if err != nil {
+		return nil, err
+	}
+	ext, ok := carPhotoTypes[contentType]
+	if !ok {
+		return nil, ErrUnsupportedPhoto
+	}
+
+	name, err := newBlobName()
+	if err != nil {
+		return nil, err
+	}
+
+	photo := &models.CarPhoto{
+		CarID:       carID,
+		Key:         fmt.Sprintf("%d/%s%s", carID, name, ext),
+		ContentType: contentType,
+		Size:        size,
+	}
+
+	// клиент мог занизить размер — файл больше допустимого не сохраняется
+	data, err := readLimited(body, s.photoMaxSize, ErrCarPhotoTooLarge)
+	if err != nil {
+		return nil, err
+	}
+	photo.Size = int64(len(data))
+
+	if err := s.blobs.Put(photo.Key, bytes.NewReader(data)); err != nil {
+		s.logger.Error("Ошибка при сохранении фотографии", slog.Uint64("car_id", uint64(carID)), slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	if err := s.carRepo.CreatePhoto(photo); err != nil {
+		if err := s.blobs.Delete(photo.Key); err != nil {
+			s.logger.Warn("Не удалось удалить файл фотографии", slog.String("key", photo.Key), slog.String("error", err.Error()))
+		}
+		return nil, err
+	}
+
+	photo.URL = s.blobs.URL(photo.Key)
+	return photo, nil
+}
+
+func (s *carService) DeletePhoto(actorID, carID, photoID uint) error {
+	car, err := s.carRepo.GetByID(carID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.policy.Authorize(actorID, ActionUpdate, car); err != nil {
+		return err
+	}
+
+	photo, err := s.carRepo.GetPhoto(carID, photoID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.carRepo.DeletePhoto(photo); err != nil {
+		return err
+	}
+
+	// запись уже удалена: оставшийся файл никому не виден, ошибку только логируем
+	if err := s.blobs.Delete(photo.Key); err != nil {
+		s.logger.Warn("Не удалось удалить файл фотографии", slog.String("key", photo.Key), slog.String("error", err.Error()))
+	}
+
+	return nil
+}
+
+// setPlate нормализует номер и проверяет, что он не закреплён за другим автомобилем
+func (s *carService) setPlate(car *models.Car, raw string) error {
+	plate, err := normalizePlate(raw)
+	if err != nil {
+		return err
+	}
+
+	other, err := s.carRepo.GetByPlate(plate)
+	if err == nil && other.ID != car.ID {
+		return ErrPlateTaken
+	}
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+
+	// другой номер — другое свидетельство о регистрации, проверку нужно пройти заново
+	if car.Plate == nil || *car.Plate != plate {
+		car.Verified = false
+	}
+
+	car.Plate = &plate
+	return nil
+}
+
+func (s *carService) fillPhotoURLs(car *models.Car) {
+	for i := range car.Photos {
+		car.Photos[i].URL = s.blobs.URL(car.Photos[i].Key)
+	}
+}
+
+// кириллические буквы российских номеров, совпадающие по начертанию с латинскими
+var plateLetters = strings.NewReplacer(
+	"А", "A", "В", "B", "Е", "E", "К", "K", "М", "M", "Н", "H",
+	"О", "O", "Р", "P", "С", "C", "Т", "T", "У", "Y", "Х", "X",
+)
+
+// normalizePlate приводит номер к одному виду: «а 123 вс-95» и «A123BC95» — один и тот же номер
+func normalizePlate(raw string) (string, error) {
+	plate := plateLetters.Replace(strings.ToUpper(raw))
+	plate = strings.NewReplacer(" ", "", "-", "").Replace(plate)
+
+	if len(plate) < 4 || len(plate) > 12 {
+		return "", ErrInvalidPlate
+	}
+	for _, c := range plate {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return "", ErrInvalidPlate
+		}
+	}
+
+	return plate, nil
+}
+
+func validateCarYear(year int) error {
+	// год необязателен; будущий модельный год допускается
+	if year != 0 && (year < 1950 || year > time.Now().Year()+1) {
+		return ErrInvalidCarYear
+	}
+	return nil
+}
+
+func applyAmenities(amenities *models.CarAmenities, req dto.CarAmenities) {
+	if req.AirConditioning != nil {
+		amenities.AirConditioning = *req.AirConditioning
+	}
+	if req.LuggageSpace != nil {
+		amenities.LuggageSpace = *req.LuggageSpace
+	}
+	if req.ChildSeat != nil {
+		amenities.ChildSeat = *req.ChildSeat
+	}
+	if req.PetsAllowed != nil {
+		amenities.PetsAllowed = *req.PetsAllowed
+	}
+	if req.SmokingAllowed != nil {
+		amenities.SmokingAllowed = *req.SmokingAllowed
+	}
+}
+
+// driverCar — автомобиль, на котором водитель создаёт поездку или шаблон.
+// Без явного carID подходит только единственный автомобиль водителя
+func driverCar(carRepo repository.CarRepository, driverID, carID uint) (*models.Car, error) {
+	if carID != 0 {
+		car, err := carRepo.GetByID(carID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil, ErrInvalidCar
+			}
+			return nil, err
+		}
+		if car.OwnerID != driverID {
+			return nil, ErrInvalidCar
+		}
+		return car, nil
+	}
+
+	cars, err := carRepo.ListByOwner(driverID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(cars) {
+	case 0:
+		return nil, ErrInvalidCar
+	case 1:
+		return &cars[0], nil
+	default:
+		return nil, ErrCarRequired
+	}
+}
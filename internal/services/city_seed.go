@@ -0,0 +1,37 @@
+package services
+
+import "github.com/mutsaevz/team-5-ambitious/internal/dto"
+
+// DefaultCities — начальный справочник: города, между которыми чаще всего ездят.
+// Остальные добавляет администратор через /cities
+var DefaultCities = []dto.CityCreateRequest{
+	{Name: "Грозный", Region: "Чеченская Республика"},
+	{Name: "Гудермес", Region: "Чеченская Республика"},
+	{Name: "Аргун", Region: "Чеченская Республика"},
+	{Name: "Шали", Region: "Чеченская Республика"},
+	{Name: "Урус-Мартан", Region: "Чеченская Республика"},
+	{Name: "Курчалой", Region: "Чеченская Республика"},
+	{Name: "Ачхой-Мартан", Region: "Чеченская Республика"},
+	{Name: "Шелковская", Region: "Чеченская Республика", Aliases: []string{"Шелковская станица"}},
+	{Name: "Наурская", Region: "Чеченская Республика"},
+	{Name: "Ведено", Region: "Чеченская Республика"},
+	{Name: "Назрань", Region: "Республика Ингушетия"},
+	{Name: "Магас", Region: "Республика Ингушетия"},
+	{Name: "Махачкала", Region: "Республика Дагестан", Aliases: []string{"Махачкала-1"}},
+	{Name: "Хасавюрт", Region: "Республика Дагестан"},
+	{Name: "Кизляр", Region: "Республика Дагестан"},
+	{Name: "Дербент", Region: "Республика Дагестан"},
+	{Name: "Владикавказ", Region: "Республика Северная Осетия — Алания", Aliases: []string{"Vladikavkaz", "Орджоникидзе"}},
+	{Name: "Моздок", Region: "Республика Северная Осетия — Алания"},
+	{Name: "Нальчик", Region: "Кабардино-Балкарская Республика"},
+	{Name: "Пятигорск", Region: "Ставропольский край"},
+	{Name: "Минеральные Воды", Region: "Ставропольский край", Aliases: []string{"Минводы", "Mineralnye Vody"}},
+	{Name: "Кисловодск", Region: "Ставропольский край"},
+	{Name: "Ставрополь", Region: "Ставропольский край"},
+	{Name: "Ростов-на-Дону", Region: "Ростовская область", Aliases: []string{"Ростов", "Rostov-on-Don"}},
+	{Name: "Краснодар", Region: "Краснодарский край"},
+	{Name: "Волгоград", Region: "Волгоградская область"},
+	{Name: "Астрахань", Region: "Астраханская область"},
+	{Name: "Москва", Region: "Москва", Aliases: []string{"Moscow", "Мск"}},
+	{Name: "Санкт-Петербург", Region: "Санкт-Петербург", Aliases: []string{"Петербург", "СПб", "Saint Petersburg", "St. Petersburg"}},
+}
@@ -0,0 +1,410 @@
+package services
+
+import (
+	"errors"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+)
+
+var ErrUnknownCity = errors.New("unknown city")
+
+// cityCacheTTL — как долго справочник в памяти считается свежим; изменения
+// на этом экземпляре сбрасывают его сразу, на остальных — по истечении срока
+const cityCacheTTL = 5 * time.Minute
+
+type CityService interface {
+	// Resolve находит город по названию без учёта регистра, пробелов, транслитерации и опечаток
+	Resolve(name string) (*models.City, error)
+
+	// ResolveExact находит город только по точному совпадению названия или синонима
+	ResolveExact(name string) (*models.City, error)
+
+	// Suggest — подсказки для ввода: сначала города, названия которых начинаются с query
+	Suggest(query string, limit int) ([]models.City, error)
+
+	GetByID(id uint) (*models.City, error)
+
+	Create(actorID uint, req *dto.CityCreateRequest) (*models.City, error)
+
+	AddAlias(actorID, id uint, name string) (*models.City, error)
+
+	// Seed наполняет пустой справочник и связывает с ним поездки, сохранённые только с названиями
+	Seed(defaults []dto.CityCreateRequest) error
+}
+
+// cityEntry — одно название города в поисковом индексе
+type cityEntry struct {
+	key       string
+	city      *models.City
+	canonical bool
+}
+
+type cityService struct {
+	cityRepo repository.CityRepository
+	policy   Policy
+	logger   *slog.Logger
+
+	mu       sync.RWMutex
+	index    []cityEntry
+	loadedAt time.Time
+}
+
+func NewCityService(cityRepo repository.CityRepository, policy Policy, logger *slog.Logger) CityService {
+	return &cityService{
+		cityRepo: cityRepo,
+		policy:   policy,
+		logger:   logger,
+	}
+}
+
+func (s *cityService) Resolve(name string) (*models.City, error) {
+	return s.resolve(name, true)
+}
+
+func (s *cityService) ResolveExact(name string) (*models.City, error) {
+	return s.resolve(name, false)
+}
+
+// resolve ищет точное совпадение ключа, а при fuzzy — ближайшее название
+// в пределах допустимого числа опечаток. При равенстве побеждает город, добавленный раньше
+func (s *cityService) resolve(name string, fuzzy bool) (*models.City, error) {
+	key := cityKey(name)
+	if key == "" {
+		return nil, ErrUnknownCity
+	}
+
+	index, err := s.entries()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		best     *models.City
+		bestDist = typoBudget(key) + 1
+	)
+
+	for _, e := range index {
+		if e.key == key {
+			return e.city, nil
+		}
+		if !fuzzy {
+			continue
+		}
+		if d := levenshtein(key, e.key); d < bestDist {
+			best, bestDist = e.city, d
+		}
+	}
+
+	if best == nil {
+		return nil, ErrUnknownCity
+	}
+
+	return best, nil
+}
+
+func (s *cityService) Suggest(query string, limit int) ([]models.City, error) {
+	key := cityKey(query)
+	if key == "" {
+		return []models.City{}, nil
+	}
+
+	index, err := s.entries()
+	if err != nil {
+		return nil, err
+	}
+
+	type match struct {
+		city *models.City
+		rank int // 0 — префикс канонического названия, 1 — префикс синонима, 2+ — с опечатками
+	}
+
+	matches := make(map[uint]match)
+	budget := typoBudget(key)
+
+	for _, e := range index {
+		rank := -1
+
+		switch {
+		case strings.HasPrefix(e.key, key):
+			rank = 1
+			if e.canonical {
+				rank = 0
+			}
+		default:
+			// опечатка в уже введённой части названия
+			prefix := e.key
+			if len(prefix) > len(key) {
+				prefix = prefix[:len(key)]
+			}
+			if d := levenshtein(key, prefix); d <= budget {
+				rank = 1 + d
+			}
+		}
+
+		if rank < 0 {
+			continue
+		}
+		if m, ok := matches[e.city.ID]; !ok || rank < m.rank {
+			matches[e.city.ID] = match{city: e.city, rank: rank}
+		}
+	}
+
+	ranked := make([]match, 0, len(matches))
+	for _, m := range matches {
+		ranked = append(ranked, m)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].rank != ranked[j].rank {
+			return ranked[i].rank < ranked[j].rank
+		}
+		return ranked[i].city.ID < ranked[j].city.ID
+	})
+
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	cities := make([]models.City, 0, len(ranked))
+	for _, m := range ranked {
+		cities = append(cities, *m.city)
+	}
+
+	return cities, nil
+}
+
+func (s *cityService) GetByID(id uint) (*models.City, error) {
+	return s.cityRepo.GetByID(id)
+}
+
+func (s *cityService) Create(actorID uint, req *dto.CityCreateRequest) (*models.City, error) {
+	op := "service.city.Create"
+
+	s.logger.Debug(" call", slog.String("op", op), slog.String("name", req.Name))
+
+	city := newCity(req)
+
+	if err := s.policy.Authorize(actorID, ActionCreate, city); err != nil {
+		return nil, err
+	}
+
+	if err := s.cityRepo.Create(city); err != nil {
+		s.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	s.invalidate()
+	return city, nil
+}
+
+func (s *cityService) AddAlias(actorID, id uint, name string) (*models.City, error) {
+	op := "service.city.AddAlias"
+
+	s.logger.Debug(" call", slog.String("op", op), slog.Uint64("city_id", uint64(id)))
+
+	city, err := s.cityRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.policy.Authorize(actorID, ActionUpdate, city); err != nil {
+		return nil, err
+	}
+
+	alias := models.CityAlias{CityID: city.ID, Name: strings.TrimSpace(name)}
+	if err := s.cityRepo.CreateAlias(&alias); err != nil {
+		s.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	city.Aliases = append(city.Aliases, alias)
+
+	s.invalidate()
+	return city, nil
+}
+
+func (s *cityService) Seed(defaults []dto.CityCreateRequest) error {
+	count, err := s.cityRepo.Count()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		for i := range defaults {
+			if err := s.cityRepo.Create(newCity(&defaults[i])); err != nil {
+				return err
+			}
+		}
+		s.invalidate()
+		s.logger.Info("city directory seeded", slog.Int("count", len(defaults)))
+	}
+
+	// названия из поездок связываются только при точном совпадении: опечатку
+	// в старых данных безопаснее оставить как есть, чем привязать к чужому городу
+	names, err := s.cityRepo.UnresolvedNames()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		city, err := s.resolve(name, false)
+		if errors.Is(err, ErrUnknownCity) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.cityRepo.AssignCity(name, city.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// entries возвращает поисковый индекс, перечитывая справочник, если он устарел
+func (s *cityService) entries() ([]cityEntry, error) {
+	s.mu.RLock()
+	index, fresh := s.index, time.Since(s.loadedAt) < cityCacheTTL
+	s.mu.RUnlock()
+
+	if index != nil && fresh {
+		return index, nil
+	}
+
+	cities, err := s.cityRepo.ListAll()
+	if err != nil {
+		return nil, err
+	}
+
+	index = make([]cityEntry, 0, len(cities)*2)
+	for i := range cities {
+		city := &cities[i]
+		index = append(index, cityEntry{key: cityKey(city.Name), city: city, canonical: true})
+		for _, alias := range city.Aliases {
+			index = append(index, cityEntry{key: cityKey(alias.Name), city: city})
+		}
+	}
+
+	s.mu.Lock()
+	s.index, s.loadedAt = index, time.Now()
+	s.mu.Unlock()
+
+	return index, nil
+}
+
+func (s *cityService) invalidate() {
+	s.mu.Lock()
+	s.index = nil
+	s.mu.Unlock()
+}
+
+func newCity(req *dto.CityCreateRequest) *models.City {
+	city := &models.City{
+		Name:   strings.TrimSpace(req.Name),
+		Region: strings.TrimSpace(req.Region),
+	}
+	for _, name := range req.Aliases {
+		city.Aliases = append(city.Aliases, models.CityAlias{Name: strings.TrimSpace(name)})
+	}
+	return city
+}
+
+// resolveCity заменяет название города каноническим из справочника и возвращает ID города;
+// name == nil — город не меняется. При записи годится только точное совпадение: опечатку
+// безопаснее сохранить как есть, без ID, чем привязать поездку к чужому городу
+func resolveCity(cities CityService, name *string) (*uint, error) {
+	if name == nil {
+		return nil, nil
+	}
+
+	city, err := cities.ResolveExact(*name)
+	if errors.Is(err, ErrUnknownCity) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	id := city.ID
+	*name = city.Name
+	return &id, nil
+}
+
+// cyrillicToLatin — упрощённая транслитерация: разные системы латиницы
+// дополнительно сводятся к одной в cityKey
+var cyrillicToLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "h", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "sch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+}
+
+// сочетания, которыми разные системы транслитерации передают одни и те же звуки
+var latinVariants = strings.NewReplacer(
+	"kh", "h",
+	"yj", "y", "ij", "y", "iy", "y", "yy", "y",
+	"shch", "sch",
+	"tz", "ts",
+)
+
+// cityKey — ключ сравнения названий: латиница в нижнем регистре без пробелов и знаков,
+// так что «Урус-Мартан», «урус мартан» и «Urus-Martan» дают один ключ
+func cityKey(name string) string {
+	var b strings.Builder
+
+	for _, r := range strings.ToLower(name) {
+		if latin, ok := cyrillicToLatin[r]; ok {
+			b.WriteString(latin)
+			continue
+		}
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+		}
+	}
+
+	return latinVariants.Replace(b.String())
+}
+
+// typoBudget — сколько опечаток допускается в ключе такой длины
+func typoBudget(key string) int {
+	switch {
+	case len(key) <= 3:
+		return 0
+	case len(key) <= 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
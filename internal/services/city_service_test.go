@@ -0,0 +1,69 @@
+package services
+
+import "testing"
+
+func TestCityKey(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "cyrillic with hyphen", in: "Урус-Мартан", want: "urusmartan"},
+		{name: "cyrillic with space", in: "урус мартан", want: "urusmartan"},
+		{name: "latin", in: "Urus-Martan", want: "urusmartan"},
+		{name: "double y ending", in: "Грозный", want: "grozny"},
+		{name: "yj transliteration", in: "Groznyj", want: "grozny"},
+		{name: "kh transliteration", in: "Khasavyurt", want: "hasavyurt"},
+		{name: "h from cyrillic", in: "Хасавюрт", want: "hasavyurt"},
+		{name: "soft sign dropped", in: "Тверь", want: "tver"},
+		{name: "empty", in: "", want: ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := cityKey(c.in); got != c.want {
+				t.Errorf("cityKey(%q) = %q, want %q", c.in, got, c.want)
+			}
+		})
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{a: "", b: "", want: 0},
+		{a: "abc", b: "", want: 3},
+		{a: "", b: "abc", want: 3},
+		{a: "grozny", b: "grozny", want: 0},
+		{a: "grozny", b: "grozni", want: 1},
+		{a: "grozny", b: "grzny", want: 1},
+		{a: "kitten", b: "sitting", want: 3},
+		{a: "flaw", b: "lawn", want: 2},
+	}
+
+	for _, c := range cases {
+		if got := levenshtein(c.a, c.b); got != c.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestTypoBudget(t *testing.T) {
+	cases := []struct {
+		key  string
+		want int
+	}{
+		{key: "uua", want: 0},
+		{key: "tver", want: 1},
+		{key: "grozny", want: 1},
+		{key: "urusmartan", want: 2},
+	}
+
+	for _, c := range cases {
+		if got := typoBudget(c.key); got != c.want {
+			t.Errorf("typoBudget(%q) = %d, want %d", c.key, got, c.want)
+		}
+	}
+}
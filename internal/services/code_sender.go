@@ -0,0 +1,25 @@
+package services
+
+import "log/slog"
+
+// CodeSender доставляет одноразовый код пользователю (SMS, мессенджер и т.д.)
+type CodeSender interface {
+	Send(phone, code string) error
+}
+
+// LogCodeSender — локальная заглушка: пишет код в лог вместо отправки
+type LogCodeSender struct {
+	logger *slog.Logger
+}
+
+func NewLogCodeSender(logger *slog.Logger) *LogCodeSender {
+	return &LogCodeSender{logger: logger}
+}
+
+func (s *LogCodeSender) Send(phone, code string) error {
+	s.logger.Info("auth code issued",
+		slog.String("phone", phone),
+		slog.String("code", code),
+	)
+	return nil
+}
@@ -0,0 +1,325 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// LedgerService проводит денежные операции двойной записью и синхронно
+// обновляет users.balance. Внутри транзакции используйте WithDB(tx).
+type LedgerService interface {
+	Hold(booking *models.Booking, amount int) error
+
+	Release(booking *models.Booking, amount int) error
+
+	Settle(booking *models.Booking, driverID uint, amount int) error
+
+	Commission(booking *models.Booking, amount int) error
+
+	Subsidize(booking *models.Booking, amount int) error
+
+	Bonus(userID uint, booking *models.Booking, amount int) error
+
+	HeldAmount(bookingID uint) (int, error)
+
+	TopUp(intent *models.PaymentIntent) error
+
+	ReservePayout(intent *models.PaymentIntent) error
+
+	CompletePayout(intent *models.PaymentIntent) error
+
+	ReleasePayout(intent *models.PaymentIntent) error
+
+	History(userID uint, filter models.Page) (*dto.Page[models.LedgerEntry], error)
+
+	WithDB(db *gorm.DB) LedgerService
+}
+
+type ledgerService struct {
+	ledgerRepo repository.LedgerRepository
+	userRepo   repository.UserRepository
+	logger     *slog.Logger
+}
+
+func NewLedgerService(
+	ledgerRepo repository.LedgerRepository,
+	userRepo repository.UserRepository,
+	logger *slog.Logger,
+) LedgerService {
+	return &ledgerService{
+		ledgerRepo: ledgerRepo,
+		userRepo:   userRepo,
+		logger:     logger,
+	}
+}
+
+// Hold переводит сумму с кошелька пассажира на счёт блокировки под бронь
+func (s *ledgerService) Hold(booking *models.Booking, amount int) error {
+	if amount < 0 {
+		return ErrInvalidAmount
+	}
+	if amount == 0 {
+		return nil
+	}
+
+	if err := s.userRepo.AdjustBalance(booking.PassengerID, -amount); err != nil {
+		return err
+	}
+
+	return s.post(constants.LedgerBookingHold, entryLink{bookingID: &booking.ID},
+		posting{userID: &booking.PassengerID, account: constants.AccountWallet, amount: -amount},
+		posting{userID: &booking.PassengerID, account: constants.AccountHold, amount: amount},
+	)
+}
+
+// Release возвращает заблокированную сумму (или её часть) на кошелёк пассажира
+func (s *ledgerService) Release(booking *models.Booking, amount int) error {
+	if amount == 0 {
+		return nil
+	}
+
+	if err := s.checkHeld(booking.ID, amount); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.AdjustBalance(booking.PassengerID, amount); err != nil {
+		return err
+	}
+
+	return s.post(constants.LedgerBookingRelease, entryLink{bookingID: &booking.ID},
+		posting{userID: &booking.PassengerID, account: constants.AccountHold, amount: -amount},
+		posting{userID: &booking.PassengerID, account: constants.AccountWallet, amount: amount},
+	)
+}
+
+// Settle переводит заблокированную сумму водителю
+func (s *ledgerService) Settle(booking *models.Booking, driverID uint, amount int) error {
+	if amount == 0 {
+		return nil
+	}
+
+	if err := s.checkHeld(booking.ID, amount); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.AdjustBalance(driverID, amount); err != nil {
+		return err
+	}
+
+	return s.post(constants.LedgerBookingSettle, entryLink{bookingID: &booking.ID},
+		posting{userID: &booking.PassengerID, account: constants.AccountHold, amount: -amount},
+		posting{userID: &driverID, account: constants.AccountWallet, amount: amount},
+	)
+}
+
+// Commission переводит долю платформы из заблокированной суммы на счёт платформы
+func (s *ledgerService) Commission(booking *models.Booking, amount int) error {
+	if amount == 0 {
+		return nil
+	}
+
+	if err := s.checkHeld(booking.ID, amount); err != nil {
+		return err
+	}
+
+	return s.post(constants.LedgerCommission, entryLink{bookingID: &booking.ID},
+		posting{userID: &booking.PassengerID, account: constants.AccountHold, amount: -amount},
+		posting{account: constants.AccountPlatform, amount: amount},
+	)
+}
+
+// Subsidize докладывает в блокировку брони скидку по промокоду за счёт платформы,
+// чтобы водитель получил полную стоимость
+func (s *ledgerService) Subsidize(booking *models.Booking, amount int) error {
+	if amount < 0 {
+		return ErrInvalidAmount
+	}
+	if amount == 0 {
+		return nil
+	}
+
+	return s.post(constants.LedgerPromoDiscount, entryLink{bookingID: &booking.ID},
+		posting{account: constants.AccountPlatform, amount: -amount},
+		posting{userID: &booking.PassengerID, account: constants.AccountHold, amount: amount},
+	)
+}
+
+// Bonus зачисляет на кошелёк пользователя бонус платформы, связанный с бронью
+func (s *ledgerService) Bonus(userID uint, booking *models.Booking, amount int) error {
+	if amount < 0 {
+		return ErrInvalidAmount
+	}
+	if amount == 0 {
+		return nil
+	}
+
+	if err := s.userRepo.AdjustBalance(userID, amount); err != nil {
+		return err
+	}
+
+	return s.post(constants.LedgerReferralBonus, entryLink{bookingID: &booking.ID},
+		posting{account: constants.AccountPlatform, amount: -amount},
+		posting{userID: &userID, account: constants.AccountWallet, amount: amount},
+	)
+}
+
+func (s *ledgerService) HeldAmount(bookingID uint) (int, error) {
+	return s.ledgerRepo.SumByBooking(bookingID, constants.AccountHold)
+}
+
+// TopUp зачисляет на кошелёк средства, поступившие от платёжного провайдера
+func (s *ledgerService) TopUp(intent *models.PaymentIntent) error {
+	if intent.Amount <= 0 {
+		return ErrInvalidAmount
+	}
+
+	if err := s.userRepo.AdjustBalance(intent.UserID, intent.Amount); err != nil {
+		return err
+	}
+
+	return s.post(constants.LedgerTopUp, entryLink{paymentID: &intent.ID},
+		posting{account: constants.AccountExternal, amount: -intent.Amount},
+		posting{userID: &intent.UserID, account: constants.AccountWallet, amount: intent.Amount},
+	)
+}
+
+// ReservePayout списывает сумму вывода с кошелька до подтверждения провайдером
+func (s *ledgerService) ReservePayout(intent *models.PaymentIntent) error {
+	if intent.Amount <= 0 {
+		return ErrInvalidAmount
+	}
+
+	if err := s.userRepo.AdjustBalance(intent.UserID, -intent.Amount); err != nil {
+		return err
+	}
+
+	return s.post(constants.LedgerPayoutReserve, entryLink{paymentID: &intent.ID},
+		posting{userID: &intent.UserID, account: constants.AccountWallet, amount: -intent.Amount},
+		posting{userID: &intent.UserID, account: constants.AccountPayout, amount: intent.Amount},
+	)
+}
+
+func (s *ledgerService) CompletePayout(intent *models.PaymentIntent) error {
+	return s.post(constants.LedgerPayoutComplete, entryLink{paymentID: &intent.ID},
+		posting{userID: &intent.UserID, account: constants.AccountPayout, amount: -intent.Amount},
+		posting{account: constants.AccountExternal, amount: intent.Amount},
+	)
+}
+
+// ReleasePayout возвращает на кошелёк сумму неудавшегося вывода
+func (s *ledgerService) ReleasePayout(intent *models.PaymentIntent) error {
+	if err := s.userRepo.AdjustBalance(intent.UserID, intent.Amount); err != nil {
+		return err
+	}
+
+	return s.post(constants.LedgerPayoutRelease, entryLink{paymentID: &intent.ID},
+		posting{userID: &intent.UserID, account: constants.AccountPayout, amount: -intent.Amount},
+		posting{userID: &intent.UserID, account: constants.AccountWallet, amount: intent.Amount},
+	)
+}
+
+func (s *ledgerService) History(userID uint, filter models.Page) (*dto.Page[models.LedgerEntry], error) {
+	entries, err := s.ledgerRepo.ListByUser(userID, filter)
+	if err != nil {
+		s.logger.Error("ledger history error",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.Any("error", err),
+		)
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (s *ledgerService) WithDB(db *gorm.DB) LedgerService {
+	return &ledgerService{
+		ledgerRepo: s.ledgerRepo.WithDB(db),
+		userRepo:   s.userRepo.WithDB(db),
+		logger:     s.logger,
+	}
+}
+
+func (s *ledgerService) checkHeld(bookingID uint, amount int) error {
+	if amount < 0 {
+		return ErrInvalidAmount
+	}
+
+	held, err := s.HeldAmount(bookingID)
+	if err != nil {
+		return err
+	}
+
+	if held < amount {
+		return ErrInvalidAmount
+	}
+
+	return nil
+}
+
+type posting struct {
+	userID  *uint
+	account constants.LedgerAccount
+	amount  int
+}
+
+// entryLink связывает проводки с бронью или платежом, породившими операцию
+type entryLink struct {
+	bookingID *uint
+	paymentID *uint
+}
+
+func (s *ledgerService) post(kind constants.LedgerKind, link entryLink, postings ...posting) error {
+	txID, err := newTransactionID()
+	if err != nil {
+		return err
+	}
+
+	entries := make([]models.LedgerEntry, 0, len(postings))
+	total := 0
+
+	for _, p := range postings {
+		total += p.amount
+		entries = append(entries, models.LedgerEntry{
+			TransactionID: txID,
+			UserID:        p.userID,
+			Account:       p.account,
+			Amount:        p.amount,
+			Kind:          kind,
+			BookingID:     link.bookingID,
+			PaymentID:     link.paymentID,
+		})
+	}
+
+	if total != 0 {
+		return errors.New("unbalanced ledger transaction")
+	}
+
+	if err := s.ledgerRepo.CreateEntries(entries); err != nil {
+		return err
+	}
+
+	s.logger.Info("ledger transaction posted",
+		slog.String("transaction_id", txID),
+		slog.String("kind", string(kind)),
+	)
+
+	return nil
+}
+
+func newTransactionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"log/slog"
+	"sync"
+)
+
+type Notification struct {
+	UserID    uint   `json:"user_id"`
+	Event     string `json:"event"`
+	Message   string `json:"message"`
+	TripID    *uint  `json:"trip_id,omitempty"`
+	BookingID *uint  `json:"booking_id,omitempty"`
+	// SavedSearchID — сохранённый поиск, по которому найдена поездка
+	SavedSearchID *uint `json:"saved_search_id,omitempty"`
+}
+
+// Notifier доставляет уведомления пользователям (push, SMS, e-mail и т.д.)
+type Notifier interface {
+	Notify(n Notification) error
+}
+
+// LogNotifier — реализация по умолчанию: пишет уведомления в лог
+type LogNotifier struct {
+	logger *slog.Logger
+}
+
+func NewLogNotifier(logger *slog.Logger) *LogNotifier {
+	return &LogNotifier{logger: logger}
+}
+
+func (n *LogNotifier) Notify(notification Notification) error {
+	n.logger.Info("notification",
+		slog.Uint64("user_id", uint64(notification.UserID)),
+		slog.String("event", notification.Event),
+		slog.String("message", notification.Message),
+	)
+	return nil
+}
+
+// InMemoryNotifier хранит последние уведомления каждого пользователя в памяти процесса;
+// для разработки и проверок, когда настоящей доставки нет
+type InMemoryNotifier struct {
+	mu     sync.Mutex
+	limit  int
+	byUser map[uint][]Notification
+}
+
+func NewInMemoryNotifier(limit int) *InMemoryNotifier {
+	return &InMemoryNotifier{
+		limit:  limit,
+		byUser: make(map[uint][]Notification),
+	}
+}
+
+func (n *InMemoryNotifier) Notify(notification Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	list := append(n.byUser[notification.UserID], notification)
+	if n.limit > 0 && len(list) > n.limit {
+		list = list[len(list)-n.limit:]
+	}
+	n.byUser[notification.UserID] = list
+
+	return nil
+}
+
+// List — уведомления пользователя от старых к новым
+func (n *InMemoryNotifier) List(userID uint) []Notification {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return append([]Notification(nil), n.byUser[userID]...)
+}
+
+// dispatch отправляет уведомления, накопленные за транзакцию, уже после её фиксации
+func dispatch(notifier Notifier, logger *slog.Logger, notifications []Notification) {
+	for _, n := range notifications {
+		if err := notifier.Notify(n); err != nil {
+			logger.Error("failed to send notification",
+				slog.Uint64("user_id", uint64(n.UserID)),
+				slog.String("event", n.Event),
+				slog.Any("error", err),
+			)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+)
+
+var ErrInvalidSignature = errors.New("invalid callback signature")
+
+// PaymentProvider — адаптер внешней платёжной системы
+type PaymentProvider interface {
+	Name() string
+
+	CreateTopUp(intent *models.PaymentIntent) (*ProviderIntent, error)
+
+	CreatePayout(intent *models.PaymentIntent) (*ProviderIntent, error)
+
+	// ParseCallback проверяет подпись уведомления провайдера и разбирает его
+	ParseCallback(signature string, body []byte) (*PaymentEvent, error)
+}
+
+// PaymentStarter — провайдер, который начинает обработку платежа только после того,
+// как ссылка на него сохранена: иначе уведомление может прийти раньше, чем платёж найдётся по Ref
+type PaymentStarter interface {
+	Start(ref string)
+}
+
+type ProviderIntent struct {
+	Ref             string
+	ConfirmationURL string
+}
+
+type PaymentEvent struct {
+	Ref           string `json:"ref"`
+	Succeeded     bool   `json:"succeeded"`
+	FailureReason string `json:"failure_reason,omitempty"`
+}
+
+// CallbackFunc — получатель уведомлений провайдера (обычно PaymentService.HandleCallback)
+type CallbackFunc func(provider, signature string, body []byte) error
+
+// FakePaymentProvider — внутрипроцессный провайдер для локальной разработки:
+// подтверждает каждый платёж через delay, отправляя подписанное уведомление в callback.
+// Создаёт деньги из ничего, поэтому подключается только при PAYMENT_PROVIDER=fake
+type FakePaymentProvider struct {
+	secret   []byte
+	delay    time.Duration
+	callback CallbackFunc
+	logger   *slog.Logger
+}
+
+func NewFakePaymentProvider(secret []byte, delay time.Duration, logger *slog.Logger) *FakePaymentProvider {
+	return &FakePaymentProvider{
+		secret: secret,
+		delay:  delay,
+		logger: logger,
+	}
+}
+
+func (p *FakePaymentProvider) SetCallback(callback CallbackFunc) {
+	p.callback = callback
+}
+
+func (p *FakePaymentProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakePaymentProvider) CreateTopUp(intent *models.PaymentIntent) (*ProviderIntent, error) {
+	return p.create(intent)
+}
+
+func (p *FakePaymentProvider) CreatePayout(intent *models.PaymentIntent) (*ProviderIntent, error) {
+	return p.create(intent)
+}
+
+func (p *FakePaymentProvider) ParseCallback(signature string, body []byte) (*PaymentEvent, error) {
+	if !hmac.Equal([]byte(signature), []byte(p.Sign(body))) {
+		return nil, ErrInvalidSignature
+	}
+
+	var event PaymentEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+func (p *FakePaymentProvider) Sign(body []byte) string {
+	h := hmac.New(sha256.New, p.secret)
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (p *FakePaymentProvider) create(intent *models.PaymentIntent) (*ProviderIntent, error) {
+	ref, err := newTransactionID()
+	if err != nil {
+		return nil, err
+	}
+	ref = "fake_" + ref
+
+	return &ProviderIntent{Ref: ref}, nil
+}
+
+// Start подтверждает платёж после delay; вызывается, когда Ref уже сохранён
+func (p *FakePaymentProvider) Start(ref string) {
+	if p.callback != nil {
+		go p.confirm(ref)
+	}
+}
+
+func (p *FakePaymentProvider) confirm(ref string) {
+	time.Sleep(p.delay)
+
+	body, err := json.Marshal(PaymentEvent{Ref: ref, Succeeded: true})
+	if err != nil {
+		return
+	}
+
+	if err := p.callback(p.Name(), p.Sign(body), body); err != nil {
+		p.logger.Error("fake provider callback failed",
+			slog.String("ref", ref),
+			slog.Any("error", err),
+		)
+	}
+}
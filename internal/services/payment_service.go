@@ -0,0 +1,201 @@
+package services
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"gorm.io/gorm"
+)
+
+var ErrUnknownProvider = errors.New("unknown payment provider")
+
+type PaymentService interface {
+	TopUp(userID uint, amount int) (*dto.PaymentIntentResponse, error)
+
+	Withdraw(userID uint, amount int) (*dto.PaymentIntentResponse, error)
+
+	HandleCallback(provider, signature string, body []byte) error
+
+	List(userID uint, filter models.Page) (*dto.Page[models.PaymentIntent], error)
+}
+
+type paymentService struct {
+	paymentRepo repository.PaymentRepository
+	ledger      LedgerService
+	provider    PaymentProvider
+	db          *gorm.DB
+	logger      *slog.Logger
+}
+
+func NewPaymentService(
+	paymentRepo repository.PaymentRepository,
+	ledger LedgerService,
+	provider PaymentProvider,
+	db *gorm.DB,
+	logger *slog.Logger,
+) PaymentService {
+	return &paymentService{
+		paymentRepo: paymentRepo,
+		ledger:      ledger,
+		provider:    provider,
+		db:          db,
+		logger:      logger,
+	}
+}
+
+func (s *paymentService) TopUp(userID uint, amount int) (*dto.PaymentIntentResponse, error) {
+	op := "service.payment.TopUp"
+
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	intent := &models.PaymentIntent{
+		UserID:   userID,
+		Kind:     constants.PaymentTopUp,
+		Amount:   amount,
+		Status:   constants.PaymentPending,
+		Provider: s.provider.Name(),
+	}
+
+	if err := s.paymentRepo.Create(intent); err != nil {
+		return nil, err
+	}
+
+	pi, err := s.provider.CreateTopUp(intent)
+	if err != nil {
+		s.logger.Error("provider top-up error", slog.String("op", op), slog.Any("error", err))
+		if ferr := s.fail(intent, err.Error()); ferr != nil {
+			return nil, ferr
+		}
+		return nil, err
+	}
+
+	if err := s.paymentRepo.SetProviderRef(intent.ID, pi.Ref); err != nil {
+		return nil, err
+	}
+	intent.ProviderRef = &pi.Ref
+	s.start(pi.Ref)
+
+	return &dto.PaymentIntentResponse{Intent: intent, ConfirmationURL: pi.ConfirmationURL}, nil
+}
+
+func (s *paymentService) Withdraw(userID uint, amount int) (*dto.PaymentIntentResponse, error) {
+	op := "service.payment.Withdraw"
+
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	intent := &models.PaymentIntent{
+		UserID:   userID,
+		Kind:     constants.PaymentPayout,
+		Amount:   amount,
+		Status:   constants.PaymentPending,
+		Provider: s.provider.Name(),
+	}
+
+	// резервируем средства до того, как обращаться к провайдеру
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.paymentRepo.WithDB(tx).Create(intent); err != nil {
+			return err
+		}
+		return s.ledger.WithDB(tx).ReservePayout(intent)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pi, err := s.provider.CreatePayout(intent)
+	if err != nil {
+		s.logger.Error("provider payout error", slog.String("op", op), slog.Any("error", err))
+		if ferr := s.fail(intent, err.Error()); ferr != nil {
+			return nil, ferr
+		}
+		return nil, err
+	}
+
+	if err := s.paymentRepo.SetProviderRef(intent.ID, pi.Ref); err != nil {
+		return nil, err
+	}
+	intent.ProviderRef = &pi.Ref
+	s.start(pi.Ref)
+
+	return &dto.PaymentIntentResponse{Intent: intent, ConfirmationURL: pi.ConfirmationURL}, nil
+}
+
+// start даёт провайдеру начать обработку, когда платёж уже можно найти по ссылке
+func (s *paymentService) start(ref string) {
+	if starter, ok := s.provider.(PaymentStarter); ok {
+		starter.Start(ref)
+	}
+}
+
+func (s *paymentService) HandleCallback(provider, signature string, body []byte) error {
+	op := "service.payment.HandleCallback"
+
+	if provider != s.provider.Name() {
+		return ErrUnknownProvider
+	}
+
+	event, err := s.provider.ParseCallback(signature, body)
+	if err != nil {
+		return err
+	}
+
+	intent, err := s.paymentRepo.GetByProviderRef(provider, event.Ref)
+	if err != nil {
+		return err
+	}
+
+	if !event.Succeeded {
+		return s.fail(intent, event.FailureReason)
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		finalized, err := s.paymentRepo.WithDB(tx).Finalize(intent.ID, constants.PaymentSucceeded, "")
+		if err != nil {
+			return err
+		}
+		if !finalized {
+			// повторное уведомление — уже обработано
+			s.logger.Info("duplicate payment callback", slog.String("op", op), slog.String("ref", event.Ref))
+			return nil
+		}
+
+		ledger := s.ledger.WithDB(tx)
+
+		switch intent.Kind {
+		case constants.PaymentTopUp:
+			return ledger.TopUp(intent)
+		case constants.PaymentPayout:
+			return ledger.CompletePayout(intent)
+		}
+
+		return nil
+	})
+}
+
+func (s *paymentService) List(userID uint, filter models.Page) (*dto.Page[models.PaymentIntent], error) {
+	return s.paymentRepo.ListByUser(userID, filter)
+}
+
+// fail помечает платёж неуспешным и возвращает зарезервированные под вывод средства
+func (s *paymentService) fail(intent *models.PaymentIntent, reason string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		finalized, err := s.paymentRepo.WithDB(tx).Finalize(intent.ID, constants.PaymentFailed, reason)
+		if err != nil || !finalized {
+			return err
+		}
+
+		if intent.Kind == constants.PaymentPayout {
+			return s.ledger.WithDB(tx).ReleasePayout(intent)
+		}
+
+		return nil
+	})
+}
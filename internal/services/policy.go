@@ -0,0 +1,206 @@
+package services
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+)
+
+var (
+	ErrForbidden       = errors.New("forbidden")
+	ErrUnauthenticated = errors.New("unauthenticated")
+)
+
+type Action string
+
+const (
+	ActionView    Action = "view" // просмотр данных, закрытых от посторонних
+	ActionCreate  Action = "create"
+	ActionUpdate  Action = "update"
+	ActionDelete  Action = "delete"
+	ActionManage  Action = "manage"   // управление заявками на поездку водителем
+	ActionCancel  Action = "cancel"   // отмена брони пассажиром или водителем
+	ActionSetRole Action = "set_role" // смена роли пользователя
+)
+
+// BookingAccess — бронь вместе с поездкой: права на бронь зависят и от пассажира, и от водителя
+type BookingAccess struct {
+	Booking *models.Booking
+	Trip    *models.Trip
+}
+
+// Policy — единая точка проверки прав: каждый изменяющий метод сервиса проходит через Authorize
+type Policy interface {
+	Authorize(actorID uint, action Action, resource any) error
+}
+
+type rule func(actor *models.User, resource any) bool
+
+type rolePolicy struct {
+	userRepo repository.UserRepository
+	rules    map[string]map[Action]rule
+	logger   *slog.Logger
+}
+
+func NewPolicy(userRepo repository.UserRepository, logger *slog.Logger) Policy {
+	return &rolePolicy{
+		userRepo: userRepo,
+		rules:    defaultRules(),
+		logger:   logger,
+	}
+}
+
+func (p *rolePolicy) Authorize(actorID uint, action Action, resource any) error {
+	if actorID == 0 {
+		return ErrUnauthenticated
+	}
+
+	actor, err := p.userRepo.GetByID(actorID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrUnauthenticated
+		}
+		return err
+	}
+
+	if actor.Role == constants.RoleAdmin {
+		return nil
+	}
+
+	kind := resourceKind(resource)
+
+	allow, ok := p.rules[kind][action]
+	if !ok || !allow(actor, resource) {
+		p.logger.Warn("access denied",
+			slog.Uint64("actor_id", uint64(actorID)),
+			slog.String("resource", kind),
+			slog.String("action", string(action)),
+		)
+		return ErrForbidden
+	}
+
+	return nil
+}
+
+func resourceKind(resource any) string {
+	switch resource.(type) {
+	case *models.User:
+		return "user"
+	case *models.Car:
+		return "car"
+	case *models.Trip:
+		return "trip"
+	case BookingAccess:
+		return "booking"
+	case *models.Review:
+		return "review"
+	case *models.WaitlistEntry:
+		return "waitlist"
+	case *models.TripTemplate:
+		return "trip_template"
+	case *models.PromoCode:
+		return "promo_code"
+	case *models.VerificationRequest:
+		return "verification"
+	case *models.City:
+		return "city"
+	case *models.SavedSearch:
+		return "saved_search"
+	}
+	return ""
+}
+
+func defaultRules() map[string]map[Action]rule {
+	self := func(actor *models.User, res any) bool {
+		return res.(*models.User).ID == actor.ID
+	}
+	carOwner := func(actor *models.User, res any) bool {
+		return res.(*models.Car).OwnerID == actor.ID
+	}
+	tripDriver := func(actor *models.User, res any) bool {
+		return res.(*models.Trip).DriverID == actor.ID
+	}
+	bookingPassenger := func(actor *models.User, res any) bool {
+		return res.(BookingAccess).Booking.PassengerID == actor.ID
+	}
+	bookingDriver := func(actor *models.User, res any) bool {
+		access := res.(BookingAccess)
+		return access.Trip != nil && access.Trip.DriverID == actor.ID
+	}
+	bookingParticipant := func(actor *models.User, res any) bool {
+		return bookingPassenger(actor, res) || bookingDriver(actor, res)
+	}
+	reviewAuthor := func(actor *models.User, res any) bool {
+		return res.(*models.Review).AuthorID == actor.ID
+	}
+	templateDriver := func(actor *models.User, res any) bool {
+		return res.(*models.TripTemplate).DriverID == actor.ID
+	}
+	waitlistPassenger := func(actor *models.User, res any) bool {
+		return res.(*models.WaitlistEntry).PassengerID == actor.ID
+	}
+	verificationDriver := func(actor *models.User, res any) bool {
+		return res.(*models.VerificationRequest).DriverID == actor.ID
+	}
+	savedSearchOwner := func(actor *models.User, res any) bool {
+		return res.(*models.SavedSearch).UserID == actor.ID
+	}
+
+	return map[string]map[Action]rule{
+		"user": {
+			ActionUpdate: self,
+			ActionDelete: self,
+			// ActionSetRole и ActionManage (список пользователей) — только администратор
+		},
+		"car": {
+			ActionCreate: carOwner,
+			ActionUpdate: carOwner,
+			ActionDelete: carOwner,
+		},
+		"trip": {
+			ActionCreate: func(actor *models.User, res any) bool {
+				return actor.Role == constants.RoleDriver && tripDriver(actor, res)
+			},
+			ActionUpdate: tripDriver,
+			ActionDelete: tripDriver,
+			ActionManage: tripDriver,
+		},
+		"booking": {
+			ActionView:   bookingParticipant,
+			ActionCreate: bookingPassenger,
+			ActionDelete: bookingPassenger,
+			ActionManage: bookingDriver,
+			ActionCancel: bookingParticipant,
+			ActionUpdate: bookingParticipant,
+		},
+		"review": {
+			ActionCreate: reviewAuthor,
+			ActionUpdate: reviewAuthor,
+			ActionDelete: reviewAuthor,
+		},
+		"trip_template": {
+			ActionCreate: func(actor *models.User, res any) bool {
+				return actor.Role == constants.RoleDriver && templateDriver(actor, res)
+			},
+			ActionUpdate: templateDriver,
+			ActionDelete: templateDriver,
+		},
+		"waitlist": {
+			ActionCreate: waitlistPassenger,
+			ActionUpdate: waitlistPassenger,
+			ActionDelete: waitlistPassenger,
+		},
+		// "promo_code" и "city" — только администратор
+		"verification": {
+			ActionCreate: verificationDriver,
+			// ActionManage — рассмотрение заявок и просмотр документов, только администратор
+		},
+		"saved_search": {
+			ActionCreate: savedSearchOwner,
+			ActionDelete: savedSearchOwner,
+		},
+	}
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+)
+
+// PricingEngine считает стоимость участка поездки для заданного числа мест
+type PricingEngine interface {
+	Quote(trip *models.Trip, from, to, seats int, now time.Time) dto.PriceQuote
+}
+
+// PricingRule — необязательная поправка к базовой цене места; ok = false, если правило не сработало
+type PricingRule interface {
+	Adjust(in PricingInput) (adjustment dto.PriceAdjustment, ok bool)
+}
+
+// PricingInput — всё, что правилу нужно знать о бронировании
+type PricingInput struct {
+	Trip        *models.Trip
+	Seats       int
+	BasePerSeat int
+	// FreeAfter — сколько мест останется на участке после этой брони
+	FreeAfter int
+	Now       time.Time
+}
+
+type rulePricingEngine struct {
+	rules             []PricingRule
+	commissionPercent int
+}
+
+func NewPricingEngine(commissionPercent int, rules ...PricingRule) PricingEngine {
+	return &rulePricingEngine{
+		rules:             rules,
+		commissionPercent: commissionPercent,
+	}
+}
+
+func (e *rulePricingEngine) Quote(trip *models.Trip, from, to, seats int, now time.Time) dto.PriceQuote {
+	base := segmentBasePrice(trip, from, to)
+
+	quote := dto.PriceQuote{
+		TripID:      trip.ID,
+		FromStop:    from,
+		ToStop:      to,
+		Seats:       seats,
+		BasePerSeat: base,
+		PerSeat:     base,
+	}
+
+	in := PricingInput{
+		Trip:        trip,
+		Seats:       seats,
+		BasePerSeat: base,
+		FreeAfter:   segmentFree(trip, from, to) - seats,
+		Now:         now,
+	}
+
+	for _, rule := range e.rules {
+		if adj, ok := rule.Adjust(in); ok {
+			quote.Adjustments = append(quote.Adjustments, adj)
+			quote.PerSeat += adj.Amount
+		}
+	}
+
+	if quote.PerSeat < 0 {
+		quote.PerSeat = 0
+	}
+
+	quote.Total = quote.PerSeat * seats
+	quote.Commission = quote.Total * e.commissionPercent / 100
+
+	return quote
+}
+
+// applyQuote фиксирует расчёт цены в брони
+func applyQuote(booking *models.Booking, quote dto.PriceQuote) {
+	booking.PricePerSeat = quote.PerSeat
+	booking.TotalPrice = quote.Total
+	booking.Commission = quote.Commission
+}
+
+// bookingPrice — сумма к блокировке по брони; у броней, созданных до появления
+// расчёта цены, она берётся из текущей цены поездки
+func bookingPrice(booking *models.Booking, trip *models.Trip) int {
+	if booking.TotalPrice == 0 && booking.PricePerSeat == 0 {
+		return trip.Price * booking.Seats
+	}
+	return booking.TotalPrice
+}
+
+// segmentBasePrice — цена места на участке [from, to). Если водитель задал тарифы
+// на обеих остановках, берётся их разница; иначе цена поездки делится пропорционально
+// расстоянию, а без расстояний — пропорционально времени в пути.
+func segmentBasePrice(trip *models.Trip, from, to int) int {
+	if len(trip.Stops) == 0 || (from == 0 && to == lastStop(trip)) {
+		return trip.Price
+	}
+
+	start, end := trip.Stops[from], trip.Stops[to]
+
+	if fromFare, ok := stopFare(trip, start); ok {
+		if toFare, ok := stopFare(trip, end); ok {
+			return max(toFare-fromFare, 0)
+		}
+	}
+
+	total := trip.Stops[len(trip.Stops)-1]
+	if start.DistanceKm != nil && end.DistanceKm != nil && total.DistanceKm != nil && *total.DistanceKm > 0 {
+		return trip.Price * (*end.DistanceKm - *start.DistanceKm) / *total.DistanceKm
+	}
+
+	duration := total.ArrivalTime.Sub(trip.Stops[0].ArrivalTime)
+	if duration <= 0 {
+		return trip.Price
+	}
+
+	return int(int64(trip.Price) * int64(end.ArrivalTime.Sub(start.ArrivalTime)) / int64(duration))
+}
+
+// stopFare — тариф от начала маршрута до остановки: на концах маршрута он известен всегда
+func stopFare(trip *models.Trip, stop models.TripStop) (int, bool) {
+	switch {
+	case stop.Position == 0:
+		return 0, true
+	case stop.Position == lastStop(trip):
+		return trip.Price, true
+	case stop.Fare != nil:
+		return *stop.Fare, true
+	}
+	return 0, false
+}
+
+// earlyBirdRule — скидка при бронировании заранее
+type earlyBirdRule struct {
+	before  time.Duration
+	percent int
+}
+
+func NewEarlyBirdRule(before time.Duration, percent int) PricingRule {
+	return &earlyBirdRule{before: before, percent: percent}
+}
+
+func (r *earlyBirdRule) Adjust(in PricingInput) (dto.PriceAdjustment, bool) {
+	if in.Trip.StartTime.Sub(in.Now) < r.before {
+		return dto.PriceAdjustment{}, false
+	}
+	return dto.PriceAdjustment{Rule: "early_bird", Amount: -in.BasePerSeat * r.percent / 100}, true
+}
+
+// lastSeatsRule — надбавка, когда бронь забирает последние места участка
+type lastSeatsRule struct {
+	threshold int
+	percent   int
+}
+
+func NewLastSeatsRule(threshold, percent int) PricingRule {
+	return &lastSeatsRule{threshold: threshold, percent: percent}
+}
+
+func (r *lastSeatsRule) Adjust(in PricingInput) (dto.PriceAdjustment, bool) {
+	if in.FreeAfter > r.threshold-1 {
+		return dto.PriceAdjustment{}, false
+	}
+	return dto.PriceAdjustment{Rule: "last_seats", Amount: in.BasePerSeat * r.percent / 100}, true
+}
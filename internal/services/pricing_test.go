@@ -0,0 +1,100 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+)
+
+func TestSegmentBasePrice(t *testing.T) {
+	start := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	km := func(v int) *int { return &v }
+
+	// маршрут A → M → B за 4 часа, до M — час пути
+	stops := func(mid models.TripStop, last models.TripStop) []models.TripStop {
+		mid.Position, mid.ArrivalTime = 1, start.Add(time.Hour)
+		last.Position, last.ArrivalTime = 2, start.Add(4*time.Hour)
+		return []models.TripStop{{Position: 0, ArrivalTime: start, DistanceKm: km(0)}, mid, last}
+	}
+
+	cases := []struct {
+		name     string
+		stops    []models.TripStop
+		from, to int
+		want     int
+	}{
+		{name: "no stops", from: 0, to: 1, want: 1000},
+		{name: "whole route", stops: stops(models.TripStop{}, models.TripStop{}), from: 0, to: 2, want: 1000},
+		{name: "fare to middle", stops: stops(models.TripStop{Fare: km(300)}, models.TripStop{}), from: 0, to: 1, want: 300},
+		{name: "fare from middle", stops: stops(models.TripStop{Fare: km(300)}, models.TripStop{}), from: 1, to: 2, want: 700},
+		{name: "distance to middle", stops: stops(models.TripStop{DistanceKm: km(40)}, models.TripStop{DistanceKm: km(100)}), from: 0, to: 1, want: 400},
+		{name: "distance from middle", stops: stops(models.TripStop{DistanceKm: km(40)}, models.TripStop{DistanceKm: km(100)}), from: 1, to: 2, want: 600},
+		{name: "time to middle", stops: stops(models.TripStop{}, models.TripStop{}), from: 0, to: 1, want: 250},
+		{name: "time from middle", stops: stops(models.TripStop{}, models.TripStop{}), from: 1, to: 2, want: 750},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			trip := &models.Trip{Price: 1000, Stops: c.stops}
+			if got := segmentBasePrice(trip, c.from, c.to); got != c.want {
+				t.Errorf("segmentBasePrice(%d, %d) = %d, want %d", c.from, c.to, got, c.want)
+			}
+		})
+	}
+}
+
+func TestPricingEngineQuote(t *testing.T) {
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	engine := NewPricingEngine(10,
+		NewEarlyBirdRule(7*24*time.Hour, 10),
+		NewLastSeatsRule(1, 20),
+	)
+
+	cases := []struct {
+		name           string
+		startIn        time.Duration
+		seats          int
+		wantPerSeat    int
+		wantTotal      int
+		wantCommission int
+	}{
+		{name: "early bird", startIn: 10 * 24 * time.Hour, seats: 1, wantPerSeat: 900, wantTotal: 900, wantCommission: 90},
+		{name: "no rules", startIn: 24 * time.Hour, seats: 1, wantPerSeat: 1000, wantTotal: 1000, wantCommission: 100},
+		{name: "last seats", startIn: 24 * time.Hour, seats: 4, wantPerSeat: 1200, wantTotal: 4800, wantCommission: 480},
+		{name: "early bird and last seats", startIn: 10 * 24 * time.Hour, seats: 4, wantPerSeat: 1100, wantTotal: 4400, wantCommission: 440},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			trip := &models.Trip{Price: 1000, TotalSeats: 4, AvailableSeats: 4, StartTime: now.Add(c.startIn)}
+
+			quote := engine.Quote(trip, 0, 1, c.seats, now)
+			if quote.PerSeat != c.wantPerSeat || quote.Total != c.wantTotal || quote.Commission != c.wantCommission {
+				t.Errorf("quote = %d/seat, total %d, commission %d; want %d/seat, total %d, commission %d",
+					quote.PerSeat, quote.Total, quote.Commission, c.wantPerSeat, c.wantTotal, c.wantCommission)
+			}
+		})
+	}
+}
+
+func TestBookingPrice(t *testing.T) {
+	trip := &models.Trip{Price: 1000}
+
+	cases := []struct {
+		name    string
+		booking models.Booking
+		want    int
+	}{
+		{name: "quoted", booking: models.Booking{Seats: 2, PricePerSeat: 900, TotalPrice: 1800}, want: 1800},
+		{name: "fully discounted", booking: models.Booking{Seats: 2, PricePerSeat: 900, TotalPrice: 0}, want: 0},
+		{name: "before quotes", booking: models.Booking{Seats: 2}, want: 2000},
+	}
+
+	for _, c := range cases {
+		if got := bookingPrice(&c.booking, trip); got != c.want {
+			t.Errorf("%s: bookingPrice = %d, want %d", c.name, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package services
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPromoInvalid     = errors.New("promo code is not valid")
+	ErrPromoExhausted   = errors.New("promo code usage limit reached")
+	ErrPromoNotEligible = errors.New("promo code is not applicable to this passenger")
+)
+
+type PromoService interface {
+	Create(actorID uint, req *dto.PromoCodeCreateRequest) (*models.PromoCode, error)
+
+	List(actorID uint, filter models.Page) (*dto.Page[models.PromoCode], error)
+
+	Update(actorID, id uint, req *dto.PromoCodeUpdateRequest) (*models.PromoCode, error)
+
+	// Preview применяет код к расчёту цены; лимиты на пассажира проверяются только при бронировании
+	Preview(code string, quote *dto.PriceQuote) error
+
+	// Apply проверяет код под блокировкой и уменьшает цену брони на скидку;
+	// вызывается в транзакции создания брони до её сохранения
+	Apply(tx *gorm.DB, code string, booking *models.Booking) error
+
+	// Record записывает применение кода к уже сохранённой брони
+	Record(tx *gorm.DB, booking *models.Booking) error
+}
+
+type promoService struct {
+	promoRepo   repository.PromoRepository
+	bookingRepo repository.BookingRepository
+	policy      Policy
+	logger      *slog.Logger
+	now         func() time.Time
+}
+
+func NewPromoService(
+	promoRepo repository.PromoRepository,
+	bookingRepo repository.BookingRepository,
+	policy Policy,
+	logger *slog.Logger,
+) PromoService {
+	return &promoService{
+		promoRepo:   promoRepo,
+		bookingRepo: bookingRepo,
+		policy:      policy,
+		logger:      logger,
+		now:         time.Now,
+	}
+}
+
+func (s *promoService) Create(actorID uint, req *dto.PromoCodeCreateRequest) (*models.PromoCode, error) {
+	op := "service.promo.Create"
+
+	s.logger.Debug(" call", slog.String("op", op))
+
+	promo := &models.PromoCode{
+		Code:          normalizePromoCode(req.Code),
+		DiscountType:  req.DiscountType,
+		DiscountValue: req.DiscountValue,
+		MaxUses:       req.MaxUses,
+		PerUserLimit:  1,
+		FirstTripOnly: req.FirstTripOnly,
+		ValidFrom:     req.ValidFrom,
+		ValidUntil:    req.ValidUntil,
+		Active:        true,
+	}
+	if req.PerUserLimit != nil {
+		promo.PerUserLimit = *req.PerUserLimit
+	}
+
+	if err := s.policy.Authorize(actorID, ActionCreate, promo); err != nil {
+		return nil, err
+	}
+
+	if err := validatePromo(promo); err != nil {
+		return nil, err
+	}
+
+	if err := s.promoRepo.Create(promo); err != nil {
+		s.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	s.logger.Info("promo code created", slog.String("op", op), slog.String("code", promo.Code))
+	return promo, nil
+}
+
+func (s *promoService) List(actorID uint, filter models.Page) (*dto.Page[models.PromoCode], error) {
+	// список кодов видит только администратор
+	if err := s.policy.Authorize(actorID, ActionManage, &models.PromoCode{}); err != nil {
+		return nil, err
+	}
+
+	return s.promoRepo.List(filter)
+}
+
+func (s *promoService) Update(actorID, id uint, req *dto.PromoCodeUpdateRequest) (*models.PromoCode, error) {
+	op := "service.promo.Update"
+
+	s.logger.Debug(" call", slog.String("op", op), slog.Uint64("promo_id", uint64(id)))
+
+	promo, err := s.promoRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.policy.Authorize(actorID, ActionUpdate, promo); err != nil {
+		return nil, err
+	}
+
+	if req.MaxUses != nil {
+		promo.MaxUses = *req.MaxUses
+	}
+	if req.PerUserLimit != nil {
+		promo.PerUserLimit = *req.PerUserLimit
+	}
+	if req.ValidFrom != nil {
+		promo.ValidFrom = req.ValidFrom
+	}
+	if req.ValidUntil != nil {
+		promo.ValidUntil = req.ValidUntil
+	}
+	if req.Active != nil {
+		promo.Active = *req.Active
+	}
+
+	if err := validatePromo(promo); err != nil {
+		return nil, err
+	}
+
+	if err := s.promoRepo.Update(promo); err != nil {
+		s.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return promo, nil
+}
+
+func (s *promoService) Preview(code string, quote *dto.PriceQuote) error {
+	promo, err := s.promoRepo.GetByCode(normalizePromoCode(code))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrPromoInvalid
+		}
+		return err
+	}
+
+	if err := s.checkUsable(s.promoRepo, promo); err != nil {
+		return err
+	}
+
+	quote.PromoCode = promo.Code
+	quote.Discount = promoDiscount(promo, quote.Total)
+	quote.Total -= quote.Discount
+
+	return nil
+}
+
+func (s *promoService) Apply(tx *gorm.DB, code string, booking *models.Booking) error {
+	op := "service.promo.Apply"
+
+	s.logger.Debug(" call", slog.String("op", op), slog.Uint64("passenger_id", uint64(booking.PassengerID)))
+
+	promoRepo := s.promoRepo.WithDB(tx)
+
+	promo, err := promoRepo.GetByCodeForUpdate(normalizePromoCode(code))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrPromoInvalid
+		}
+		return err
+	}
+
+	if err := s.checkUsable(promoRepo, promo); err != nil {
+		return err
+	}
+
+	if promo.PerUserLimit > 0 {
+		used, err := promoRepo.CountUserRedemptions(promo.ID, booking.PassengerID)
+		if err != nil {
+			return err
+		}
+		if used >= int64(promo.PerUserLimit) {
+			return ErrPromoNotEligible
+		}
+	}
+
+	if promo.FirstTripOnly {
+		completed, err := s.bookingRepo.WithDB(tx).CountCompletedByPassenger(booking.PassengerID)
+		if err != nil {
+			return err
+		}
+		if completed > 0 {
+			return ErrPromoNotEligible
+		}
+	}
+
+	discount := promoDiscount(promo, booking.TotalPrice)
+
+	booking.PromoCodeID = &promo.ID
+	booking.Discount = discount
+	booking.TotalPrice -= discount
+
+	return nil
+}
+
+func (s *promoService) Record(tx *gorm.DB, booking *models.Booking) error {
+	if booking.PromoCodeID == nil {
+		return nil
+	}
+
+	return s.promoRepo.WithDB(tx).CreateRedemption(&models.PromoRedemption{
+		PromoCodeID: *booking.PromoCodeID,
+		UserID:      booking.PassengerID,
+		BookingID:   booking.ID,
+		Amount:      booking.Discount,
+	})
+}
+
+// checkUsable — проверки самого кода, не зависящие от пассажира
+func (s *promoService) checkUsable(promoRepo repository.PromoRepository, promo *models.PromoCode) error {
+	now := s.now()
+
+	if !promo.Active ||
+		(promo.ValidFrom != nil && now.Before(*promo.ValidFrom)) ||
+		(promo.ValidUntil != nil && !now.Before(*promo.ValidUntil)) {
+		return ErrPromoInvalid
+	}
+
+	if promo.MaxUses > 0 {
+		used, err := promoRepo.CountRedemptions(promo.ID)
+		if err != nil {
+			return err
+		}
+		if used >= int64(promo.MaxUses) {
+			return ErrPromoExhausted
+		}
+	}
+
+	return nil
+}
+
+func validatePromo(promo *models.PromoCode) error {
+	if promo.Code == "" ||
+		promo.DiscountValue <= 0 ||
+		(promo.DiscountType != constants.PromoPercent && promo.DiscountType != constants.PromoFixed) ||
+		(promo.DiscountType == constants.PromoPercent && promo.DiscountValue > 100) ||
+		(promo.ValidFrom != nil && promo.ValidUntil != nil && !promo.ValidFrom.Before(*promo.ValidUntil)) {
+		return ErrPromoInvalid
+	}
+	return nil
+}
+
+// promoDiscount — скидка на бронь стоимостью total; больше самой стоимости не бывает
+func promoDiscount(promo *models.PromoCode, total int) int {
+	if promo.DiscountType == constants.PromoPercent {
+		return total * promo.DiscountValue / 100
+	}
+	return min(promo.DiscountValue, total)
+}
+
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
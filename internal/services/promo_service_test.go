@@ -0,0 +1,60 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+)
+
+func TestPromoDiscount(t *testing.T) {
+	cases := []struct {
+		name  string
+		kind  constants.PromoDiscountType
+		value int
+		total int
+		want  int
+	}{
+		{name: "percent", kind: constants.PromoPercent, value: 10, total: 1000, want: 100},
+		{name: "percent rounds down", kind: constants.PromoPercent, value: 15, total: 999, want: 149},
+		{name: "whole price", kind: constants.PromoPercent, value: 100, total: 1000, want: 1000},
+		{name: "fixed", kind: constants.PromoFixed, value: 300, total: 1000, want: 300},
+		{name: "fixed above price", kind: constants.PromoFixed, value: 1500, total: 1000, want: 1000},
+		{name: "free booking", kind: constants.PromoFixed, value: 300, total: 0, want: 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			promo := &models.PromoCode{DiscountType: c.kind, DiscountValue: c.value}
+			if got := promoDiscount(promo, c.total); got != c.want {
+				t.Errorf("promoDiscount = %d, want %d", got, c.want)
+			}
+		})
+	}
+}
+
+func TestValidatePromo(t *testing.T) {
+	cases := []struct {
+		name    string
+		kind    constants.PromoDiscountType
+		value   int
+		wantErr bool
+	}{
+		{name: "percent", kind: constants.PromoPercent, value: 10},
+		{name: "fixed", kind: constants.PromoFixed, value: 300},
+		{name: "percent above 100", kind: constants.PromoPercent, value: 101, wantErr: true},
+		{name: "zero value", kind: constants.PromoFixed, value: 0, wantErr: true},
+		{name: "negative value", kind: constants.PromoFixed, value: -100, wantErr: true},
+		{name: "unknown type", kind: "gift", value: 100, wantErr: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validatePromo(&models.PromoCode{Code: "SPRING", DiscountType: c.kind, DiscountValue: c.value})
+			if c.wantErr != errors.Is(err, ErrPromoInvalid) {
+				t.Errorf("validatePromo error = %v, want error %v", err, c.wantErr)
+			}
+		})
+	}
+}
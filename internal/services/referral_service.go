@@ -0,0 +1,177 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrReferralInvalid    = errors.New("referral code is not valid")
+	ErrReferralNotAllowed = errors.New("referral code can no longer be applied")
+)
+
+type ReferralService interface {
+	// Code возвращает реферальный код пользователя, выдавая его при первом запросе
+	Code(userID uint) (string, error)
+
+	// Apply привязывает пользователя к пригласившему до его первой завершённой поездки
+	Apply(userID uint, code string) error
+
+	// Reward начисляет бонусы обоим после первой завершённой поездки приглашённого;
+	// вызывается в транзакции завершения поездки
+	Reward(tx *gorm.DB, booking *models.Booking) error
+}
+
+type referralService struct {
+	userRepo      repository.UserRepository
+	bookingRepo   repository.BookingRepository
+	ledger        LedgerService
+	referrerBonus int
+	refereeBonus  int
+	logger        *slog.Logger
+}
+
+func NewReferralService(
+	userRepo repository.UserRepository,
+	bookingRepo repository.BookingRepository,
+	ledger LedgerService,
+	referrerBonus int,
+	refereeBonus int,
+	logger *slog.Logger,
+) ReferralService {
+	return &referralService{
+		userRepo:      userRepo,
+		bookingRepo:   bookingRepo,
+		ledger:        ledger,
+		referrerBonus: referrerBonus,
+		refereeBonus:  refereeBonus,
+		logger:        logger,
+	}
+}
+
+func (s *referralService) Code(userID uint) (string, error) {
+	op := "service.referral.Code"
+
+	s.logger.Debug(" call", slog.String("op", op), slog.Uint64("user_id", uint64(userID)))
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return "", err
+	}
+	if user.ReferralCode != nil {
+		return *user.ReferralCode, nil
+	}
+
+	code, err := newReferralCode()
+	if err != nil {
+		return "", err
+	}
+
+	ok, err := s.userRepo.SetReferralCode(userID, code)
+	if err != nil {
+		s.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
+		return "", err
+	}
+	if !ok {
+		// код выдан параллельным запросом
+		user, err := s.userRepo.GetByID(userID)
+		if err != nil {
+			return "", err
+		}
+		return *user.ReferralCode, nil
+	}
+
+	return code, nil
+}
+
+func (s *referralService) Apply(userID uint, code string) error {
+	op := "service.referral.Apply"
+
+	s.logger.Debug(" call", slog.String("op", op), slog.Uint64("user_id", uint64(userID)))
+
+	referrer, err := s.userRepo.GetByReferralCode(strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrReferralInvalid
+		}
+		return err
+	}
+	if referrer.ID == userID {
+		return ErrReferralInvalid
+	}
+
+	completed, err := s.bookingRepo.CountCompletedByPassenger(userID)
+	if err != nil {
+		return err
+	}
+	if completed > 0 {
+		return ErrReferralNotAllowed
+	}
+
+	ok, err := s.userRepo.SetReferrer(userID, referrer.ID)
+	if err != nil {
+		s.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+	if !ok {
+		return ErrReferralNotAllowed
+	}
+
+	s.logger.Info("referral applied",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Uint64("referrer_id", uint64(referrer.ID)),
+	)
+	return nil
+}
+
+func (s *referralService) Reward(tx *gorm.DB, booking *models.Booking) error {
+	userRepo := s.userRepo.WithDB(tx)
+
+	user, err := userRepo.GetByID(booking.PassengerID)
+	if err != nil {
+		return err
+	}
+	if user.ReferredByID == nil || user.ReferralRewarded {
+		return nil
+	}
+
+	ok, err := userRepo.MarkReferralRewarded(user.ID)
+	if err != nil || !ok {
+		return err
+	}
+
+	ledger := s.ledger.WithDB(tx)
+
+	// пригласивший мог удалить аккаунт — тогда бонус получает только приглашённый
+	if _, err := userRepo.GetByID(*user.ReferredByID); err == nil {
+		if err := ledger.Bonus(*user.ReferredByID, booking, s.referrerBonus); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	if err := ledger.Bonus(user.ID, booking, s.refereeBonus); err != nil {
+		return err
+	}
+
+	s.logger.Info("referral rewarded",
+		slog.Uint64("user_id", uint64(user.ID)),
+		slog.Uint64("referrer_id", uint64(*user.ReferredByID)),
+	)
+	return nil
+}
+
+func newReferralCode() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return strings.ToUpper(hex.EncodeToString(b)), nil
+}
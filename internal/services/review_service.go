@@ -26,7 +26,7 @@ var (
 type ReviewService interface {
 	Create(tripID, authorID uint, req *dto.ReviewCreateRequest) (*models.Review, error)
 
-	List(filter models.Page) ([]dto.ReviewListItem, error)
+	List(filter models.Page) (*dto.Page[dto.ReviewListItem], error)
 
 	GetByID(id uint) (*models.Review, error)
 
@@ -38,6 +38,7 @@ type ReviewService interface {
 type reviewService struct {
 	reviewRepo repository.ReviewRepository
 	tripRepo   repository.TripRepository
+	policy     Policy
 	logger     *slog.Logger
 	redis      *redis.Client
 	db         *gorm.DB
@@ -46,6 +47,7 @@ type reviewService struct {
 func NewReviewService(
 	reviewRepo repository.ReviewRepository,
 	tripRepo repository.TripRepository,
+	policy Policy,
 	db *gorm.DB,
 	redis *redis.Client,
 	logger *slog.Logger,
@@ -53,6 +55,7 @@ func NewReviewService(
 	return &reviewService{
 		reviewRepo: reviewRepo,
 		tripRepo:   tripRepo,
+		policy:     policy,
 		logger:     logger,
 		redis:      redis,
 		db:         db,
@@ -113,6 +116,10 @@ func (s *reviewService) Create(tripID, authorId uint, req *dto.ReviewCreateReque
 			Text:     req.Text,
 		}
 
+		if err := s.policy.Authorize(authorId, ActionCreate, review); err != nil {
+			return err
+		}
+
 		if err := rr.Create(review); err != nil {
 			s.logger.Error("error creating review", slog.String("op", op), slog.Any("error", err))
 			return err
@@ -134,12 +141,13 @@ func (s *reviewService) Create(tripID, authorId uint, req *dto.ReviewCreateReque
 	return created, nil
 }
 
-func (s *reviewService) List(filter models.Page) ([]dto.ReviewListItem, error) {
+func (s *reviewService) List(filter models.Page) (*dto.Page[dto.ReviewListItem], error) {
 	op := "service.review.list"
 
 	// 🔥 кешируем ТОЛЬКО первую страницу БЕЗ фильтров
 	useCache := filter.Page <= 1 &&
 		filter.LastID == nil &&
+		filter.Cursor == "" &&
 		filter.TripID == nil &&
 		filter.AuthorID == nil
 
@@ -150,10 +158,10 @@ func (s *reviewService) List(filter models.Page) ([]dto.ReviewListItem, error) {
 		cacheKey = buildReviewListCacheKey(filter)
 
 		if data, err := s.redis.Get(ctx, cacheKey).Bytes(); err == nil {
-			var items []dto.ReviewListItem
+			var items dto.Page[dto.ReviewListItem]
 			if err := json.Unmarshal(data, &items); err == nil {
 				s.logger.Debug("cache hit", slog.String("op", op))
-				return items, nil
+				return &items, nil
 			}
 		}
 	}
@@ -199,8 +207,8 @@ func (s *reviewService) Update(id, authorID uint, req *dto.ReviewUpdateRequest)
 			return err
 		}
 
-		if review.AuthorID != authorID {
-			return errors.New("permission denied")
+		if err := s.policy.Authorize(authorID, ActionUpdate, review); err != nil {
+			return err
 		}
 
 		if req.Text != nil {
@@ -242,8 +250,8 @@ func (s *reviewService) Delete(id, authorID uint) error {
 			return err
 		}
 
-		if review.AuthorID != authorID {
-			return errors.New("permission denied")
+		if err := s.policy.Authorize(authorID, ActionDelete, review); err != nil {
+			return err
 		}
 
 		if err := rr.Delete(id); err != nil {
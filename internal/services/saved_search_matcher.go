@@ -0,0 +1,123 @@
+package services
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+)
+
+// TripMatcher сообщает подписчикам о новой поездке, подходящей под их сохранённый поиск
+type TripMatcher interface {
+	Match(trip *models.Trip) error
+}
+
+type savedSearchMatcher struct {
+	repo     repository.SavedSearchRepository
+	tripRepo repository.TripRepository
+	notifier Notifier
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+func NewSavedSearchMatcher(
+	repo repository.SavedSearchRepository,
+	tripRepo repository.TripRepository,
+	notifier Notifier,
+	logger *slog.Logger,
+) TripMatcher {
+	return &savedSearchMatcher{
+		repo:     repo,
+		tripRepo: tripRepo,
+		notifier: notifier,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// Match проверяет поездку по всем действующим подпискам на города её маршрута тем же
+// запросом, что и поиск поездок. О каждой поездке подписчик узнаёт один раз, даже если
+// Match вызван повторно
+func (m *savedSearchMatcher) Match(trip *models.Trip) error {
+	op := "service.saved_search.Match"
+
+	m.logger.Debug(" call", slog.String("op", op), slog.Uint64("trip_id", uint64(trip.ID)))
+
+	now := m.now()
+	stops := routeStops(trip)
+
+	cityIDs := make([]uint, 0, len(stops))
+	for _, stop := range stops {
+		if stop.CityID != nil {
+			cityIDs = append(cityIDs, *stop.CityID)
+		}
+	}
+
+	searches, err := m.repo.ListActiveForCities(cityIDs, now)
+	if err != nil || len(searches) == 0 {
+		return err
+	}
+
+	var notifications []Notification
+
+	for i := range searches {
+		search := &searches[i]
+		if search.UserID == trip.DriverID {
+			continue
+		}
+
+		filter := savedSearchFilter(search)
+		filter.TripID = &trip.ID
+		filter.PageSize = 1
+
+		_, total, err := m.tripRepo.List(filter)
+		if err != nil {
+			m.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
+			continue
+		}
+		if total == 0 {
+			continue
+		}
+
+		first, err := m.repo.RecordMatch(search.ID, trip.ID)
+		if err != nil {
+			m.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
+			continue
+		}
+		if !first {
+			continue
+		}
+
+		notifications = append(notifications, Notification{
+			UserID:        search.UserID,
+			Event:         "saved_search_match",
+			Message:       "Появилась поездка по вашему сохранённому поиску: " + trip.FromCity + " — " + trip.ToCity,
+			TripID:        &trip.ID,
+			SavedSearchID: &search.ID,
+		})
+	}
+
+	dispatch(m.notifier, m.logger, notifications)
+
+	return nil
+}
+
+// routeStops — остановки поездки; у поездок без остановок маршрут из двух точек
+func routeStops(trip *models.Trip) []models.TripStop {
+	if len(trip.Stops) > 0 {
+		return trip.Stops
+	}
+
+	return []models.TripStop{
+		{
+			Position:    0,
+			City:        trip.FromCity,
+			CityID:      trip.FromCityID,
+			Lat:         trip.FromLat,
+			Lng:         trip.FromLng,
+			BookedSeats: trip.TotalSeats - trip.AvailableSeats,
+		},
+		{Position: 1, City: trip.ToCity, CityID: trip.ToCityID, Lat: trip.ToLat, Lng: trip.ToLng},
+	}
+}
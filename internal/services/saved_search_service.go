@@ -0,0 +1,218 @@
+package services
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+)
+
+var (
+	ErrInvalidSavedSearch = errors.New("saved search needs a departure or arrival and a valid time window and expiry")
+	ErrSavedSearchLimit   = errors.New("saved search limit reached")
+)
+
+type SavedSearchService interface {
+	Create(userID uint, req *dto.SavedSearchCreateRequest) (*models.SavedSearch, error)
+
+	ListMine(userID uint) ([]models.SavedSearch, error)
+
+	Delete(actorID, id uint) error
+}
+
+type savedSearchService struct {
+	repo       repository.SavedSearchRepository
+	cities     CityService
+	policy     Policy
+	defaultTTL time.Duration
+	maxTTL     time.Duration
+	maxPerUser int
+	logger     *slog.Logger
+	now        func() time.Time
+}
+
+func NewSavedSearchService(
+	repo repository.SavedSearchRepository,
+	cities CityService,
+	policy Policy,
+	defaultTTL time.Duration,
+	maxTTL time.Duration,
+	maxPerUser int,
+	logger *slog.Logger,
+) SavedSearchService {
+	return &savedSearchService{
+		repo:       repo,
+		cities:     cities,
+		policy:     policy,
+		defaultTTL: defaultTTL,
+		maxTTL:     maxTTL,
+		maxPerUser: maxPerUser,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+func (s *savedSearchService) Create(userID uint, req *dto.SavedSearchCreateRequest) (*models.SavedSearch, error) {
+	op := "service.saved_search.Create"
+
+	s.logger.Debug(" call", slog.String("op", op), slog.Uint64("user_id", uint64(userID)))
+
+	now := s.now()
+
+	if req.FromCity == nil && req.ToCity == nil && req.FromPoint == nil && req.ToPoint == nil {
+		return nil, ErrInvalidSavedSearch
+	}
+	if req.StartTime != nil && req.StartTimeTo != nil && req.StartTimeTo.Before(*req.StartTime) {
+		return nil, ErrInvalidSavedSearch
+	}
+
+	expiresAt := now.Add(s.defaultTTL)
+	if req.ExpiresAt != nil {
+		expiresAt = *req.ExpiresAt
+	}
+	if !expiresAt.After(now) || expiresAt.After(now.Add(s.maxTTL)) {
+		return nil, ErrInvalidSavedSearch
+	}
+
+	seats := req.Seats
+	if seats == 0 {
+		seats = 1
+	}
+
+	search := models.SavedSearch{
+		UserID:          userID,
+		FromCity:        req.FromCity,
+		ToCity:          req.ToCity,
+		StartTime:       req.StartTime,
+		StartTimeTo:     req.StartTimeTo,
+		Seats:           seats,
+		MaxPrice:        req.MaxPrice,
+		MinRating:       req.MinRating,
+		VerifiedOnly:    req.VerifiedOnly,
+		AirConditioning: req.Amenities.AirConditioning,
+		LuggageSpace:    req.Amenities.LuggageSpace,
+		ChildSeat:       req.Amenities.ChildSeat,
+		PetsAllowed:     req.Amenities.PetsAllowed,
+		SmokingAllowed:  req.Amenities.SmokingAllowed,
+		ExpiresAt:       expiresAt,
+	}
+	if req.FromPoint != nil {
+		search.FromLat, search.FromLng = pointCoords(req.FromPoint)
+		search.FromRadiusKm = &req.FromRadiusKm
+	}
+	if req.ToPoint != nil {
+		search.ToLat, search.ToLng = pointCoords(req.ToPoint)
+		search.ToRadiusKm = &req.ToRadiusKm
+	}
+
+	if err := s.policy.Authorize(userID, ActionCreate, &search); err != nil {
+		return nil, err
+	}
+
+	// как и в поиске, город вне справочника ищется по названию
+	var err error
+	if search.FromCityID, err = s.searchCity(search.FromCity); err != nil {
+		return nil, err
+	}
+	if search.ToCityID, err = s.searchCity(search.ToCity); err != nil {
+		return nil, err
+	}
+
+	count, err := s.repo.CountActiveByUser(userID, now)
+	if err != nil {
+		s.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+	if count >= int64(s.maxPerUser) {
+		return nil, ErrSavedSearchLimit
+	}
+
+	if err := s.repo.Create(&search); err != nil {
+		s.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return &search, nil
+}
+
+// searchCity — город справочника для критериев поиска: как и в поиске поездок,
+// название подбирается с учётом опечаток, а неизвестное остаётся как есть
+func (s *savedSearchService) searchCity(name *string) (*uint, error) {
+	if name == nil {
+		return nil, nil
+	}
+
+	city, err := s.cities.Resolve(*name)
+	if errors.Is(err, ErrUnknownCity) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	id := city.ID
+	*name = city.Name
+	return &id, nil
+}
+
+func (s *savedSearchService) ListMine(userID uint) ([]models.SavedSearch, error) {
+	return s.repo.ListByUser(userID)
+}
+
+func (s *savedSearchService) Delete(actorID, id uint) error {
+	op := "service.saved_search.Delete"
+
+	s.logger.Debug(" call", slog.String("op", op), slog.Uint64("saved_search_id", uint64(id)))
+
+	search, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.policy.Authorize(actorID, ActionDelete, search); err != nil {
+		return err
+	}
+
+	return s.repo.Delete(id)
+}
+
+// savedSearchFilter — условия подписки в виде фильтра поиска поездок
+func savedSearchFilter(search *models.SavedSearch) dto.TripFilter {
+	seats := search.Seats
+
+	filter := dto.TripFilter{
+		FromCity:        search.FromCity,
+		ToCity:          search.ToCity,
+		FromCityID:      search.FromCityID,
+		ToCityID:        search.ToCityID,
+		StartTime:       search.StartTime,
+		StartTimeTo:     search.StartTimeTo,
+		AvailableSeats:  &seats,
+		MaxPrice:        search.MaxPrice,
+		MinRating:       search.MinRating,
+		VerifiedOnly:    search.VerifiedOnly,
+		AirConditioning: search.AirConditioning,
+		LuggageSpace:    search.LuggageSpace,
+		ChildSeat:       search.ChildSeat,
+		PetsAllowed:     search.PetsAllowed,
+		SmokingAllowed:  search.SmokingAllowed,
+	}
+
+	if search.FromLat != nil && search.FromLng != nil && search.FromRadiusKm != nil {
+		filter.Origin = &dto.GeoArea{
+			GeoPoint: dto.GeoPoint{Lat: *search.FromLat, Lng: *search.FromLng},
+			RadiusKm: *search.FromRadiusKm,
+		}
+	}
+	if search.ToLat != nil && search.ToLng != nil && search.ToRadiusKm != nil {
+		filter.Destination = &dto.GeoArea{
+			GeoPoint: dto.GeoPoint{Lat: *search.ToLat, Lng: *search.ToLng},
+			RadiusKm: *search.ToRadiusKm,
+		}
+	}
+
+	return filter
+}
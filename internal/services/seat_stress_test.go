@@ -0,0 +1,305 @@
+package services
+
+// Нагрузочная проверка резервирования мест: создаёт поездку с промежуточной остановкой,
+// набирает заявок больше, чем мест, и параллельно подтверждает их все. После прогона
+// сверяет места на каждом перегоне и удержания на кошельках с подтверждёнными бронями.
+//
+// Нужна отдельная база: тест пишет в неё пользователей, поездки и брони.
+//
+//	SEATSTRESS_DATABASE_URL=postgres://... go test -count=1 -run TestSeatStress ./internal/services/
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+const seatStressRounds = 5
+
+type seatStress struct {
+	users    repository.UserRepository
+	cars     repository.CarRepository
+	trips    repository.TripRepository
+	bookings repository.BookingRepository
+	ledger   LedgerService
+	service  BookingService
+}
+
+func TestSeatStress(t *testing.T) {
+	dsn := os.Getenv("SEATSTRESS_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("SEATSTRESS_DATABASE_URL is not set")
+	}
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: dsn, PreferSimpleProtocol: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+
+	if err := db.AutoMigrate(
+		&models.User{},
+		&models.Car{},
+		&models.Trip{},
+		&models.TripStop{},
+		&models.Booking{},
+		&models.LedgerEntry{},
+		&models.WaitlistEntry{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	s := newSeatStress(db)
+
+	cases := []struct {
+		name       string
+		seats      int
+		passengers int
+		perBooking int
+	}{
+		{name: "one seat per booking", seats: 3, passengers: 40, perBooking: 1},
+		{name: "two seats per booking", seats: 5, passengers: 40, perBooking: 2},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			for round := 1; round <= seatStressRounds; round++ {
+				if err := s.round(c.seats, c.passengers, c.perBooking); err != nil {
+					t.Errorf("round %d: %v", round, err)
+				}
+			}
+		})
+	}
+}
+
+func newSeatStress(db *gorm.DB) *seatStress {
+	quiet := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	userRepo := repository.NewUserRepository(db, quiet)
+	bookingRepo := repository.NewBookingRepository(db, quiet)
+	tripRepo := repository.NewTripRepository(db, quiet)
+
+	policy := NewPolicy(userRepo, quiet)
+	ledger := NewLedgerService(repository.NewLedgerRepository(db, quiet), userRepo, quiet)
+	cancellation := NewCancellationPolicy(24*time.Hour, 2*time.Hour, 50)
+	pricing := NewPricingEngine(0)
+	promoRepo := repository.NewPromoRepository(db, quiet)
+	promo := NewPromoService(promoRepo, bookingRepo, policy, quiet)
+	notifier := NewLogNotifier(quiet)
+	waitlist := NewWaitlistService(
+		repository.NewWaitlistRepository(db, quiet),
+		bookingRepo,
+		tripRepo,
+		promoRepo,
+		ledger,
+		policy,
+		cancellation,
+		pricing,
+		notifier,
+		30*time.Minute,
+		db,
+		quiet,
+	)
+
+	return &seatStress{
+		users:    userRepo,
+		cars:     repository.NewCarRepository(db, quiet),
+		trips:    tripRepo,
+		bookings: bookingRepo,
+		ledger:   ledger,
+		service: NewBookingService(
+			bookingRepo,
+			tripRepo,
+			userRepo,
+			promoRepo,
+			ledger,
+			policy,
+			cancellation,
+			pricing,
+			promo,
+			notifier,
+			waitlist,
+			db,
+			quiet,
+		),
+	}
+}
+
+// round прогоняет одну поездку A → M → B: пассажиры по очереди берут весь маршрут
+// и каждый из двух перегонов, а водитель параллельно подтверждает все заявки дважды
+func (s *seatStress) round(seats, passengers, perBooking int) error {
+	stamp := time.Now().UnixNano()
+
+	driver := &models.User{Name: "stress driver", Phone: fmt.Sprintf("+d%d", stamp%1e15), Role: constants.RoleDriver}
+	if err := s.users.Create(driver); err != nil {
+		return fmt.Errorf("create driver: %w", err)
+	}
+
+	car := &models.Car{OwnerID: driver.ID, Brand: "stress", CarModel: "stress", Seats: seats}
+	if err := s.cars.Create(car); err != nil {
+		return fmt.Errorf("create car: %w", err)
+	}
+
+	trip := &models.Trip{
+		DriverID:       driver.ID,
+		CarID:          car.ID,
+		FromCity:       "A",
+		ToCity:         "B",
+		StartTime:      time.Now().Add(72 * time.Hour),
+		DurationMin:    60,
+		TotalSeats:     seats,
+		AvailableSeats: seats,
+		Price:          100,
+		TripStatus:     string(constants.TripPublished),
+	}
+
+	var err error
+	middle := []dto.TripStopRequest{{City: "M", ArrivalTime: trip.StartTime.Add(30 * time.Minute)}}
+	if trip.Stops, err = buildTripStops(trip, middle, nil); err != nil {
+		return fmt.Errorf("build stops: %w", err)
+	}
+	if err := s.trips.Create(trip); err != nil {
+		return fmt.Errorf("create trip: %w", err)
+	}
+
+	segments := [][2]int{{0, 2}, {0, 1}, {1, 2}}
+
+	ids := make([]uint, 0, passengers)
+	for i := 0; i < passengers; i++ {
+		p := &models.User{Name: "stress passenger", Phone: fmt.Sprintf("+p%d%03d", stamp%1e12, i), Role: constants.RolePassenger}
+		if err := s.users.Create(p); err != nil {
+			return fmt.Errorf("create passenger: %w", err)
+		}
+		if err := s.users.AdjustBalance(p.ID, trip.Price*perBooking); err != nil {
+			return fmt.Errorf("fund passenger: %w", err)
+		}
+
+		segment := segments[i%len(segments)]
+		b, err := s.service.Create(p.ID, &dto.BookingCreateRequest{
+			TripID:   trip.ID,
+			Seats:    perBooking,
+			FromStop: &segment[0],
+			ToStop:   &segment[1],
+		})
+		if err != nil {
+			return fmt.Errorf("create booking: %w", err)
+		}
+		ids = append(ids, b.ID)
+	}
+
+	// каждую заявку подтверждаем дважды, чтобы заодно проверить повторное подтверждение
+	var (
+		wg       sync.WaitGroup
+		start    = make(chan struct{})
+		mu       sync.Mutex
+		approved = map[uint]int{}
+	)
+	for _, id := range append(ids, ids...) {
+		wg.Add(1)
+		go func(id uint) {
+			defer wg.Done()
+			<-start
+			if err := s.service.Approve(id, driver.ID); err == nil {
+				mu.Lock()
+				approved[id]++
+				mu.Unlock()
+			}
+		}(id)
+	}
+	close(start)
+	wg.Wait()
+
+	for id, n := range approved {
+		if n > 1 {
+			return fmt.Errorf("booking %d approved %d times", id, n)
+		}
+	}
+
+	return s.check(trip.ID, ids, len(approved))
+}
+
+// check сверяет поездку после прогона: загрузку каждого перегона, свободные места,
+// отказы при наличии мест и удержания на кошельках
+func (s *seatStress) check(tripID uint, ids []uint, reported int) error {
+	after, err := s.trips.GetByID(tripID)
+	if err != nil {
+		return fmt.Errorf("reload trip: %w", err)
+	}
+
+	stored, err := s.bookings.ListByTripAndStatus(tripID, constants.BookingApproved)
+	if err != nil {
+		return fmt.Errorf("list approved: %w", err)
+	}
+	if len(stored) != reported {
+		return fmt.Errorf("approved in db %d, reported %d", len(stored), reported)
+	}
+
+	// перегон i — между остановками i и i+1, его загрузка хранится на остановке i
+	legs := make(map[int]int)
+	for _, b := range stored {
+		for pos := b.FromStop; pos < b.ToStop; pos++ {
+			legs[pos] += b.Seats
+		}
+	}
+
+	busiest := 0
+	for _, stop := range after.Stops[:len(after.Stops)-1] {
+		switch {
+		case stop.BookedSeats != legs[stop.Position]:
+			return fmt.Errorf("leg %d: booked_seats %d, approved bookings %d", stop.Position, stop.BookedSeats, legs[stop.Position])
+		case stop.BookedSeats > after.TotalSeats:
+			return fmt.Errorf("leg %d: booked_seats %d > total %d", stop.Position, stop.BookedSeats, after.TotalSeats)
+		}
+		busiest = max(busiest, stop.BookedSeats)
+	}
+
+	if after.AvailableSeats != after.TotalSeats-busiest {
+		return fmt.Errorf("available_seats %d, expected %d", after.AvailableSeats, after.TotalSeats-busiest)
+	}
+
+	isApproved := make(map[uint]bool, len(stored))
+	held, expected := 0, 0
+	for _, b := range stored {
+		isApproved[b.ID] = true
+		expected += bookingPrice(&b, after)
+	}
+
+	for _, id := range ids {
+		amount, err := s.ledger.HeldAmount(id)
+		if err != nil {
+			return fmt.Errorf("held amount of booking %d: %w", id, err)
+		}
+		held += amount
+
+		if isApproved[id] {
+			continue
+		}
+		if amount != 0 {
+			return fmt.Errorf("booking %d is not approved but holds %d", id, amount)
+		}
+
+		// места в этом прогоне только занимаются, поэтому отказ при свободном участке — потерянная продажа
+		b, err := s.bookings.GetByID(id)
+		if err != nil {
+			return fmt.Errorf("reload booking %d: %w", id, err)
+		}
+		if segmentFree(after, b.FromStop, b.ToStop) >= b.Seats {
+			return fmt.Errorf("booking %d refused while its segment had %d free seats", id, segmentFree(after, b.FromStop, b.ToStop))
+		}
+	}
+
+	if held != expected {
+		return fmt.Errorf("ledger holds %d, approved bookings cost %d", held, expected)
+	}
+
+	return nil
+}
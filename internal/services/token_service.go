@@ -0,0 +1,142 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token expired")
+)
+
+const (
+	tokenTypeAccess  = "access"
+	tokenTypeRefresh = "refresh"
+)
+
+type TokenService interface {
+	Issue(userID uint) (*dto.TokenPair, error)
+
+	ParseAccess(token string) (uint, error)
+
+	ParseRefresh(token string) (uint, error)
+}
+
+type tokenClaims struct {
+	Subject   uint   `json:"sub"`
+	Type      string `json:"typ"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// hmacTokenService подписывает токены HMAC-SHA256: base64(claims).base64(signature)
+type hmacTokenService struct {
+	secret     []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	now        func() time.Time
+}
+
+func NewTokenService(secret []byte, accessTTL, refreshTTL time.Duration) TokenService {
+	return &hmacTokenService{
+		secret:     secret,
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+		now:        time.Now,
+	}
+}
+
+func (s *hmacTokenService) Issue(userID uint) (*dto.TokenPair, error) {
+	now := s.now().UTC()
+
+	access, err := s.sign(tokenClaims{
+		Subject:   userID,
+		Type:      tokenTypeAccess,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(s.accessTTL).Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	refresh, err := s.sign(tokenClaims{
+		Subject:   userID,
+		Type:      tokenTypeRefresh,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(s.refreshTTL).Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		ExpiresIn:    int64(s.accessTTL.Seconds()),
+	}, nil
+}
+
+func (s *hmacTokenService) ParseAccess(token string) (uint, error) {
+	return s.parse(token, tokenTypeAccess)
+}
+
+func (s *hmacTokenService) ParseRefresh(token string) (uint, error) {
+	return s.parse(token, tokenTypeRefresh)
+}
+
+func (s *hmacTokenService) sign(claims tokenClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.mac(encoded)), nil
+}
+
+func (s *hmacTokenService) parse(token, typ string) (uint, error) {
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return 0, ErrInvalidToken
+	}
+
+	gotSig, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(gotSig, s.mac(encoded)) {
+		return 0, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return 0, ErrInvalidToken
+	}
+
+	var claims tokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return 0, ErrInvalidToken
+	}
+
+	if claims.Type != typ || claims.Subject == 0 {
+		return 0, ErrInvalidToken
+	}
+
+	if s.now().UTC().Unix() >= claims.ExpiresAt {
+		return 0, ErrTokenExpired
+	}
+
+	return claims.Subject, nil
+}
+
+func (s *hmacTokenService) mac(data string) []byte {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
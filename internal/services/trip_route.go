@@ -0,0 +1,133 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+)
+
+var (
+	ErrInvalidStops   = errors.New("invalid trip stops")
+	ErrInvalidSegment = errors.New("invalid route segment")
+)
+
+// buildTripStops собирает маршрут поездки: отправление, промежуточные остановки и прибытие.
+// Время, расстояние и тариф остановок должны возрастать внутри поездки.
+func buildTripStops(trip *models.Trip, intermediate []dto.TripStopRequest, distanceKm *int) ([]models.TripStop, error) {
+	arrival := trip.StartTime.Add(time.Duration(trip.DurationMin) * time.Minute)
+
+	zero := 0
+	stops := make([]models.TripStop, 0, len(intermediate)+2)
+	stops = append(stops, models.TripStop{
+		Position:    0,
+		City:        trip.FromCity,
+		CityID:      trip.FromCityID,
+		Lat:         trip.FromLat,
+		Lng:         trip.FromLng,
+		ArrivalTime: trip.StartTime,
+		DistanceKm:  &zero,
+	})
+
+	prev := trip.StartTime
+	prevDistance, prevFare := 0, 0
+	for i, s := range intermediate {
+		if !s.ArrivalTime.After(prev) || !s.ArrivalTime.Before(arrival) {
+			return nil, ErrInvalidStops
+		}
+		prev = s.ArrivalTime
+
+		if s.DistanceKm != nil {
+			if *s.DistanceKm <= prevDistance || (distanceKm != nil && *s.DistanceKm >= *distanceKm) {
+				return nil, ErrInvalidStops
+			}
+			prevDistance = *s.DistanceKm
+		}
+		if s.Fare != nil {
+			if *s.Fare < prevFare || *s.Fare > trip.Price {
+				return nil, ErrInvalidStops
+			}
+			prevFare = *s.Fare
+		}
+
+		stop := models.TripStop{
+			Position:    i + 1,
+			City:        s.City,
+			CityID:      s.CityID,
+			ArrivalTime: s.ArrivalTime,
+			DistanceKm:  s.DistanceKm,
+			Fare:        s.Fare,
+		}
+		stop.Lat, stop.Lng = pointCoords(s.Point)
+		stops = append(stops, stop)
+	}
+
+	stops = append(stops, models.TripStop{
+		Position:    len(intermediate) + 1,
+		City:        trip.ToCity,
+		CityID:      trip.ToCityID,
+		Lat:         trip.ToLat,
+		Lng:         trip.ToLng,
+		ArrivalTime: arrival,
+		DistanceKm:  distanceKm,
+	})
+
+	return stops, nil
+}
+
+// pointCoords раскладывает точку на широту и долготу для хранения; nil — точка не задана
+func pointCoords(p *dto.GeoPoint) (*float64, *float64) {
+	if p == nil {
+		return nil, nil
+	}
+	lat, lng := p.Lat, p.Lng
+	return &lat, &lng
+}
+
+func samePoint(lat, lng *float64, p *dto.GeoPoint) bool {
+	return lat != nil && lng != nil && *lat == p.Lat && *lng == p.Lng
+}
+
+// lastStop — позиция конечной остановки; у поездок без остановок маршрут из двух точек
+func lastStop(trip *models.Trip) int {
+	if len(trip.Stops) == 0 {
+		return 1
+	}
+	return trip.Stops[len(trip.Stops)-1].Position
+}
+
+// resolveSegment проверяет участок посадки и высадки; по умолчанию — весь маршрут
+func resolveSegment(trip *models.Trip, from, to *int) (int, int, error) {
+	start, end := 0, lastStop(trip)
+
+	if from != nil {
+		start = *from
+	}
+	if to != nil {
+		end = *to
+	}
+
+	if start < 0 || end > lastStop(trip) || start >= end {
+		return 0, 0, ErrInvalidSegment
+	}
+
+	return start, end, nil
+}
+
+// segmentFree — сколько мест свободно на участке [from, to): столько, сколько осталось
+// на самом загруженном перегоне
+func segmentFree(trip *models.Trip, from, to int) int {
+	if len(trip.Stops) == 0 {
+		return trip.AvailableSeats
+	}
+
+	booked := 0
+	for _, s := range trip.Stops {
+		if s.Position >= from && s.Position < to && s.BookedSeats > booked {
+			booked = s.BookedSeats
+		}
+	}
+
+	return trip.TotalSeats - booked
+}
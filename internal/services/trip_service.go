@@ -1,43 +1,98 @@
 package services
 
 import (
+	"errors"
 	"log/slog"
+	"time"
 
 	"github.com/mutsaevz/team-5-ambitious/internal/constants"
 	"github.com/mutsaevz/team-5-ambitious/internal/dto"
 	"github.com/mutsaevz/team-5-ambitious/internal/models"
 	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTripNotEditable   = errors.New("trip can no longer be edited")
+	ErrTripHasPassengers = errors.New("route and price cannot change after bookings are approved")
 )
 
 type TripService interface {
 	Create(driverID uint, req *dto.TripCreateRequest) (*models.Trip, error)
 
-	List(filter dto.TripFilter) ([]models.Trip, error)
+	List(filter dto.TripFilter) (*dto.TripListResponse, error)
 
 	GetByID(id uint) (*models.Trip, error)
 
-	Update(id uint, req dto.TripUpdateRequest) (*models.Trip, error)
+	Update(actorID, id uint, req dto.TripUpdateRequest) (*models.Trip, error)
+
+	Delete(actorID, id uint) error
+
+	// Start отправляет поездку раньше или позже планового времени
+	Start(actorID, id uint) (*models.Trip, error)
 
-	Delete(id uint) error
+	// Finish завершает поездку по команде водителя
+	Finish(actorID, id uint) (*models.Trip, error)
+
+	// Cancel отменяет поездку и все её брони с полным возвратом денег
+	Cancel(actorID, id uint, reason string) (*models.Trip, error)
+
+	// Complete завершает поездку от имени системы и рассчитывается с водителем по одобренным броням
+	Complete(id uint) error
 }
 
 type tripService struct {
-	tripRepo repository.TripRepository
-	userRepo repository.UserRepository
-	carRepo  repository.CarRepository
-	logger   *slog.Logger
+	tripRepo    repository.TripRepository
+	userRepo    repository.UserRepository
+	carRepo     repository.CarRepository
+	bookingRepo repository.BookingRepository
+	ledger      LedgerService
+	policy      Policy
+	waitlist    WaitlistService
+	referral    ReferralService
+	cities      CityService
+	matcher     TripMatcher
+	machine     *bookingStateMachine
+	notifier    Notifier
+	// materialShift — порог существенного изменения времени поездки
+	materialShift time.Duration
+	db            *gorm.DB
+	logger        *slog.Logger
 }
 
 func NewTripService(
 	tripRepo repository.TripRepository,
 	userRepo repository.UserRepository,
 	carRepo repository.CarRepository,
+	bookingRepo repository.BookingRepository,
+	promoRepo repository.PromoRepository,
+	ledger LedgerService,
+	policy Policy,
+	cancellation CancellationPolicy,
+	notifier Notifier,
+	waitlist WaitlistService,
+	referral ReferralService,
+	cities CityService,
+	matcher TripMatcher,
+	materialShift time.Duration,
+	db *gorm.DB,
 	logger *slog.Logger) TripService {
 	return &tripService{
-		tripRepo: tripRepo,
-		userRepo: userRepo,
-		carRepo:  carRepo,
-		logger:   logger,
+		tripRepo:      tripRepo,
+		userRepo:      userRepo,
+		carRepo:       carRepo,
+		bookingRepo:   bookingRepo,
+		ledger:        ledger,
+		policy:        policy,
+		waitlist:      waitlist,
+		referral:      referral,
+		cities:        cities,
+		matcher:       matcher,
+		machine:       newBookingStateMachine(bookingRepo, tripRepo, promoRepo, ledger, cancellation, logger),
+		notifier:      notifier,
+		materialShift: materialShift,
+		db:            db,
+		logger:        logger,
 	}
 }
 
@@ -48,35 +103,131 @@ func (s *tripService) Create(id uint, req *dto.TripCreateRequest) (*models.Trip,
 		return nil, err
 	}
 
-	car, err := s.carRepo.GetByOwner(id)
+	car, err := driverCar(s.carRepo, id, req.CarID)
 
 	if err != nil {
 		return nil, err
 	}
 
+	mode := req.BookingMode
+	if mode == "" {
+		mode = constants.BookingModeManual
+	}
+
+	// водитель может предложить не все места автомобиля; по умолчанию — все
+	seats := req.AvailableSeats
+	if seats == 0 {
+		seats = car.Seats
+	}
+	if seats > car.Seats {
+		return nil, ErrInvalidSeatsCount
+	}
+
 	var trip = models.Trip{
-		DriverID:       driver.ID,
-		CarID:          car.ID,
-		FromCity:       req.FromCity,
-		ToCity:         req.ToCity,
-		StartTime:      req.StartTime,
-		DurationMin:    req.DurationMin,
-		TotalSeats:     car.Seats,
-		AvailableSeats: req.AvailableSeats,
-		Price:          req.Price,
-		TripStatus:     string(constants.TripPublished),
-		AvgRating:      0,
+		DriverID:          driver.ID,
+		CarID:             car.ID,
+		FromCity:          req.FromCity,
+		ToCity:            req.ToCity,
+		StartTime:         req.StartTime,
+		DurationMin:       req.DurationMin,
+		TotalSeats:        seats,
+		AvailableSeats:    seats,
+		Price:             req.Price,
+		TripStatus:        string(constants.TripPublished),
+		BookingMode:       mode,
+		PendingTimeoutMin: req.PendingTimeoutMin,
+		AvgRating:         0,
+	}
+	trip.FromLat, trip.FromLng = pointCoords(req.FromPoint)
+	trip.ToLat, trip.ToLng = pointCoords(req.ToPoint)
+
+	if err := s.policy.Authorize(id, ActionCreate, &trip); err != nil {
+		return nil, err
+	}
+
+	if err := checkVerified(driver, car, time.Now()); err != nil {
+		return nil, err
+	}
+
+	// города маршрута приводятся к справочнику
+	if trip.FromCityID, err = resolveCity(s.cities, &trip.FromCity); err != nil {
+		return nil, err
+	}
+	if trip.ToCityID, err = resolveCity(s.cities, &trip.ToCity); err != nil {
+		return nil, err
+	}
+	for i := range req.Stops {
+		if req.Stops[i].CityID, err = resolveCity(s.cities, &req.Stops[i].City); err != nil {
+			return nil, err
+		}
+	}
+
+	// остановки сохраняются вместе с поездкой как ассоциация
+	if trip.Stops, err = buildTripStops(&trip, req.Stops, req.DistanceKm); err != nil {
+		return nil, err
 	}
 
 	if err := s.tripRepo.Create(&trip); err != nil {
 		return nil, err
 	}
 
+	// поездка уже опубликована: ошибка рассылки подписчикам не отменяет её создание
+	if err := s.matcher.Match(&trip); err != nil {
+		s.logger.Error("failed to match saved searches",
+			slog.Uint64("trip_id", uint64(trip.ID)),
+			slog.Any("error", err),
+		)
+	}
+
 	return &trip, nil
 }
 
-func (s *tripService) List(filter dto.TripFilter) ([]models.Trip, error) {
-	return s.tripRepo.List(filter)
+func (s *tripService) List(filter dto.TripFilter) (*dto.TripListResponse, error) {
+	var err error
+
+	if filter.FromCityID, err = s.searchCity(filter.FromCity); err != nil {
+		return nil, err
+	}
+	if filter.ToCityID, err = s.searchCity(filter.ToCity); err != nil {
+		return nil, err
+	}
+
+	list, total, err := s.tripRepo.List(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := markVerifiedDrivers(s.userRepo, list.Items, time.Now()); err != nil {
+		return nil, err
+	}
+
+	page, pageSize := repository.NormalizePage(filter.Page, filter.PageSize)
+
+	return &dto.TripListResponse{
+		Items:      list.Items,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		NextCursor: list.NextCursor,
+	}, nil
+}
+
+// searchCity — город справочника для поиска; города, которого в справочнике нет,
+// ищутся по названию как есть
+func (s *tripService) searchCity(name *string) (*uint, error) {
+	if name == nil {
+		return nil, nil
+	}
+
+	city, err := s.cities.Resolve(*name)
+	if err != nil {
+		if errors.Is(err, ErrUnknownCity) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &city.ID, nil
 }
 
 func (s *tripService) GetByID(id uint) (*models.Trip, error) {
@@ -89,10 +240,16 @@ func (s *tripService) GetByID(id uint) (*models.Trip, error) {
 		return nil, err
 	}
 
+	trips := []models.Trip{*trip}
+	if err := markVerifiedDrivers(s.userRepo, trips, time.Now()); err != nil {
+		return nil, err
+	}
+	trip.DriverVerified = trips[0].DriverVerified
+
 	return trip, nil
 }
 
-func (s *tripService) Update(id uint, req dto.TripUpdateRequest) (*models.Trip, error) {
+func (s *tripService) Update(actorID, id uint, req dto.TripUpdateRequest) (*models.Trip, error) {
 	trip, err := s.tripRepo.GetByID(id)
 	if err != nil {
 		s.logger.Error("trip not found for update",
@@ -102,41 +259,230 @@ func (s *tripService) Update(id uint, req dto.TripUpdateRequest) (*models.Trip,
 		return nil, err
 	}
 
+	if err := s.policy.Authorize(actorID, ActionUpdate, trip); err != nil {
+		return nil, err
+	}
+
+	// названия заменяются каноническими до сравнения с текущим маршрутом
+	fromCityID, err := resolveCity(s.cities, req.FromCity)
+	if err != nil {
+		return nil, err
+	}
+	toCityID, err := resolveCity(s.cities, req.ToCity)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		notifications []Notification
+		seatsBefore   int
+	)
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		tripRepo := s.tripRepo.WithDB(tx)
+
+		// порядок блокировок как при переходах броней: сначала брони, потом поездка
+		bookings, err := s.bookingRepo.WithDB(tx).ListActiveByTripForUpdate(id)
+		if err != nil {
+			return err
+		}
+
+		trip, err = tripRepo.GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+
+		if trip.TripStatus != string(constants.TripPublished) {
+			return ErrTripNotEditable
+		}
+
+		if err := s.guardEdit(trip, bookings, &req); err != nil {
+			return err
+		}
+
+		before := *trip
+		seatsBefore = trip.AvailableSeats
+
+		// неизвестный справочнику город остаётся без ID, а не со старым
+		if req.FromCity != nil {
+			trip.FromCityID = fromCityID
+		}
+		if req.ToCity != nil {
+			trip.ToCityID = toCityID
+		}
+
+		if err := s.applyEdit(trip, &req); err != nil {
+			return err
+		}
+
+		if err := tripRepo.Update(trip); err != nil {
+			return err
+		}
+
+		if len(trip.Stops) > 0 && trip.TotalSeats != before.TotalSeats {
+			if trip.AvailableSeats, err = tripRepo.SyncAvailableSeats(trip.ID); err != nil {
+				return err
+			}
+		}
+
+		if !s.isMaterialChange(&before, trip) || len(bookings) == 0 {
+			return nil
+		}
+
+		// существенное изменение: пассажиры могут отказаться от поездки без штрафа
+		ids := make([]uint, 0, len(bookings))
+		for i := range bookings {
+			ids = append(ids, bookings[i].ID)
+			notifications = append(notifications, Notification{
+				UserID:    bookings[i].PassengerID,
+				Event:     "trip_changed",
+				Message:   "Время поездки изменилось, бронь можно отменить без штрафа",
+				TripID:    &trip.ID,
+				BookingID: &bookings[i].ID,
+			})
+		}
+
+		return s.bookingRepo.WithDB(tx).MarkPenaltyFree(ids)
+	})
+	if err != nil {
+		s.logger.Error("failed to update trip",
+			slog.Uint64("trip_id", uint64(id)),
+			slog.Any("error", err),
+		)
+		return nil, err
+	}
+
+	dispatch(s.notifier, s.logger, notifications)
+
+	// добавленные места в первую очередь получают ожидающие в листе ожидания
+	if trip.AvailableSeats > seatsBefore {
+		if err := s.waitlist.Promote(trip.ID); err != nil {
+			s.logger.Error("failed to promote waitlist",
+				slog.Uint64("trip_id", uint64(trip.ID)),
+				slog.Any("error", err),
+			)
+		}
+	}
+
+	return trip, nil
+}
+
+// guardEdit запрещает менять маршрут и цену, когда в поездке уже есть одобренные пассажиры
+func (s *tripService) guardEdit(trip *models.Trip, bookings []models.Booking, req *dto.TripUpdateRequest) error {
+	approved := false
+	for i := range bookings {
+		if bookings[i].BookingStatus == constants.BookingApproved {
+			approved = true
+			break
+		}
+	}
+	if !approved {
+		return nil
+	}
+
+	if (req.FromCity != nil && *req.FromCity != trip.FromCity) ||
+		(req.ToCity != nil && *req.ToCity != trip.ToCity) ||
+		(req.FromPoint != nil && !samePoint(trip.FromLat, trip.FromLng, req.FromPoint)) ||
+		(req.ToPoint != nil && !samePoint(trip.ToLat, trip.ToLng, req.ToPoint)) ||
+		(req.Price != nil && *req.Price != trip.Price) {
+		return ErrTripHasPassengers
+	}
+
+	return nil
+}
+
+// applyEdit переносит изменения на поездку. Свободные места задаются поверх уже занятых:
+// вместимость поездки становится занято + свободно и не может превышать места автомобиля
+func (s *tripService) applyEdit(trip *models.Trip, req *dto.TripUpdateRequest) error {
 	if req.FromCity != nil {
 		trip.FromCity = *req.FromCity
 	}
 	if req.ToCity != nil {
 		trip.ToCity = *req.ToCity
 	}
-	if req.StartTime != nil {
-		trip.StartTime = *req.StartTime
-	}
-	if req.DurationMin != nil {
-		trip.DurationMin = *req.DurationMin
+	if req.FromPoint != nil {
+		trip.FromLat, trip.FromLng = pointCoords(req.FromPoint)
 	}
-	if req.AvailableSeats != nil {
-		trip.AvailableSeats = *req.AvailableSeats
+	if req.ToPoint != nil {
+		trip.ToLat, trip.ToLng = pointCoords(req.ToPoint)
 	}
 	if req.Price != nil {
 		trip.Price = *req.Price
 	}
-	if req.TripStatus != nil {
-		trip.TripStatus = string(*req.TripStatus)
+	if req.BookingMode != nil {
+		trip.BookingMode = *req.BookingMode
+	}
+	if req.PendingTimeoutMin != nil {
+		trip.PendingTimeoutMin = *req.PendingTimeoutMin
 	}
 
-	if err := s.tripRepo.Update(trip); err != nil {
-		s.logger.Error("failed to update trip",
-			slog.Uint64("trip_id", uint64(id)),
-			slog.Any("error", err),
-		)
-		return nil, err
+	if req.StartTime != nil || req.DurationMin != nil {
+		shift := time.Duration(0)
+		if req.StartTime != nil {
+			shift = req.StartTime.Sub(trip.StartTime)
+			trip.StartTime = *req.StartTime
+		}
+		if req.DurationMin != nil {
+			trip.DurationMin = *req.DurationMin
+		}
+
+		// промежуточные остановки сдвигаются вместе с отправлением и должны успеть до прибытия;
+		// в базе их сдвигает tripRepo.Update, здесь — чтобы ответ совпадал с сохранённым
+		arrival := trip.StartTime.Add(time.Duration(trip.DurationMin) * time.Minute)
+		for i := 1; i < len(trip.Stops)-1; i++ {
+			trip.Stops[i].ArrivalTime = trip.Stops[i].ArrivalTime.Add(shift)
+			if !trip.Stops[i].ArrivalTime.Before(arrival) {
+				return ErrInvalidStops
+			}
+		}
 	}
 
-	return trip, nil
+	if n := len(trip.Stops); n > 0 {
+		trip.Stops[0].City, trip.Stops[0].CityID, trip.Stops[0].ArrivalTime = trip.FromCity, trip.FromCityID, trip.StartTime
+		trip.Stops[0].Lat, trip.Stops[0].Lng = trip.FromLat, trip.FromLng
+		trip.Stops[n-1].City, trip.Stops[n-1].CityID = trip.ToCity, trip.ToCityID
+		trip.Stops[n-1].Lat, trip.Stops[n-1].Lng = trip.ToLat, trip.ToLng
+		trip.Stops[n-1].ArrivalTime = trip.StartTime.Add(time.Duration(trip.DurationMin) * time.Minute)
+	}
+
+	if req.AvailableSeats != nil {
+		car, err := s.carRepo.GetByID(trip.CarID)
+		if err != nil {
+			return err
+		}
+
+		// поездка без единого места нарушила бы check total_seats > 0
+		booked := trip.TotalSeats - trip.AvailableSeats
+		if booked+*req.AvailableSeats < 1 || booked+*req.AvailableSeats > car.Seats {
+			return ErrInvalidSeatsCount
+		}
+
+		trip.TotalSeats = booked + *req.AvailableSeats
+		trip.AvailableSeats = *req.AvailableSeats
+	}
+
+	return nil
+}
+
+// isMaterialChange — отправление или прибытие сдвинулись не меньше чем на порог
+func (s *tripService) isMaterialChange(before, after *models.Trip) bool {
+	arrival := func(t *models.Trip) time.Time {
+		return t.StartTime.Add(time.Duration(t.DurationMin) * time.Minute)
+	}
+
+	return absDuration(after.StartTime.Sub(before.StartTime)) >= s.materialShift ||
+		absDuration(arrival(after).Sub(arrival(before))) >= s.materialShift
 }
 
-func (s *tripService) Delete(id uint) error {
-	_, err := s.tripRepo.GetByID(id)
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+func (s *tripService) Delete(actorID, id uint) error {
+	trip, err := s.tripRepo.GetByID(id)
 	if err != nil {
 		s.logger.Error("trip not found for delete",
 			slog.Uint64("trip_id", uint64(id)),
@@ -145,7 +491,32 @@ func (s *tripService) Delete(id uint) error {
 		return err
 	}
 
-	if err := s.tripRepo.Delete(id); err != nil {
+	if err := s.policy.Authorize(actorID, ActionDelete, trip); err != nil {
+		return err
+	}
+
+	// поездку с пассажирами можно только отменить: удаление не вернёт деньги и не освободит места
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		tripRepo := s.tripRepo.WithDB(tx)
+
+		if _, err := s.bookingRepo.WithDB(tx).ListActiveByTripForUpdate(id); err != nil {
+			return err
+		}
+		if _, err := tripRepo.GetByIDForUpdate(id); err != nil {
+			return err
+		}
+
+		active, err := tripRepo.HasActiveBookings(id)
+		if err != nil {
+			return err
+		}
+		if active {
+			return ErrTripHasPassengers
+		}
+
+		return tripRepo.Delete(id)
+	})
+	if err != nil {
 		s.logger.Error("failed to delete trip",
 			slog.Uint64("trip_id", uint64(id)),
 			slog.Any("error", err),
@@ -155,3 +526,170 @@ func (s *tripService) Delete(id uint) error {
 
 	return nil
 }
+
+func (s *tripService) Start(actorID, id uint) (*models.Trip, error) {
+	return s.lifecycle(actorID, id, func(tx *gorm.DB, trip *models.Trip, actor bookingActor) error {
+		return transitionTrip(s.tripRepo.WithDB(tx), s.logger, trip, constants.TripInProgress, actor, time.Now().UTC())
+	})
+}
+
+func (s *tripService) Finish(actorID, id uint) (*models.Trip, error) {
+	return s.lifecycle(actorID, id, func(tx *gorm.DB, trip *models.Trip, actor bookingActor) error {
+		return s.complete(tx, trip, actor)
+	})
+}
+
+func (s *tripService) Cancel(actorID, id uint, reason string) (*models.Trip, error) {
+	trip, err := s.tripRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.policy.Authorize(actorID, ActionManage, trip); err != nil {
+		return nil, err
+	}
+
+	var notifications []Notification
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// порядок блокировок как при переходах броней: сначала брони, потом поездка
+		bookings, err := s.bookingRepo.WithDB(tx).ListActiveByTripForUpdate(id)
+		if err != nil {
+			return err
+		}
+
+		trip, err = s.tripRepo.WithDB(tx).GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+
+		trip.CancelReason = reason
+		if err := transitionTrip(s.tripRepo.WithDB(tx), s.logger, trip, constants.TripCancelled, resolveTripActor(actorID, trip), time.Now().UTC()); err != nil {
+			return err
+		}
+
+		// брони отменяются от имени системы: политика отмены не применяется, деньги возвращаются полностью
+		for i := range bookings {
+			result, err := s.machine.Transition(tx, &bookings[i], trip, constants.BookingCancelledByDriver, actorSystem)
+			if err != nil {
+				return err
+			}
+			notifications = append(notifications, result.notifications...)
+			notifications = append(notifications, Notification{
+				UserID:    bookings[i].PassengerID,
+				Event:     "trip_cancelled",
+				Message:   "Поездка отменена водителем: " + reason,
+				TripID:    &trip.ID,
+				BookingID: &bookings[i].ID,
+			})
+		}
+
+		closed, err := s.waitlist.CloseTrip(tx, trip.ID)
+		if err != nil {
+			return err
+		}
+		notifications = append(notifications, closed...)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dispatch(s.notifier, s.logger, notifications)
+	return trip, nil
+}
+
+// lifecycle выполняет команду водителя над поездкой в транзакции с блокировкой поездки
+func (s *tripService) lifecycle(
+	actorID, id uint,
+	apply func(tx *gorm.DB, trip *models.Trip, actor bookingActor) error,
+) (*models.Trip, error) {
+	trip, err := s.tripRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.policy.Authorize(actorID, ActionManage, trip); err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		locked, err := s.tripRepo.WithDB(tx).GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+
+		trip = locked
+		return apply(tx, trip, resolveTripActor(actorID, trip))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return trip, nil
+}
+
+func (s *tripService) Complete(id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		trip, err := s.tripRepo.WithDB(tx).GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+
+		if trip.TripStatus != string(constants.TripInProgress) {
+			// уже завершена водителем или параллельно
+			return nil
+		}
+
+		return s.complete(tx, trip, actorSystem)
+	})
+}
+
+// complete завершает поездку и рассчитывается с водителем по одобренным броням
+func (s *tripService) complete(tx *gorm.DB, trip *models.Trip, actor bookingActor) error {
+	bookingRepo := s.bookingRepo.WithDB(tx)
+	ledger := s.ledger.WithDB(tx)
+
+	if err := transitionTrip(s.tripRepo.WithDB(tx), s.logger, trip, constants.TripCompleted, actor, time.Now().UTC()); err != nil {
+		return err
+	}
+
+	bookings, err := bookingRepo.ListByTripAndStatus(trip.ID, constants.BookingApproved)
+	if err != nil {
+		return err
+	}
+
+	for i := range bookings {
+		// скидку по промокоду водителю доплачивает платформа
+		if err := ledger.Subsidize(&bookings[i], bookings[i].Discount); err != nil {
+			return err
+		}
+
+		held, err := ledger.HeldAmount(bookings[i].ID)
+		if err != nil {
+			return err
+		}
+
+		// комиссия зафиксирована в брони при создании
+		commission := min(bookings[i].Commission, held)
+		if err := ledger.Commission(&bookings[i], commission); err != nil {
+			return err
+		}
+
+		if err := ledger.Settle(&bookings[i], trip.DriverID, held-commission); err != nil {
+			return err
+		}
+
+		if err := s.referral.Reward(tx, &bookings[i]); err != nil {
+			return err
+		}
+	}
+
+	s.logger.Info("trip completed",
+		slog.Uint64("trip_id", uint64(trip.ID)),
+		slog.Int("settled_bookings", len(bookings)),
+	)
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+)
+
+var ErrInvalidTripTransition = errors.New("invalid trip status transition")
+
+// tripTransitions — единственное описание допустимых переходов поездки и того, кто их выполняет.
+// Роли те же, что у броней: система — воркер статусов поездок.
+var tripTransitions = map[constants.TripStatus]map[constants.TripStatus][]bookingActor{
+	constants.TripPublished: {
+		constants.TripInProgress: {actorDriver, actorAdmin, actorSystem},
+		constants.TripCancelled:  {actorDriver, actorAdmin},
+	},
+	constants.TripInProgress: {
+		constants.TripCompleted: {actorDriver, actorAdmin, actorSystem},
+	},
+}
+
+// resolveTripActor — роль пользователя, уже прошедшего проверку прав на поездку
+func resolveTripActor(actorID uint, trip *models.Trip) bookingActor {
+	if trip.DriverID == actorID {
+		return actorDriver
+	}
+	return actorAdmin
+}
+
+// transitionTrip переводит заблокированную поездку в статус to и сохраняет отметки времени
+func transitionTrip(
+	tripRepo repository.TripRepository,
+	logger *slog.Logger,
+	trip *models.Trip,
+	to constants.TripStatus,
+	actor bookingActor,
+	now time.Time,
+) error {
+	from := constants.TripStatus(trip.TripStatus)
+
+	if !slices.Contains(tripTransitions[from][to], actor) {
+		return fmt.Errorf("%w: %s -> %s by %s", ErrInvalidTripTransition, from, to, actor)
+	}
+
+	trip.TripStatus = string(to)
+
+	switch to {
+	case constants.TripInProgress:
+		trip.StartedAt = &now
+	case constants.TripCompleted, constants.TripCancelled:
+		trip.FinishedAt = &now
+	}
+
+	ok, err := tripRepo.SaveStatus(trip, from)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// статус успел смениться параллельно
+		return fmt.Errorf("%w: trip is no longer %s", ErrInvalidTripTransition, from)
+	}
+
+	logger.Info("trip transition",
+		slog.Uint64("trip_id", uint64(trip.ID)),
+		slog.String("from", string(from)),
+		slog.String("to", string(to)),
+		slog.String("actor", string(actor)),
+	)
+
+	return nil
+}
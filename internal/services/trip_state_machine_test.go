@@ -0,0 +1,57 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+)
+
+func TestTripTransitions(t *testing.T) {
+	cases := []struct {
+		from  constants.TripStatus
+		to    constants.TripStatus
+		actor bookingActor
+		want  bool
+	}{
+		{from: constants.TripPublished, to: constants.TripInProgress, actor: actorDriver, want: true},
+		{from: constants.TripPublished, to: constants.TripInProgress, actor: actorSystem, want: true},
+		{from: constants.TripPublished, to: constants.TripCancelled, actor: actorDriver, want: true},
+		{from: constants.TripPublished, to: constants.TripCancelled, actor: actorAdmin, want: true},
+		{from: constants.TripPublished, to: constants.TripCancelled, actor: actorSystem, want: false},
+		{from: constants.TripPublished, to: constants.TripCompleted, actor: actorDriver, want: false},
+		{from: constants.TripInProgress, to: constants.TripCompleted, actor: actorDriver, want: true},
+		{from: constants.TripInProgress, to: constants.TripCompleted, actor: actorSystem, want: true},
+		{from: constants.TripInProgress, to: constants.TripCancelled, actor: actorDriver, want: false},
+		{from: constants.TripCompleted, to: constants.TripPublished, actor: actorAdmin, want: false},
+		{from: constants.TripCancelled, to: constants.TripPublished, actor: actorDriver, want: false},
+		{from: constants.TripPublished, to: constants.TripInProgress, actor: actorPassenger, want: false},
+	}
+
+	for _, c := range cases {
+		t.Run(string(c.from)+"->"+string(c.to)+" by "+string(c.actor), func(t *testing.T) {
+			if got := slices.Contains(tripTransitions[c.from][c.to], c.actor); got != c.want {
+				t.Errorf("allowed = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestTransitionTripRejectsUnknown(t *testing.T) {
+	quiet := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	trip := &models.Trip{TripStatus: string(constants.TripCompleted)}
+
+	err := transitionTrip(nil, quiet, trip, constants.TripInProgress, actorDriver, time.Now())
+	if !errors.Is(err, ErrInvalidTripTransition) {
+		t.Fatalf("error = %v, want ErrInvalidTripTransition", err)
+	}
+	if trip.TripStatus != string(constants.TripCompleted) || trip.StartedAt != nil {
+		t.Errorf("trip changed: status %s, started_at %v", trip.TripStatus, trip.StartedAt)
+	}
+}
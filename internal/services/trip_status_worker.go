@@ -8,21 +8,28 @@ import (
 	"github.com/mutsaevz/team-5-ambitious/internal/repository"
 )
 
+// TripStatusWorker ведёт поездки по часам, если водитель не начал или не завершил их сам
 type TripStatusWorker struct {
-	repo   repository.TripRepository
-	logger *slog.Logger
-	tick   time.Duration
+	repo       repository.TripRepository
+	trips      TripService
+	startGrace time.Duration
+	logger     *slog.Logger
+	tick       time.Duration
 }
 
 func NewTripStatusWorker(
 	repo repository.TripRepository,
+	trips TripService,
+	startGrace time.Duration,
 	logger *slog.Logger,
 	tick time.Duration,
 ) *TripStatusWorker {
 	return &TripStatusWorker{
-		repo:   repo,
-		logger: logger,
-		tick:   tick,
+		repo:       repo,
+		trips:      trips,
+		startGrace: startGrace,
+		logger:     logger,
+		tick:       tick,
 	}
 }
 
@@ -39,14 +46,37 @@ func (w *TripStatusWorker) Start(ctx context.Context) {
 				return
 
 			case <-ticker.C:
-				now := time.Now().UTC()
-				if err := w.repo.UpdateTripStatuses(now); err != nil {
-					w.logger.Error(
-						"failed to update trip statuses",
-						slog.Any("error", err),
-					)
-				}
+				w.run(time.Now().UTC())
 			}
 		}
 	}()
 }
+
+func (w *TripStatusWorker) run(now time.Time) {
+	if err := w.repo.StartDueTrips(now.Add(-w.startGrace)); err != nil {
+		w.logger.Error(
+			"failed to update trip statuses",
+			slog.Any("error", err),
+		)
+		return
+	}
+
+	due, err := w.repo.ListDueForCompletion(now)
+	if err != nil {
+		w.logger.Error(
+			"failed to list trips for completion",
+			slog.Any("error", err),
+		)
+		return
+	}
+
+	for _, trip := range due {
+		if err := w.trips.Complete(trip.ID); err != nil {
+			w.logger.Error(
+				"failed to complete trip",
+				slog.Uint64("trip_id", uint64(trip.ID)),
+				slog.Any("error", err),
+			)
+		}
+	}
+}
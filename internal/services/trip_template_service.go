@@ -0,0 +1,602 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidSchedule = errors.New("invalid trip schedule")
+
+const (
+	templateDateLayout = "2006-01-02"
+	templateTimeLayout = "15:04"
+	defaultTimezone    = "Europe/Moscow"
+)
+
+type TripTemplateService interface {
+	Create(driverID uint, req *dto.TripTemplateCreateRequest) (*models.TripTemplate, error)
+
+	GetByID(id uint) (*models.TripTemplate, error)
+
+	ListByDriver(driverID uint) ([]models.TripTemplate, error)
+
+	// Update меняет шаблон и переносит изменения на будущие поездки без броней
+	Update(actorID, id uint, req *dto.TripTemplateUpdateRequest) (*models.TripTemplate, error)
+
+	// Cancel отключает шаблон и удаляет будущие поездки без броней
+	Cancel(actorID, id uint) error
+
+	// Materialize создаёт поездки по шаблону на горизонт планирования вперёд
+	Materialize(templateID uint, now time.Time) (int, error)
+}
+
+type tripTemplateService struct {
+	templateRepo repository.TripTemplateRepository
+	tripRepo     repository.TripRepository
+	carRepo      repository.CarRepository
+	userRepo     repository.UserRepository
+	cities       CityService
+	matcher      TripMatcher
+	policy       Policy
+	horizon      time.Duration
+	db           *gorm.DB
+	logger       *slog.Logger
+	now          func() time.Time
+}
+
+func NewTripTemplateService(
+	templateRepo repository.TripTemplateRepository,
+	tripRepo repository.TripRepository,
+	carRepo repository.CarRepository,
+	userRepo repository.UserRepository,
+	cities CityService,
+	matcher TripMatcher,
+	policy Policy,
+	horizon time.Duration,
+	db *gorm.DB,
+	logger *slog.Logger,
+) TripTemplateService {
+	return &tripTemplateService{
+		templateRepo: templateRepo,
+		tripRepo:     tripRepo,
+		carRepo:      carRepo,
+		userRepo:     userRepo,
+		cities:       cities,
+		matcher:      matcher,
+		policy:       policy,
+		horizon:      horizon,
+		db:           db,
+		logger:       logger,
+		now:          time.Now,
+	}
+}
+
+func (s *tripTemplateService) Create(driverID uint, req *dto.TripTemplateCreateRequest) (*models.TripTemplate, error) {
+	op := "service.trip_template.Create"
+
+	s.logger.Debug(" call", slog.String("op", op), slog.Uint64("driver_id", uint64(driverID)))
+
+	car, err := driverCar(s.carRepo, driverID, req.CarID)
+	if err != nil {
+		return nil, err
+	}
+
+	mode := req.BookingMode
+	if mode == "" {
+		mode = constants.BookingModeManual
+	}
+
+	template := &models.TripTemplate{
+		DriverID:          driverID,
+		CarID:             car.ID,
+		FromCity:          req.FromCity,
+		ToCity:            req.ToCity,
+		DurationMin:       req.DurationMin,
+		Price:             req.Price,
+		BookingMode:       mode,
+		PendingTimeoutMin: req.PendingTimeoutMin,
+		Weekdays:          req.Weekdays,
+		DepartureTime:     req.DepartureTime,
+		Timezone:          req.Timezone,
+		Exceptions:        req.Exceptions,
+		Active:            true,
+	}
+
+	if template.Timezone == "" {
+		template.Timezone = defaultTimezone
+	}
+
+	if template.StartDate, err = parseTemplateDate(req.StartDate); err != nil {
+		return nil, err
+	}
+	if req.EndDate != nil {
+		end, err := parseTemplateDate(*req.EndDate)
+		if err != nil {
+			return nil, err
+		}
+		template.EndDate = &end
+	}
+
+	if err := s.policy.Authorize(driverID, ActionCreate, template); err != nil {
+		return nil, err
+	}
+
+	driver, err := s.userRepo.GetByID(driverID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVerified(driver, car, s.now()); err != nil {
+		return nil, err
+	}
+
+	if template.FromCityID, err = resolveCity(s.cities, &template.FromCity); err != nil {
+		return nil, err
+	}
+	if template.ToCityID, err = resolveCity(s.cities, &template.ToCity); err != nil {
+		return nil, err
+	}
+
+	if err := validateTemplate(template); err != nil {
+		return nil, err
+	}
+
+	if err := s.templateRepo.Create(template); err != nil {
+		s.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	if _, err := s.Materialize(template.ID, s.now()); err != nil {
+		// шаблон уже сохранён, поездки догенерирует планировщик
+		s.logger.Error("failed to materialize new template", slog.String("op", op), slog.Any("error", err))
+	}
+
+	s.logger.Info("trip template created", slog.String("op", op), slog.Uint64("template_id", uint64(template.ID)))
+	return template, nil
+}
+
+func (s *tripTemplateService) GetByID(id uint) (*models.TripTemplate, error) {
+	return s.templateRepo.GetByID(id)
+}
+
+func (s *tripTemplateService) ListByDriver(driverID uint) ([]models.TripTemplate, error) {
+	return s.templateRepo.ListByDriver(driverID)
+}
+
+func (s *tripTemplateService) Update(actorID, id uint, req *dto.TripTemplateUpdateRequest) (*models.TripTemplate, error) {
+	op := "service.trip_template.Update"
+
+	s.logger.Debug(" call", slog.String("op", op), slog.Uint64("template_id", uint64(id)))
+
+	template, err := s.templateRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.policy.Authorize(actorID, ActionUpdate, template); err != nil {
+		return nil, err
+	}
+
+	if !template.Active {
+		return nil, fmt.Errorf("%w: template is cancelled", ErrInvalidSchedule)
+	}
+
+	fromCityID, err := resolveCity(s.cities, req.FromCity)
+	if err != nil {
+		return nil, err
+	}
+	toCityID, err := resolveCity(s.cities, req.ToCity)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyTemplateUpdate(template, req); err != nil {
+		return nil, err
+	}
+	// неизвестный справочнику город остаётся без ID, а не со старым
+	if req.FromCity != nil {
+		template.FromCityID = fromCityID
+	}
+	if req.ToCity != nil {
+		template.ToCityID = toCityID
+	}
+
+	if err := validateTemplate(template); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.templateRepo.WithDB(tx).Update(template); err != nil {
+			return err
+		}
+
+		return s.propagate(tx, template, now)
+	})
+	if err != nil {
+		s.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	if _, err := s.Materialize(template.ID, now); err != nil {
+		s.logger.Error("failed to materialize updated template", slog.String("op", op), slog.Any("error", err))
+	}
+
+	s.logger.Info("trip template updated", slog.String("op", op), slog.Uint64("template_id", uint64(id)))
+	return template, nil
+}
+
+func (s *tripTemplateService) Cancel(actorID, id uint) error {
+	op := "service.trip_template.Cancel"
+
+	s.logger.Debug(" call", slog.String("op", op), slog.Uint64("template_id", uint64(id)))
+
+	template, err := s.templateRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.policy.Authorize(actorID, ActionDelete, template); err != nil {
+		return err
+	}
+
+	template.Active = false
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.templateRepo.WithDB(tx).Update(template); err != nil {
+			return err
+		}
+
+		// неактивный шаблон не даёт ни одной даты — propagate удалит все свободные поездки
+		return s.propagate(tx, template, s.now())
+	})
+	if err != nil {
+		s.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	s.logger.Info("trip template cancelled", slog.String("op", op), slog.Uint64("template_id", uint64(id)))
+	return nil
+}
+
+func (s *tripTemplateService) Materialize(templateID uint, now time.Time) (int, error) {
+	op := "service.trip_template.Materialize"
+
+	template, err := s.templateRepo.GetByID(templateID)
+	if err != nil {
+		return 0, err
+	}
+
+	if !template.Active {
+		return 0, nil
+	}
+
+	occurrences, err := templateOccurrences(template, now, now.Add(s.horizon))
+	if err != nil {
+		return 0, err
+	}
+
+	existing, err := s.tripRepo.ListFutureByTemplate(template.ID, now)
+	if err != nil {
+		return 0, err
+	}
+
+	// одна поездка на дату: поездки с бронями могли сохранить старое время отправления
+	taken := make(map[string]bool, len(existing))
+	for _, trip := range existing {
+		taken[templateLocalDate(template, trip.StartTime)] = true
+	}
+
+	car, err := s.carRepo.GetByID(template.CarID)
+	if err != nil {
+		return 0, err
+	}
+
+	driver, err := s.userRepo.GetByID(template.DriverID)
+	if err != nil {
+		return 0, err
+	}
+
+	// пока проверка водителя не продлена, новые поездки по шаблону не публикуются
+	if err := checkVerified(driver, car, now); err != nil {
+		s.logger.Warn("template driver is not verified",
+			slog.String("op", op),
+			slog.Uint64("template_id", uint64(template.ID)),
+		)
+		return 0, nil
+	}
+
+	created := 0
+	for _, start := range occurrences {
+		if taken[templateLocalDate(template, start)] {
+			continue
+		}
+
+		trip := templateTrip(template, start, car.Seats)
+		if trip.Stops, err = buildTripStops(trip, nil, nil); err != nil {
+			return created, err
+		}
+		if err := s.tripRepo.Create(trip); err != nil {
+			// параллельный прогон воркера уже создал поездку на эту дату
+			if errors.Is(err, repository.ErrAlreadyExists) {
+				continue
+			}
+			s.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
+			return created, err
+		}
+		created++
+
+		// как и при ручной публикации, сбой рассылки подписчикам не отменяет поездку
+		if err := s.matcher.Match(trip); err != nil {
+			s.logger.Error("failed to match saved searches",
+				slog.String("op", op),
+				slog.Uint64("trip_id", uint64(trip.ID)),
+				slog.Any("error", err),
+			)
+		}
+	}
+
+	if created > 0 {
+		s.logger.Info("trips materialized",
+			slog.String("op", op),
+			slog.Uint64("template_id", uint64(template.ID)),
+			slog.Int("count", created),
+		)
+	}
+
+	return created, nil
+}
+
+// propagate приводит будущие поездки без броней к текущему шаблону: совпадающие по дате
+// обновляются, остальные удаляются. Поездки с бронями не трогаем — пассажиры уже
+// рассчитывают на их условия.
+func (s *tripTemplateService) propagate(tx *gorm.DB, template *models.TripTemplate, now time.Time) error {
+	tripRepo := s.tripRepo.WithDB(tx)
+
+	trips, err := tripRepo.ListFutureByTemplate(template.ID, now)
+	if err != nil {
+		return err
+	}
+
+	if len(trips) == 0 {
+		return nil
+	}
+
+	var occurrences []time.Time
+	if template.Active {
+		last := trips[len(trips)-1].StartTime.AddDate(0, 0, 1)
+		if occurrences, err = templateOccurrences(template, now, last); err != nil {
+			return err
+		}
+	}
+
+	byDate := make(map[string]time.Time, len(occurrences))
+	for _, start := range occurrences {
+		byDate[templateLocalDate(template, start)] = start
+	}
+
+	for i := range trips {
+		// блокировка поездки не даёт брони появиться между проверкой и правкой
+		trip, err := tripRepo.GetByIDForUpdate(trips[i].ID)
+		if err != nil {
+			return err
+		}
+
+		booked, err := tripRepo.HasActiveBookings(trip.ID)
+		if err != nil {
+			return err
+		}
+		if booked {
+			continue
+		}
+
+		date := templateLocalDate(template, trip.StartTime)
+
+		start, ok := byDate[date]
+		if !ok {
+			if err := tripRepo.Delete(trip.ID); err != nil {
+				return err
+			}
+			continue
+		}
+
+		trip.FromCity, trip.FromCityID = template.FromCity, template.FromCityID
+		trip.ToCity, trip.ToCityID = template.ToCity, template.ToCityID
+		trip.StartTime = start
+		trip.TemplateDate = &date
+		trip.DurationMin = template.DurationMin
+		trip.Price = template.Price
+		trip.BookingMode = template.BookingMode
+		trip.PendingTimeoutMin = template.PendingTimeoutMin
+
+		if err := tripRepo.Update(trip); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// templateOccurrences возвращает моменты отправления по шаблону в интервале [from, to)
+func templateOccurrences(template *models.TripTemplate, from, to time.Time) ([]time.Time, error) {
+	loc, err := time.LoadLocation(template.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidSchedule, template.Timezone)
+	}
+
+	departure, err := time.Parse(templateTimeLayout, template.DepartureTime)
+	if err != nil {
+		return nil, fmt.Errorf("%w: departure_time must be HH:MM", ErrInvalidSchedule)
+	}
+
+	weekdays := make(map[time.Weekday]bool, len(template.Weekdays))
+	for _, d := range template.Weekdays {
+		weekdays[time.Weekday(d%7)] = true // 7 — воскресенье, в time.Weekday это 0
+	}
+
+	exceptions := make(map[string]bool, len(template.Exceptions))
+	for _, d := range template.Exceptions {
+		exceptions[d] = true
+	}
+
+	startDate := template.StartDate.Format(templateDateLayout)
+	endDate := ""
+	if template.EndDate != nil {
+		endDate = template.EndDate.Format(templateDateLayout)
+	}
+
+	var result []time.Time
+
+	local := from.In(loc)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+
+	for ; day.Before(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format(templateDateLayout)
+
+		if date < startDate {
+			continue
+		}
+		if endDate != "" && date > endDate {
+			break
+		}
+		if !weekdays[day.Weekday()] || exceptions[date] {
+			continue
+		}
+
+		start := time.Date(day.Year(), day.Month(), day.Day(), departure.Hour(), departure.Minute(), 0, 0, loc)
+		if start.Before(from) || !start.Before(to) {
+			continue
+		}
+
+		result = append(result, start.UTC())
+	}
+
+	return result, nil
+}
+
+func templateTrip(template *models.TripTemplate, start time.Time, seats int) *models.Trip {
+	templateID := template.ID
+	date := templateLocalDate(template, start)
+
+	return &models.Trip{
+		DriverID:          template.DriverID,
+		CarID:             template.CarID,
+		FromCity:          template.FromCity,
+		FromCityID:        template.FromCityID,
+		ToCity:            template.ToCity,
+		ToCityID:          template.ToCityID,
+		StartTime:         start,
+		DurationMin:       template.DurationMin,
+		TotalSeats:        seats,
+		AvailableSeats:    seats,
+		Price:             template.Price,
+		TripStatus:        string(constants.TripPublished),
+		BookingMode:       template.BookingMode,
+		PendingTimeoutMin: template.PendingTimeoutMin,
+		TemplateID:        &templateID,
+		TemplateDate:      &date,
+	}
+}
+
+func templateLocalDate(template *models.TripTemplate, t time.Time) string {
+	loc, err := time.LoadLocation(template.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	return t.In(loc).Format(templateDateLayout)
+}
+
+func validateTemplate(template *models.TripTemplate) error {
+	if len(template.Weekdays) == 0 {
+		return fmt.Errorf("%w: weekdays are required", ErrInvalidSchedule)
+	}
+	for _, d := range template.Weekdays {
+		if d < 1 || d > 7 {
+			return fmt.Errorf("%w: weekday must be 1..7", ErrInvalidSchedule)
+		}
+	}
+
+	if template.EndDate != nil && template.EndDate.Before(template.StartDate) {
+		return fmt.Errorf("%w: end_date is before start_date", ErrInvalidSchedule)
+	}
+
+	for _, d := range template.Exceptions {
+		if _, err := parseTemplateDate(d); err != nil {
+			return err
+		}
+	}
+
+	// часовой пояс и время отправления проверяются разбором правила
+	_, err := templateOccurrences(template, template.StartDate, template.StartDate)
+	return err
+}
+
+func applyTemplateUpdate(template *models.TripTemplate, req *dto.TripTemplateUpdateRequest) error {
+	if req.FromCity != nil {
+		template.FromCity = *req.FromCity
+	}
+	if req.ToCity != nil {
+		template.ToCity = *req.ToCity
+	}
+	if req.DurationMin != nil {
+		template.DurationMin = *req.DurationMin
+	}
+	if req.Price != nil {
+		template.Price = *req.Price
+	}
+	if req.BookingMode != nil {
+		template.BookingMode = *req.BookingMode
+	}
+	if req.PendingTimeoutMin != nil {
+		template.PendingTimeoutMin = *req.PendingTimeoutMin
+	}
+	if req.Weekdays != nil {
+		template.Weekdays = req.Weekdays
+	}
+	if req.DepartureTime != nil {
+		template.DepartureTime = *req.DepartureTime
+	}
+	if req.Timezone != nil {
+		template.Timezone = *req.Timezone
+	}
+	if req.Exceptions != nil {
+		template.Exceptions = req.Exceptions
+	}
+	if req.StartDate != nil {
+		start, err := parseTemplateDate(*req.StartDate)
+		if err != nil {
+			return err
+		}
+		template.StartDate = start
+	}
+	if req.EndDate != nil {
+		// пустая строка снимает дату окончания
+		if *req.EndDate == "" {
+			template.EndDate = nil
+		} else {
+			end, err := parseTemplateDate(*req.EndDate)
+			if err != nil {
+				return err
+			}
+			template.EndDate = &end
+		}
+	}
+
+	return nil
+}
+
+func parseTemplateDate(value string) (time.Time, error) {
+	date, err := time.Parse(templateDateLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: date %q must be YYYY-MM-DD", ErrInvalidSchedule, value)
+	}
+	return date, nil
+}
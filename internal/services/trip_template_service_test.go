@@ -0,0 +1,107 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+)
+
+func TestTemplateOccurrences(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Skipf("no timezone data: %v", err)
+	}
+
+	// неделя с понедельника 19 октября 2026 года по московскому времени
+	from := time.Date(2026, 10, 19, 0, 0, 0, 0, moscow)
+	to := from.AddDate(0, 0, 7)
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 5, 30, 0, 0, time.UTC) }
+	date := func(d int) time.Time { return time.Date(2026, 10, d, 0, 0, 0, 0, time.UTC) }
+	endDate := date(20)
+
+	cases := []struct {
+		name     string
+		template models.TripTemplate
+		from     time.Time
+		want     []time.Time
+		wantErr  bool
+	}{
+		{
+			name:     "weekdays",
+			template: models.TripTemplate{Weekdays: []int{1, 3}},
+			want:     []time.Time{day(19), day(21)},
+		},
+		{
+			name:     "sunday is 7",
+			template: models.TripTemplate{Weekdays: []int{7}},
+			want:     []time.Time{day(25)},
+		},
+		{
+			name:     "exception",
+			template: models.TripTemplate{Weekdays: []int{1, 3}, Exceptions: []string{"2026-10-21"}},
+			want:     []time.Time{day(19)},
+		},
+		{
+			name:     "end date",
+			template: models.TripTemplate{Weekdays: []int{1, 3}, EndDate: &endDate},
+			want:     []time.Time{day(19)},
+		},
+		{
+			name:     "start date",
+			template: models.TripTemplate{Weekdays: []int{1, 3}, StartDate: date(20)},
+			want:     []time.Time{day(21)},
+		},
+		{
+			name:     "departure already passed",
+			template: models.TripTemplate{Weekdays: []int{1, 3}},
+			from:     time.Date(2026, 10, 19, 9, 0, 0, 0, moscow),
+			want:     []time.Time{day(21)},
+		},
+		{
+			name:     "unknown timezone",
+			template: models.TripTemplate{Weekdays: []int{1}, Timezone: "Mars/Olympus"},
+			wantErr:  true,
+		},
+		{
+			name:     "bad departure time",
+			template: models.TripTemplate{Weekdays: []int{1}, DepartureTime: "8h"},
+			wantErr:  true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			template := c.template
+			if template.Timezone == "" {
+				template.Timezone = "Europe/Moscow"
+			}
+			if template.DepartureTime == "" {
+				template.DepartureTime = "08:30"
+			}
+			if template.StartDate.IsZero() {
+				template.StartDate = date(1)
+			}
+			start := c.from
+			if start.IsZero() {
+				start = from
+			}
+
+			got, err := templateOccurrences(&template, start, to)
+			if c.wantErr {
+				if !errors.Is(err, ErrInvalidSchedule) {
+					t.Errorf("error = %v, want ErrInvalidSchedule", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("templateOccurrences: %v", err)
+			}
+			if !slices.EqualFunc(got, c.want, time.Time.Equal) {
+				t.Errorf("occurrences = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
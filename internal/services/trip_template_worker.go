@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+)
+
+// TripTemplateWorker поддерживает расписание регулярных рейсов на горизонт вперёд
+type TripTemplateWorker struct {
+	repo      repository.TripTemplateRepository
+	templates TripTemplateService
+	logger    *slog.Logger
+	tick      time.Duration
+}
+
+func NewTripTemplateWorker(
+	repo repository.TripTemplateRepository,
+	templates TripTemplateService,
+	logger *slog.Logger,
+	tick time.Duration,
+) *TripTemplateWorker {
+	return &TripTemplateWorker{
+		repo:      repo,
+		templates: templates,
+		logger:    logger,
+		tick:      tick,
+	}
+}
+
+func (w *TripTemplateWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.tick)
+
+	go func() {
+		defer ticker.Stop()
+
+		// первый прогон сразу при старте, чтобы не ждать целый тик
+		w.run(time.Now().UTC())
+
+		for {
+			select {
+			case <-ctx.Done():
+				w.logger.Info("trip template worker stopped")
+				return
+
+			case <-ticker.C:
+				w.run(time.Now().UTC())
+			}
+		}
+	}()
+}
+
+func (w *TripTemplateWorker) run(now time.Time) {
+	templates, err := w.repo.ListActive()
+	if err != nil {
+		w.logger.Error(
+			"failed to list trip templates",
+			slog.Any("error", err),
+		)
+		return
+	}
+
+	for _, template := range templates {
+		if _, err := w.templates.Materialize(template.ID, now); err != nil {
+			w.logger.Error(
+				"failed to materialize trip template",
+				slog.Uint64("template_id", uint64(template.ID)),
+				slog.Any("error", err),
+			)
+		}
+	}
+}
@@ -1,42 +1,63 @@
 package services
 
 import (
+	"errors"
 	"log/slog"
 
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
 	"github.com/mutsaevz/team-5-ambitious/internal/dto"
 	"github.com/mutsaevz/team-5-ambitious/internal/models"
 	"github.com/mutsaevz/team-5-ambitious/internal/repository"
 )
 
+var (
+	ErrInvalidRole = errors.New("invalid role")
+	ErrPhoneTaken  = errors.New("phone is already registered")
+)
+
 type UserService interface {
 	Create(req *dto.UserCreateRequest) (*models.User, error)
 
-	List(filter models.Page) ([]models.User, error)
+	// List — полный список пользователей, доступен только администратору
+	List(actorID uint, filter models.Page) (*dto.Page[models.User], error)
 
 	GetByID(id uint) (*models.User, error)
 
-	Update(id uint, req dto.UserUpdateRequest) (*models.User, error)
+	Update(actorID, id uint, req dto.UserUpdateRequest) (*models.User, error)
+
+	// RequestPhoneCode отправляет код на номер, который пользователь хочет сделать своим
+	RequestPhoneCode(actorID uint, phone string) error
+
+	SetRole(actorID, id uint, role constants.UserRole) (*models.User, error)
+
+	Delete(actorID, id uint) error
 
-	Delete(id uint) error
+	// BootstrapAdmins выдаёт роль администратора пользователям с этими номерами и создаёт недостающих;
+	// вызывается при запуске, иначе на новой установке некому назначать роли
+	BootstrapAdmins(phones []string) error
 }
 
 type userService struct {
 	repo   repository.UserRepository
+	phones PhoneVerifier
+	policy Policy
 	logger *slog.Logger
 }
 
-func NewUserService(userRepo repository.UserRepository, logger *slog.Logger) UserService {
+func NewUserService(userRepo repository.UserRepository, phones PhoneVerifier, policy Policy, logger *slog.Logger) UserService {
 	return &userService{
 		repo:   userRepo,
+		phones: phones,
+		policy: policy,
 		logger: logger,
 	}
 }
 
 func (s *userService) Create(req *dto.UserCreateRequest) (*models.User, error) {
 	var user = models.User{
-		Name:    req.Name,
-		Phone:   req.Phone,
-		Balance: req.Balance,
+		Name:  req.Name,
+		Phone: normalizePhone(req.Phone),
+		Role:  constants.RolePassenger,
 	}
 
 	if err := s.repo.Create(&user); err != nil {
@@ -49,7 +70,11 @@ func (s *userService) Create(req *dto.UserCreateRequest) (*models.User, error) {
 	return &user, nil
 }
 
-func (s *userService) List(filter models.Page) ([]models.User, error) {
+func (s *userService) List(actorID uint, filter models.Page) (*dto.Page[models.User], error) {
+	if err := s.policy.Authorize(actorID, ActionManage, &models.User{}); err != nil {
+		return nil, err
+	}
+
 	users, err := s.repo.List(filter)
 	if err != nil {
 		s.logger.Error("user list error",
@@ -73,7 +98,7 @@ func (s *userService) GetByID(id uint) (*models.User, error) {
 	return user, nil
 }
 
-func (s *userService) Update(id uint, req dto.UserUpdateRequest) (*models.User, error) {
+func (s *userService) Update(actorID, id uint, req dto.UserUpdateRequest) (*models.User, error) {
 	user, err := s.repo.GetByID(id)
 	if err != nil {
 		s.logger.Error("user not found",
@@ -83,17 +108,95 @@ func (s *userService) Update(id uint, req dto.UserUpdateRequest) (*models.User,
 		return nil, err
 	}
 
+	if err := s.policy.Authorize(actorID, ActionUpdate, user); err != nil {
+		return nil, err
+	}
+
 	if req.Name != nil {
 		user.Name = *req.Name
 	}
 
 	if req.Phone != nil {
-		user.Phone = *req.Phone
+		if err := s.changePhone(user, normalizePhone(*req.Phone), req.PhoneCode); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := s.repo.Update(id, user); err != nil {
 		s.logger.Error("error saving changes",
-			slog.String("user_name", *req.Name),
+			slog.Uint64("user_id", uint64(id)),
+			slog.Any("error", err),
+		)
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *userService) RequestPhoneCode(actorID uint, phone string) error {
+	phone = normalizePhone(phone)
+
+	if err := s.checkPhoneFree(actorID, phone); err != nil {
+		return err
+	}
+
+	return s.phones.SendCode(phone)
+}
+
+// changePhone меняет номер только после подтверждения кодом, отправленным на новый номер:
+// иначе по номеру, которым пользователь не владеет, можно было бы перехватить вход
+func (s *userService) changePhone(user *models.User, phone string, code *string) error {
+	if phone == user.Phone {
+		return nil
+	}
+
+	if err := s.checkPhoneFree(user.ID, phone); err != nil {
+		return err
+	}
+
+	if code == nil {
+		return ErrInvalidCode
+	}
+	if err := s.phones.VerifyCode(phone, *code); err != nil {
+		return err
+	}
+
+	user.Phone = phone
+	return nil
+}
+
+func (s *userService) checkPhoneFree(userID uint, phone string) error {
+	other, err := s.repo.GetByPhone(phone)
+	if err == nil && other.ID != userID {
+		return ErrPhoneTaken
+	}
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+func (s *userService) SetRole(actorID, id uint, role constants.UserRole) (*models.User, error) {
+	switch role {
+	case constants.RolePassenger, constants.RoleDriver, constants.RoleAdmin:
+	default:
+		return nil, ErrInvalidRole
+	}
+
+	user, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.policy.Authorize(actorID, ActionSetRole, user); err != nil {
+		return nil, err
+	}
+
+	user.Role = role
+
+	if err := s.repo.Update(id, user); err != nil {
+		s.logger.Error("error saving role",
+			slog.Uint64("user_id", uint64(id)),
 			slog.Any("error", err),
 		)
 		return nil, err
@@ -102,9 +205,48 @@ func (s *userService) Update(id uint, req dto.UserUpdateRequest) (*models.User,
 	return user, nil
 }
 
-func (s *userService) Delete(id uint) error {
-	err := s.repo.Delete(id)
+func (s *userService) BootstrapAdmins(phones []string) error {
+	for _, phone := range phones {
+		phone = normalizePhone(phone)
+
+		user, err := s.repo.GetByPhone(phone)
+		if errors.Is(err, repository.ErrNotFound) {
+			user = &models.User{Name: phone, Phone: phone, Role: constants.RoleAdmin}
+			if err := s.repo.Create(user); err != nil {
+				return err
+			}
+			s.logger.Info("admin created", slog.Uint64("user_id", uint64(user.ID)))
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if user.Role == constants.RoleAdmin {
+			continue
+		}
+
+		user.Role = constants.RoleAdmin
+		if err := s.repo.Update(user.ID, user); err != nil {
+			return err
+		}
+		s.logger.Info("admin role granted", slog.Uint64("user_id", uint64(user.ID)))
+	}
+
+	return nil
+}
+
+func (s *userService) Delete(actorID, id uint) error {
+	user, err := s.repo.GetByID(id)
 	if err != nil {
+		return err
+	}
+
+	if err := s.policy.Authorize(actorID, ActionDelete, user); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(id); err != nil {
 		s.logger.Error("failed to delete user",
 			slog.Uint64("user_id", uint64(id)),
 			slog.Any("error", err),
@@ -0,0 +1,410 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrDriverNotVerified    = errors.New("driver and car must be verified to publish trips")
+	ErrVerificationPending  = errors.New("verification request for this car is already pending")
+	ErrVerificationReviewed = errors.New("verification request is already reviewed")
+	ErrVerificationStale    = errors.New("car plate changed after the verification request was submitted")
+	ErrLicenseExpired       = errors.New("driver license has expired")
+	ErrUnsupportedDocument  = errors.New("document must be a JPEG, PNG or PDF file")
+	ErrDocumentTooLarge     = errors.New("document is too large")
+)
+
+// форматы файлов документов и расширения в хранилище
+var documentTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"application/pdf": ".pdf",
+}
+
+// DocumentUpload — загружаемый файл документа и его размер в байтах
+type DocumentUpload struct {
+	Reader io.Reader
+	Size   int64
+}
+
+type VerificationService interface {
+	Submit(driverID uint, req *dto.VerificationSubmitRequest, license, registration DocumentUpload) (*models.VerificationRequest, error)
+
+	ListMine(driverID uint) ([]models.VerificationRequest, error)
+
+	// Queue — заявки для администратора, старые первыми
+	Queue(actorID uint, status *constants.VerificationStatus, filter models.Page) (*dto.Page[models.VerificationRequest], error)
+
+	Approve(actorID, id uint) (*models.VerificationRequest, error)
+
+	Reject(actorID, id uint, reason string) (*models.VerificationRequest, error)
+
+	// Document открывает файл документа заявки; доступен только администратору
+	Document(actorID, id uint, kind constants.VerificationDocument) (io.ReadCloser, string, error)
+
+	// ExpireDue отмечает заявки с истёкшим удостоверением; вызывается воркером
+	ExpireDue(now time.Time) (int64, error)
+}
+
+type verificationService struct {
+	verificationRepo repository.VerificationRepository
+	userRepo         repository.UserRepository
+	carRepo          repository.CarRepository
+	policy           Policy
+	documents        BlobStore
+	notifier         Notifier
+	maxDocumentSize  int64
+	db               *gorm.DB
+	logger           *slog.Logger
+	now              func() time.Time
+}
+
+func NewVerificationService(
+	verificationRepo repository.VerificationRepository,
+	userRepo repository.UserRepository,
+	carRepo repository.CarRepository,
+	policy Policy,
+	documents BlobStore,
+	notifier Notifier,
+	maxDocumentSize int64,
+	db *gorm.DB,
+	logger *slog.Logger,
+) VerificationService {
+	return &verificationService{
+		verificationRepo: verificationRepo,
+		userRepo:         userRepo,
+		carRepo:          carRepo,
+		policy:           policy,
+		documents:        documents,
+		notifier:         notifier,
+		maxDocumentSize:  maxDocumentSize,
+		db:               db,
+		logger:           logger,
+		now:              time.Now,
+	}
+}
+
+func (s *verificationService) Submit(
+	driverID uint,
+	req *dto.VerificationSubmitRequest,
+	license, registration DocumentUpload,
+) (*models.VerificationRequest, error) {
+	op := "service.verification.Submit"
+
+	s.logger.Debug(" call", slog.String("op", op), slog.Uint64("driver_id", uint64(driverID)))
+
+	car, err := driverCar(s.carRepo, driverID, req.CarID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+
+	verification := &models.VerificationRequest{
+		DriverID:         driverID,
+		CarID:            car.ID,
+		Plate:            car.Plate,
+		LicenseNumber:    strings.ToUpper(strings.TrimSpace(req.LicenseNumber)),
+		LicenseExpiresAt: req.LicenseExpiresAt,
+		Status:           constants.VerificationPending,
+		SubmittedAt:      now,
+	}
+
+	if err := s.policy.Authorize(driverID, ActionCreate, verification); err != nil {
+		return nil, err
+	}
+
+	if !verification.LicenseExpiresAt.After(now) {
+		return nil, ErrLicenseExpired
+	}
+
+	pending, err := s.verificationRepo.HasPending(driverID, car.ID)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, ErrVerificationPending
+	}
+
+	if verification.LicenseKey, verification.LicenseContentType, err = s.storeDocument(driverID, license); err != nil {
+		return nil, err
+	}
+	if verification.RegistrationKey, verification.RegistrationContentType, err = s.storeDocument(driverID, registration); err != nil {
+		s.removeDocuments(verification.LicenseKey)
+		return nil, err
+	}
+
+	if err := s.verificationRepo.Create(verification); err != nil {
+		s.removeDocuments(verification.LicenseKey, verification.RegistrationKey)
+		return nil, err
+	}
+
+	s.logger.Info("verification submitted",
+		slog.Uint64("verification_id", uint64(verification.ID)),
+		slog.Uint64("driver_id", uint64(driverID)),
+		slog.Uint64("car_id", uint64(car.ID)),
+	)
+
+	return verification, nil
+}
+
+func (s *verificationService) ListMine(driverID uint) ([]models.VerificationRequest, error) {
+	return s.verificationRepo.ListByDriver(driverID)
+}
+
+func (s *verificationService) Queue(
+	actorID uint,
+	status *constants.VerificationStatus,
+	filter models.Page,
+) (*dto.Page[models.VerificationRequest], error) {
+	if err := s.policy.Authorize(actorID, ActionManage, &models.VerificationRequest{}); err != nil {
+		return nil, err
+	}
+
+	return s.verificationRepo.List(status, filter)
+}
+
+func (s *verificationService) Approve(actorID, id uint) (*models.VerificationRequest, error) {
+	return s.review(actorID, id, constants.VerificationApproved, "")
+}
+
+func (s *verificationService) Reject(actorID, id uint, reason string) (*models.VerificationRequest, error) {
+	return s.review(actorID, id, constants.VerificationRejected, reason)
+}
+
+// review переводит заявку из pending в решение администратора; одобрение
+// продлевает проверку водителя до срока удостоверения и подтверждает автомобиль
+func (s *verificationService) review(
+	actorID, id uint,
+	status constants.VerificationStatus,
+	reason string,
+) (*models.VerificationRequest, error) {
+	op := "service.verification.review"
+
+	s.logger.Debug(" call",
+		slog.String("op", op),
+		slog.Uint64("verification_id", uint64(id)),
+		slog.String("status", string(status)),
+	)
+
+	var (
+		verification *models.VerificationRequest
+		stale        bool
+	)
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+
+		verification, err = s.verificationRepo.WithDB(tx).GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+
+		if err := s.policy.Authorize(actorID, ActionManage, verification); err != nil {
+			return err
+		}
+
+		if verification.Status != constants.VerificationPending {
+			return ErrVerificationReviewed
+		}
+
+		now := s.now()
+
+		if status == constants.VerificationApproved && !verification.LicenseExpiresAt.After(now) {
+			return ErrLicenseExpired
+		}
+
+		if status == constants.VerificationApproved {
+			// блокировка не даёт сменить номер между сверкой и отметкой о проверке
+			car, err := s.carRepo.WithDB(tx).GetByIDForUpdate(verification.CarID)
+			if err != nil {
+				return err
+			}
+
+			// номер сменили после подачи: документы относятся к другому автомобилю
+			if !samePlate(car.Plate, verification.Plate) {
+				stale = true
+				status = constants.VerificationExpired
+			}
+		}
+
+		verification.Status = status
+		verification.RejectReason = reason
+		verification.ReviewerID = &actorID
+		verification.ReviewedAt = &now
+
+		if err := s.verificationRepo.WithDB(tx).Update(verification); err != nil {
+			return err
+		}
+
+		if status != constants.VerificationApproved {
+			return nil
+		}
+
+		if err := s.userRepo.WithDB(tx).SetDriverVerifiedUntil(verification.DriverID, verification.LicenseExpiresAt); err != nil {
+			return err
+		}
+		return s.carRepo.WithDB(tx).SetVerified(verification.CarID, true)
+	})
+	if err != nil {
+		if !errors.Is(err, repository.ErrNotFound) && !errors.Is(err, ErrForbidden) &&
+			!errors.Is(err, ErrVerificationReviewed) && !errors.Is(err, ErrLicenseExpired) {
+			s.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
+		}
+		return nil, err
+	}
+
+	message := "Документы проверены, можно публиковать поездки"
+	switch status {
+	case constants.VerificationRejected:
+		message = "Заявка на проверку отклонена: " + reason
+	case constants.VerificationExpired:
+		message = "Номер автомобиля изменился после подачи заявки, отправьте документы заново"
+	}
+
+	dispatch(s.notifier, s.logger, []Notification{{
+		UserID:  verification.DriverID,
+		Event:   "verification_" + string(status),
+		Message: message,
+	}})
+
+	if stale {
+		return nil, ErrVerificationStale
+	}
+
+	return verification, nil
+}
+
+func (s *verificationService) Document(
+	actorID, id uint,
+	kind constants.VerificationDocument,
+) (io.ReadCloser, string, error) {
+	verification, err := s.verificationRepo.GetByID(id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := s.policy.Authorize(actorID, ActionManage, verification); err != nil {
+		return nil, "", err
+	}
+
+	var key, contentType string
+
+	switch kind {
+	case constants.DocumentLicense:
+		key, contentType = verification.LicenseKey, verification.LicenseContentType
+	case constants.DocumentRegistration:
+		key, contentType = verification.RegistrationKey, verification.RegistrationContentType
+	default:
+		return nil, "", repository.ErrNotFound
+	}
+
+	file, err := s.documents.Open(key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return file, contentType, nil
+}
+
+func (s *verificationService) ExpireDue(now time.Time) (int64, error) {
+	return s.verificationRepo.ExpireDue(now)
+}
+
+func samePlate(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// storeDocument проверяет формат и размер файла и кладёт его в закрытое хранилище
+func (s *verificationService) storeDocument(driverID uint, upload DocumentUpload) (string, string, error) {
+	if upload.Size > s.maxDocumentSize {
+		return "", "", ErrDocumentTooLarge
+	}
+
+	body, contentType, err := sniffContentType(upload.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	ext, ok := documentTypes[contentType]
+	if !ok {
+		return "", "", ErrUnsupportedDocument
+	}
+
+	name, err := newBlobName()
+	if err != nil {
+		return "", "", err
+	}
+	key := fmt.Sprintf("%d/%s%s", driverID, name, ext)
+
+	// заявленный размер мог быть занижен — проверяем по фактически прочитанному
+	data, err := readLimited(body, s.maxDocumentSize, ErrDocumentTooLarge)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := s.documents.Put(key, bytes.NewReader(data)); err != nil {
+		s.logger.Error("failed to store document", slog.Uint64("driver_id", uint64(driverID)), slog.Any("error", err))
+		return "", "", err
+	}
+
+	return key, contentType, nil
+}
+
+func (s *verificationService) removeDocuments(keys ...string) {
+	for _, key := range keys {
+		if err := s.documents.Delete(key); err != nil {
+			s.logger.Warn("failed to remove document", slog.String("key", key), slog.Any("error", err))
+		}
+	}
+}
+
+// checkVerified — водитель может публиковать поездки на автомобиле, только если
+// его удостоверение проверено и не истекло, а сам автомобиль подтверждён
+func checkVerified(driver *models.User, car *models.Car, now time.Time) error {
+	if driver.DriverVerifiedUntil == nil || !driver.DriverVerifiedUntil.After(now) || !car.Verified {
+		return ErrDriverNotVerified
+	}
+	return nil
+}
+
+// markVerifiedDrivers заполняет у поездок признак проверенного водителя
+func markVerifiedDrivers(userRepo repository.UserRepository, trips []models.Trip, now time.Time) error {
+	if len(trips) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(trips))
+	for _, trip := range trips {
+		ids = append(ids, trip.DriverID)
+	}
+
+	verified, err := userRepo.ListVerifiedDriverIDs(ids, now)
+	if err != nil {
+		return err
+	}
+
+	set := make(map[uint]bool, len(verified))
+	for _, id := range verified {
+		set[id] = true
+	}
+	for i := range trips {
+		trips[i].DriverVerified = set[trips[i].DriverID]
+	}
+
+	return nil
+}
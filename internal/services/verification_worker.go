@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// VerificationExpiryWorker отмечает одобренные заявки, у которых истекло удостоверение.
+// Публикацию поездок истёкшая проверка блокирует и без него — по DriverVerifiedUntil
+type VerificationExpiryWorker struct {
+	verifications VerificationService
+	logger        *slog.Logger
+	tick          time.Duration
+}
+
+func NewVerificationExpiryWorker(
+	verifications VerificationService,
+	logger *slog.Logger,
+	tick time.Duration,
+) *VerificationExpiryWorker {
+	return &VerificationExpiryWorker{
+		verifications: verifications,
+		logger:        logger,
+		tick:          tick,
+	}
+}
+
+func (w *VerificationExpiryWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.tick)
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				w.logger.Info("verification expiry worker stopped")
+				return
+
+			case <-ticker.C:
+				w.run(time.Now().UTC())
+			}
+		}
+	}()
+}
+
+func (w *VerificationExpiryWorker) run(now time.Time) {
+	expired, err := w.verifications.ExpireDue(now)
+	if err != nil {
+		w.logger.Error(
+			"failed to expire verifications",
+			slog.Any("error", err),
+		)
+		return
+	}
+
+	if expired > 0 {
+		w.logger.Info("verifications expired", slog.Int64("count", expired))
+	}
+}
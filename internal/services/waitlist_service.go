@@ -0,0 +1,466 @@
+package services
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTripNotFull         = errors.New("trip has free seats, book it directly")
+	ErrAlreadyWaitlisted   = errors.New("passenger is already on the waitlist for this trip")
+	ErrWaitlistEntryClosed = errors.New("waitlist entry is no longer active")
+)
+
+type WaitlistService interface {
+	Join(passengerID, tripID uint, req *dto.WaitlistJoinRequest) (*models.WaitlistEntry, error)
+
+	Leave(passengerID, entryID uint) error
+
+	Accept(passengerID, entryID uint) (*models.Booking, error)
+
+	ListByTrip(actorID, tripID uint) ([]models.WaitlistEntry, error)
+
+	// Promote раздаёт освободившиеся места очереди поездки по порядку
+	Promote(tripID uint) error
+
+	ExpireOffer(entryID uint) error
+
+	// OfferedSeats — сколько мест поездки обещано очереди: пока предложение
+	// действует, эти места нельзя отдать другой брони
+	OfferedSeats(tx *gorm.DB, tripID uint) (int, error)
+
+	// CloseTrip закрывает очередь отменённой поездки в транзакции вызывающего;
+	// уведомления нужно отправить после фиксации
+	CloseTrip(tx *gorm.DB, tripID uint) ([]Notification, error)
+}
+
+type waitlistService struct {
+	waitlistRepo repository.WaitlistRepository
+	bookingRepo  repository.BookingRepository
+	tripRepo     repository.TripRepository
+	policy       Policy
+	pricing      PricingEngine
+	machine      *bookingStateMachine
+	notifier     Notifier
+	offerWindow  time.Duration
+	db           *gorm.DB
+	logger       *slog.Logger
+	now          func() time.Time
+}
+
+func NewWaitlistService(
+	waitlistRepo repository.WaitlistRepository,
+	bookingRepo repository.BookingRepository,
+	tripRepo repository.TripRepository,
+	promoRepo repository.PromoRepository,
+	ledger LedgerService,
+	policy Policy,
+	cancellation CancellationPolicy,
+	pricing PricingEngine,
+	notifier Notifier,
+	offerWindow time.Duration,
+	db *gorm.DB,
+	logger *slog.Logger,
+) WaitlistService {
+	return &waitlistService{
+		waitlistRepo: waitlistRepo,
+		bookingRepo:  bookingRepo,
+		tripRepo:     tripRepo,
+		policy:       policy,
+		pricing:      pricing,
+		machine:      newBookingStateMachine(bookingRepo, tripRepo, promoRepo, ledger, cancellation, logger),
+		notifier:     notifier,
+		offerWindow:  offerWindow,
+		db:           db,
+		logger:       logger,
+		now:          time.Now,
+	}
+}
+
+func (s *waitlistService) Join(passengerID, tripID uint, req *dto.WaitlistJoinRequest) (*models.WaitlistEntry, error) {
+	op := "service.waitlist.Join"
+
+	s.logger.Debug(" call", slog.String("op", op), slog.Uint64("trip_id", uint64(tripID)))
+
+	seats := req.Seats
+	if seats == 0 {
+		seats = 1
+	}
+
+	entry := &models.WaitlistEntry{
+		TripID:      tripID,
+		PassengerID: passengerID,
+		Seats:       seats,
+		AutoBook:    req.AutoBook,
+		Status:      constants.WaitlistWaiting,
+	}
+
+	if err := s.policy.Authorize(passengerID, ActionCreate, entry); err != nil {
+		return nil, err
+	}
+
+	trip, err := s.tripRepo.GetByID(tripID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case trip.TripStatus != string(constants.TripPublished):
+		return nil, ErrTripNotBookable
+	case trip.DriverID == passengerID:
+		return nil, ErrSelfBooking
+	case seats > trip.TotalSeats:
+		return nil, ErrInvalidSeatsCount
+	case seats <= trip.AvailableSeats:
+		return nil, ErrTripNotFull
+	}
+
+	queued, err := s.waitlistRepo.ExistsActive(tripID, passengerID)
+	if err != nil {
+		return nil, err
+	}
+	if queued {
+		return nil, ErrAlreadyWaitlisted
+	}
+
+	booked, err := s.bookingRepo.Exists(tripID, passengerID)
+	if err != nil {
+		return nil, err
+	}
+	if booked {
+		return nil, ErrDuplicateBooking
+	}
+
+	if err := s.waitlistRepo.Create(entry); err != nil {
+		s.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	s.logger.Info("joined waitlist", slog.String("op", op), slog.Uint64("entry_id", uint64(entry.ID)))
+	return entry, nil
+}
+
+func (s *waitlistService) Leave(passengerID, entryID uint) error {
+	op := "service.waitlist.Leave"
+
+	s.logger.Debug(" call", slog.String("op", op), slog.Uint64("entry_id", uint64(entryID)))
+
+	var entry *models.WaitlistEntry
+	var wasOffered bool
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+
+		entry, err = s.waitlistRepo.WithDB(tx).GetByIDForUpdate(entryID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.policy.Authorize(passengerID, ActionDelete, entry); err != nil {
+			return err
+		}
+
+		if entry.Status != constants.WaitlistWaiting && entry.Status != constants.WaitlistOffered {
+			return ErrWaitlistEntryClosed
+		}
+
+		wasOffered = entry.Status == constants.WaitlistOffered
+		entry.Status = constants.WaitlistLeft
+		entry.OfferExpiresAt = nil
+
+		return s.waitlistRepo.WithDB(tx).Update(entry)
+	})
+	if err != nil {
+		return err
+	}
+
+	// предложенные места переходят следующему в очереди
+	if wasOffered {
+		s.promoteQuietly(entry.TripID)
+	}
+
+	return nil
+}
+
+func (s *waitlistService) Accept(passengerID, entryID uint) (*models.Booking, error) {
+	op := "service.waitlist.Accept"
+
+	s.logger.Debug(" call", slog.String("op", op), slog.Uint64("entry_id", uint64(entryID)))
+
+	var (
+		booking       *models.Booking
+		notifications []Notification
+	)
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		entry, err := s.waitlistRepo.WithDB(tx).GetByIDForUpdate(entryID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.policy.Authorize(passengerID, ActionUpdate, entry); err != nil {
+			return err
+		}
+
+		if entry.Status != constants.WaitlistOffered || entry.OfferExpiresAt == nil || !entry.OfferExpiresAt.After(s.now()) {
+			return ErrWaitlistEntryClosed
+		}
+
+		trip, err := s.tripRepo.WithDB(tx).GetByIDForUpdate(entry.TripID)
+		if err != nil {
+			return err
+		}
+
+		booking, notifications, err = s.book(tx, entry, trip)
+		return err
+	})
+	if err != nil {
+		s.logger.Warn("waitlist offer not accepted", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	dispatch(s.notifier, s.logger, notifications)
+	return booking, nil
+}
+
+func (s *waitlistService) ListByTrip(actorID, tripID uint) ([]models.WaitlistEntry, error) {
+	trip, err := s.tripRepo.GetByID(tripID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.policy.Authorize(actorID, ActionManage, trip); err != nil {
+		return nil, err
+	}
+
+	return s.waitlistRepo.ListActiveByTrip(tripID)
+}
+
+func (s *waitlistService) Promote(tripID uint) error {
+	op := "service.waitlist.Promote"
+
+	var notifications []Notification
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		trip, err := s.tripRepo.WithDB(tx).GetByIDForUpdate(tripID)
+		if err != nil {
+			return err
+		}
+
+		if trip.TripStatus != string(constants.TripPublished) {
+			return nil
+		}
+
+		entries, err := s.waitlistRepo.WithDB(tx).ListActiveByTrip(tripID)
+		if err != nil {
+			return err
+		}
+
+		// места под действующими предложениями уже обещаны
+		free := trip.AvailableSeats
+		for _, e := range entries {
+			if e.Status == constants.WaitlistOffered {
+				free -= e.Seats
+			}
+		}
+
+		for i := range entries {
+			entry := &entries[i]
+			if entry.Status != constants.WaitlistWaiting {
+				continue
+			}
+
+			// строгая очередь: следующий не обгоняет того, кому мест пока не хватает
+			if entry.Seats > free {
+				break
+			}
+
+			if entry.AutoBook {
+				// точка сохранения: неудачная автобронь (например, не хватает денег)
+				// не должна откатывать всё продвижение очереди
+				var booked []Notification
+				err := tx.Transaction(func(inner *gorm.DB) error {
+					var err error
+					_, booked, err = s.book(inner, entry, trip)
+					return err
+				})
+				if err == nil {
+					notifications = append(notifications, booked...)
+					free -= entry.Seats
+					continue
+				}
+
+				s.logger.Warn("waitlist auto-book failed, offering instead",
+					slog.String("op", op),
+					slog.Uint64("entry_id", uint64(entry.ID)),
+					slog.Any("error", err),
+				)
+
+				// точка сохранения откатила места в базе, но не в загруженной поездке
+				if trip, err = s.tripRepo.WithDB(tx).GetByIDForUpdate(tripID); err != nil {
+					return err
+				}
+			}
+
+			expires := s.now().Add(s.offerWindow).UTC()
+			entry.Status = constants.WaitlistOffered
+			entry.OfferExpiresAt = &expires
+
+			if err := s.waitlistRepo.WithDB(tx).Update(entry); err != nil {
+				return err
+			}
+
+			free -= entry.Seats
+			notifications = append(notifications, waitlistNotification(entry, "waitlist_offered",
+				"Освободились места в поездке — подтвердите бронь до "+expires.Format(time.RFC3339)))
+		}
+
+		return nil
+	})
+	if err != nil {
+		s.logger.Error(" error", slog.String("op", op), slog.Uint64("trip_id", uint64(tripID)), slog.Any("error", err))
+		return err
+	}
+
+	dispatch(s.notifier, s.logger, notifications)
+	return nil
+}
+
+func (s *waitlistService) ExpireOffer(entryID uint) error {
+	var (
+		entry   *models.WaitlistEntry
+		expired bool
+	)
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+
+		entry, err = s.waitlistRepo.WithDB(tx).GetByIDForUpdate(entryID)
+		if err != nil {
+			return err
+		}
+
+		// пассажир мог успеть принять предложение, пока воркер шёл к этой записи
+		if entry.Status != constants.WaitlistOffered || entry.OfferExpiresAt == nil || entry.OfferExpiresAt.After(s.now()) {
+			return nil
+		}
+
+		entry.Status = constants.WaitlistExpired
+		expired = true
+
+		return s.waitlistRepo.WithDB(tx).Update(entry)
+	})
+	if err != nil {
+		return err
+	}
+
+	if expired {
+		dispatch(s.notifier, s.logger, []Notification{
+			waitlistNotification(entry, "waitlist_expired", "Время на подтверждение брони из листа ожидания истекло"),
+		})
+		s.promoteQuietly(entry.TripID)
+	}
+
+	return nil
+}
+
+func (s *waitlistService) OfferedSeats(tx *gorm.DB, tripID uint) (int, error) {
+	return s.waitlistRepo.WithDB(tx).OfferedSeats(tripID, s.now())
+}
+
+func (s *waitlistService) CloseTrip(tx *gorm.DB, tripID uint) ([]Notification, error) {
+	waitlistRepo := s.waitlistRepo.WithDB(tx)
+
+	entries, err := waitlistRepo.ListActiveByTrip(tripID)
+	if err != nil {
+		return nil, err
+	}
+
+	notifications := make([]Notification, 0, len(entries))
+	for i := range entries {
+		entries[i].Status = constants.WaitlistClosed
+		entries[i].OfferExpiresAt = nil
+
+		if err := waitlistRepo.Update(&entries[i]); err != nil {
+			return nil, err
+		}
+
+		notifications = append(notifications,
+			waitlistNotification(&entries[i], "waitlist_closed", "Поездка отменена, лист ожидания закрыт"))
+	}
+
+	return notifications, nil
+}
+
+// book создаёт бронь по записи очереди и подтверждает её от имени системы
+func (s *waitlistService) book(tx *gorm.DB, entry *models.WaitlistEntry, trip *models.Trip) (*models.Booking, []Notification, error) {
+	exists, err := s.bookingRepo.WithDB(tx).Exists(entry.TripID, entry.PassengerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if exists {
+		return nil, nil, ErrDuplicateBooking
+	}
+
+	// очередь ведётся на весь маршрут поездки
+	booking := &models.Booking{
+		TripID:        entry.TripID,
+		PassengerID:   entry.PassengerID,
+		Seats:         entry.Seats,
+		FromStop:      0,
+		ToStop:        lastStop(trip),
+		BookingStatus: constants.BookingPending,
+	}
+	applyQuote(booking, s.pricing.Quote(trip, booking.FromStop, booking.ToStop, booking.Seats, s.now()))
+
+	if err := s.bookingRepo.WithDB(tx).Create(booking); err != nil {
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			return nil, nil, ErrDuplicateBooking
+		}
+		return nil, nil, err
+	}
+
+	result, err := s.machine.Transition(tx, booking, trip, constants.BookingApproved, actorSystem)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entry.Status = constants.WaitlistAccepted
+	entry.OfferExpiresAt = nil
+	entry.BookingID = &booking.ID
+
+	if err := s.waitlistRepo.WithDB(tx).Update(entry); err != nil {
+		return nil, nil, err
+	}
+
+	return booking, result.notifications, nil
+}
+
+// promoteQuietly — продвижение очереди как побочный эффект: ошибка не отменяет
+// уже выполненное действие, следующая попытка случится при очередном освобождении мест
+func (s *waitlistService) promoteQuietly(tripID uint) {
+	if err := s.Promote(tripID); err != nil {
+		s.logger.Error("failed to promote waitlist",
+			slog.Uint64("trip_id", uint64(tripID)),
+			slog.Any("error", err),
+		)
+	}
+}
+
+func waitlistNotification(entry *models.WaitlistEntry, event, message string) Notification {
+	tripID := entry.TripID
+
+	return Notification{
+		UserID:  entry.PassengerID,
+		Event:   event,
+		Message: message,
+		TripID:  &tripID,
+	}
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+)
+
+// WaitlistWorker снимает просроченные предложения из листа ожидания
+// и передаёт места следующим в очереди
+type WaitlistWorker struct {
+	repo     repository.WaitlistRepository
+	waitlist WaitlistService
+	logger   *slog.Logger
+	tick     time.Duration
+}
+
+func NewWaitlistWorker(
+	repo repository.WaitlistRepository,
+	waitlist WaitlistService,
+	logger *slog.Logger,
+	tick time.Duration,
+) *WaitlistWorker {
+	return &WaitlistWorker{
+		repo:     repo,
+		waitlist: waitlist,
+		logger:   logger,
+		tick:     tick,
+	}
+}
+
+func (w *WaitlistWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.tick)
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				w.logger.Info("waitlist worker stopped")
+				return
+
+			case <-ticker.C:
+				w.run(time.Now().UTC())
+			}
+		}
+	}()
+}
+
+func (w *WaitlistWorker) run(now time.Time) {
+	due, err := w.repo.ListExpiredOffers(now)
+	if err != nil {
+		w.logger.Error(
+			"failed to list expired waitlist offers",
+			slog.Any("error", err),
+		)
+		return
+	}
+
+	for _, entry := range due {
+		if err := w.waitlist.ExpireOffer(entry.ID); err != nil {
+			w.logger.Error(
+				"failed to expire waitlist offer",
+				slog.Uint64("entry_id", uint64(entry.ID)),
+				slog.Any("error", err),
+			)
+		}
+	}
+}
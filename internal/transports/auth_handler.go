@@ -0,0 +1,116 @@
+package transports
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/services"
+)
+
+type AuthHandler struct {
+	service services.AuthService
+	logger  *slog.Logger
+}
+
+func NewAuthHandler(service services.AuthService, logger *slog.Logger) *AuthHandler {
+	return &AuthHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *AuthHandler) RegisterRoutes(ctx *gin.Engine) {
+	api := ctx.Group("/auth")
+	{
+		api.POST("/code", h.RequestCode)
+		api.POST("/login", h.Login)
+		api.POST("/refresh", h.Refresh)
+	}
+}
+
+func (h *AuthHandler) RequestCode(ctx *gin.Context) {
+	var input dto.AuthCodeRequest
+
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON"})
+		return
+	}
+
+	if err := h.service.RequestCode(input.Phone); err != nil {
+		if errors.Is(err, services.ErrPhoneNotRegistered) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrTooManyRequests) {
+			ctx.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("failed to request auth code",
+			slog.String("method", ctx.Request.Method),
+			slog.String("path", ctx.FullPath()),
+			slog.Any("error", err),
+		)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{"status": "code sent"})
+}
+
+func (h *AuthHandler) Login(ctx *gin.Context) {
+	var input dto.AuthLoginRequest
+
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON"})
+		return
+	}
+
+	tokens, err := h.service.Login(input.Phone, input.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidCode), errors.Is(err, services.ErrPhoneNotRegistered):
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrTooManyAttempts):
+			ctx.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		default:
+			h.logger.Error("failed to login",
+				slog.String("method", ctx.Request.Method),
+				slog.String("path", ctx.FullPath()),
+				slog.Any("error", err),
+			)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tokens)
+}
+
+func (h *AuthHandler) Refresh(ctx *gin.Context) {
+	var input dto.AuthRefreshRequest
+
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON"})
+		return
+	}
+
+	tokens, err := h.service.Refresh(input.RefreshToken)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidToken) || errors.Is(err, services.ErrTokenExpired) {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("failed to refresh token",
+			slog.String("method", ctx.Request.Method),
+			slog.String("path", ctx.FullPath()),
+			slog.Any("error", err),
+		)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tokens)
+}
@@ -1,6 +1,7 @@
 package transports
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -8,6 +9,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/mutsaevz/team-5-ambitious/internal/dto"
 	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
 	"github.com/mutsaevz/team-5-ambitious/internal/services"
 )
 
@@ -23,15 +25,19 @@ func NewBookingHandler(service services.BookingService, logger *slog.Logger) *Bo
 	}
 }
 
-func (h BookingHandler) RegisterRoutes(ctx *gin.Engine) {
+func (h BookingHandler) RegisterRoutes(ctx *gin.Engine, auth gin.HandlerFunc) {
 	api := ctx.Group("/bookings")
 	{
-		api.POST("/", h.Create)
-		api.GET("/", h.List)
-		api.GET("/:id", h.GetByID)
-		api.GET("driver/:driver_id/trip/:trip_id/pending", h.GetAllPendingBookingsByTripID)
-		api.PATCH("/:id", h.Update)
-		api.DELETE("/:id", h.Delete)
+		api.POST("/", auth, h.Create)
+		api.GET("/", auth, h.List)
+		api.GET("/quote", auth, h.Quote)
+		api.GET("/:id", auth, h.GetByID)
+		api.GET("/trip/:trip_id/pending", auth, h.GetAllPendingBookingsByTripID)
+		api.POST("/:id/approve", auth, h.Approve)
+		api.POST("/:id/reject", auth, h.Reject)
+		api.POST("/:id/cancel", auth, h.Cancel)
+		api.PATCH("/:id", auth, h.Update)
+		api.DELETE("/:id", auth, h.Delete)
 	}
 }
 
@@ -54,8 +60,22 @@ func (h *BookingHandler) Create(ctx *gin.Context) {
 		return
 	}
 
-	booking, err := h.service.Create(&input)
+	passengerID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	booking, err := h.service.Create(passengerID, &input)
 	if err != nil {
+		if status, ok := errorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "trip not found"})
+			return
+		}
 		h.logger.Error("error adding booking",
 			slog.String("method", ctx.Request.Method),
 			slog.String("path", ctx.FullPath()),
@@ -70,6 +90,42 @@ func (h *BookingHandler) Create(ctx *gin.Context) {
 	ctx.JSON(http.StatusCreated, booking)
 }
 
+func (h *BookingHandler) Quote(ctx *gin.Context) {
+
+	h.logger.Info("handler called",
+		slog.String("method", ctx.Request.Method),
+		slog.String("path", ctx.FullPath()),
+	)
+
+	var input dto.BookingQuoteRequest
+
+	if err := ctx.ShouldBindQuery(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid query parameters"})
+		return
+	}
+
+	quote, err := h.service.Quote(&input)
+	if err != nil {
+		if status, ok := errorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "trip not found"})
+			return
+		}
+		h.logger.Error("error quoting booking",
+			slog.String("method", ctx.Request.Method),
+			slog.String("path", ctx.FullPath()),
+			slog.Any("error", err.Error()),
+		)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, quote)
+}
+
 func (h *BookingHandler) List(ctx *gin.Context) {
 
 	h.logger.Info("handler called",
@@ -93,9 +149,21 @@ func (h *BookingHandler) List(ctx *gin.Context) {
 		}
 	}
 
-	bookings, err := h.service.List(filter)
+	filter.Cursor = ctx.Query("cursor")
+
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	bookings, err := h.service.List(userID, filter)
 
 	if err != nil {
+		if status, ok := errorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Error("error getting bookings",
 			slog.String("method", ctx.Request.Method),
 			slog.String("path", ctx.FullPath()),
@@ -117,10 +185,8 @@ func (h *BookingHandler) GetAllPendingBookingsByTripID(ctx *gin.Context) {
 		slog.String("path", ctx.FullPath()),
 	)
 	tripIDParam := ctx.Param("trip_id")
-	driverIDParam := ctx.Param("driver_id")
 
 	tripID, err := strconv.ParseUint(tripIDParam, 10, 64)
-	driverID, err := strconv.ParseUint(driverIDParam, 10, 64)
 
 	if err != nil {
 		h.logger.Warn("invalid trip ID parameter",
@@ -133,9 +199,19 @@ func (h *BookingHandler) GetAllPendingBookingsByTripID(ctx *gin.Context) {
 		return
 	}
 
-	bookings, err := h.service.GetAllPendingBookingsByTripID(uint(driverID), uint(tripID))
+	driverID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	bookings, err := h.service.GetAllPendingBookingsByTripID(driverID, uint(tripID))
 
 	if err != nil {
+		if status, ok := errorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Error("error getting pending bookings by trip ID",
 			slog.String("method", ctx.Request.Method),
 			slog.String("path", ctx.FullPath()),
@@ -170,9 +246,23 @@ func (h *BookingHandler) GetByID(ctx *gin.Context) {
 		return
 	}
 
-	booking, err := h.service.GetByID(uint(id))
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	booking, err := h.service.GetByID(userID, uint(id))
 
 	if err != nil {
+		if status, ok := errorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "booking not found"})
+			return
+		}
 		h.logger.Error("error getting booking by ID",
 			slog.String("method", ctx.Request.Method),
 			slog.String("path", ctx.FullPath()),
@@ -186,6 +276,130 @@ func (h *BookingHandler) GetByID(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, booking)
 }
 
+func (h *BookingHandler) Approve(ctx *gin.Context) {
+
+	h.logger.Info("handler called",
+		slog.String("method", ctx.Request.Method),
+		slog.String("path", ctx.FullPath()),
+	)
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID parameter"})
+		return
+	}
+
+	driverID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.service.Approve(uint(id), driverID); err != nil {
+		if status, ok := errorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "booking not found"})
+			return
+		}
+		h.logger.Error("error approving booking",
+			slog.String("method", ctx.Request.Method),
+			slog.String("path", ctx.FullPath()),
+			slog.Any("error", err.Error()),
+		)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("booking approved successfully")
+	ctx.JSON(http.StatusOK, gin.H{"status": "approved"})
+}
+
+func (h *BookingHandler) Reject(ctx *gin.Context) {
+
+	h.logger.Info("handler called",
+		slog.String("method", ctx.Request.Method),
+		slog.String("path", ctx.FullPath()),
+	)
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID parameter"})
+		return
+	}
+
+	driverID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.service.Rejected(uint(id), driverID); err != nil {
+		if status, ok := errorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "booking not found"})
+			return
+		}
+		h.logger.Error("error rejecting booking",
+			slog.String("method", ctx.Request.Method),
+			slog.String("path", ctx.FullPath()),
+			slog.Any("error", err.Error()),
+		)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("booking rejected successfully")
+	ctx.JSON(http.StatusOK, gin.H{"status": "rejected"})
+}
+
+func (h *BookingHandler) Cancel(ctx *gin.Context) {
+
+	h.logger.Info("handler called",
+		slog.String("method", ctx.Request.Method),
+		slog.String("path", ctx.FullPath()),
+	)
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID parameter"})
+		return
+	}
+
+	actorID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	booking, err := h.service.Cancel(uint(id), actorID)
+	if err != nil {
+		if status, ok := errorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "booking not found"})
+			return
+		}
+		h.logger.Error("error cancelling booking",
+			slog.String("method", ctx.Request.Method),
+			slog.String("path", ctx.FullPath()),
+			slog.Any("error", err.Error()),
+		)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	h.logger.Info("booking cancelled successfully")
+	ctx.JSON(http.StatusOK, booking)
+}
+
 func (h *BookingHandler) Update(ctx *gin.Context) {
 
 	h.logger.Info("handler called",
@@ -220,9 +434,19 @@ func (h *BookingHandler) Update(ctx *gin.Context) {
 		return
 	}
 
-	booking, err := h.service.Update(uint(id), &input)
+	actorID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	booking, err := h.service.Update(actorID, uint(id), &input)
 
 	if err != nil {
+		if status, ok := errorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Error("error updating booking",
 			slog.String("method", ctx.Request.Method),
 			slog.String("path", ctx.FullPath()),
@@ -259,7 +483,17 @@ func (h *BookingHandler) Delete(ctx *gin.Context) {
 		return
 	}
 
-	if err := h.service.Delete(uint(id)); err != nil {
+	actorID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.service.Delete(actorID, uint(id)); err != nil {
+		if status, ok := errorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Error("error deleting booking",
 			slog.String("method", ctx.Request.Method),
 			slog.String("path", ctx.FullPath()),
@@ -1,6 +1,7 @@
 package transports
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -8,6 +9,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/mutsaevz/team-5-ambitious/internal/dto"
 	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
 	"github.com/mutsaevz/team-5-ambitious/internal/services"
 )
 
@@ -23,18 +25,20 @@ func NewCarHandler(service services.CarService, logger *slog.Logger) *CarHandler
 	}
 }
 
-func (h *CarHandler) RegisterRoutes(ctx *gin.Engine) {
+func (h *CarHandler) RegisterRoutes(ctx *gin.Engine, auth gin.HandlerFunc) {
 	api := ctx.Group("/cars")
 
-	api.POST("/:id", h.Create)
+	api.POST("/", auth, h.Create)
 	api.GET("/", h.List)
-	api.GET("/owner/:id", h.GetByOwner)
+	api.GET("/owner/:id", h.ListByOwner)
 	api.GET("/:id", h.GetByID)
-	api.PUT("/:id", h.Update)
-	api.DELETE("/:id", h.Delete)
+	api.PUT("/:id", auth, h.Update)
+	api.DELETE("/:id", auth, h.Delete)
+	api.POST("/:id/photos", auth, h.AddPhoto)
+	api.DELETE("/:id/photos/:photo_id", auth, h.DeletePhoto)
 }
 
-// POST /cars/:id
+// POST /cars
 func (h *CarHandler) Create(ctx *gin.Context) {
 	var input dto.CarCreateRequest
 	if err := ctx.ShouldBindJSON(&input); err != nil {
@@ -43,22 +47,24 @@ func (h *CarHandler) Create(ctx *gin.Context) {
 		return
 	}
 
-	idStr := ctx.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 64)
-	if err != nil {
-		h.logger.Warn("Invalid owner ID for car creation", slog.String("id", idStr))
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID"})
+	ownerID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
 
-	car, err := h.service.Create(uint(id), input)
+	car, err := h.service.Create(ownerID, input)
 	if err != nil {
-		h.logger.Error("Failed to create car", slog.Uint64("owner_id", id), slog.String("error", err.Error()))
+		if status, ok := errorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("Failed to create car", slog.Uint64("owner_id", uint64(ownerID)), slog.String("error", err.Error()))
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "car create error"})
 		return
 	}
 
-	h.logger.Info("Car created successfully", slog.Uint64("car_id", uint64(car.ID)), slog.Uint64("owner_id", id))
+	h.logger.Info("Car created successfully", slog.Uint64("car_id", uint64(car.ID)), slog.Uint64("owner_id", uint64(ownerID)))
 	ctx.JSON(http.StatusOK, car)
 }
 
@@ -81,19 +87,25 @@ func (h *CarHandler) List(ctx *gin.Context) {
 		}
 	}
 
+	filter.Cursor = ctx.Query("cursor")
+
 	cars, err := h.service.List(filter)
 	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Error("Failed to list cars", slog.String("error", err.Error()))
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get cars"})
 		return
 	}
 
-	h.logger.Info("List of cars retrieved", slog.Int("count", len(cars)))
+	h.logger.Info("List of cars retrieved", slog.Int("count", len(cars.Items)))
 	ctx.JSON(http.StatusOK, cars)
 }
 
 // GET /cars/owner/:id
-func (h *CarHandler) GetByOwner(ctx *gin.Context) {
+func (h *CarHandler) ListByOwner(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 64)
 	if err != nil {
@@ -102,15 +114,15 @@ func (h *CarHandler) GetByOwner(ctx *gin.Context) {
 		return
 	}
 
-	car, err := h.service.GetByOwner(uint(id))
+	cars, err := h.service.ListByOwner(uint(id))
 	if err != nil {
-		h.logger.Warn("Car not found for owner", slog.Uint64("owner_id", id))
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "car not found"})
+		h.logger.Error("Failed to list cars by owner", slog.Uint64("owner_id", id), slog.String("error", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get cars"})
 		return
 	}
 
-	h.logger.Info("Car retrieved by owner", slog.Uint64("owner_id", id), slog.Uint64("car_id", uint64(car.ID)))
-	ctx.JSON(http.StatusOK, car)
+	h.logger.Info("Cars retrieved by owner", slog.Uint64("owner_id", id), slog.Int("count", len(cars)))
+	ctx.JSON(http.StatusOK, cars)
 }
 
 // GET /cars/:id
@@ -151,8 +163,18 @@ func (h *CarHandler) Update(ctx *gin.Context) {
 		return
 	}
 
-	car, err := h.service.Update(uint(id), input)
+	actorID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	car, err := h.service.Update(actorID, uint(id), input)
 	if err != nil {
+		if status, ok := errorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Error("Failed to update car", slog.Uint64("car_id", id), slog.String("error", err.Error()))
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
 		return
@@ -172,7 +194,17 @@ func (h *CarHandler) Delete(ctx *gin.Context) {
 		return
 	}
 
-	if err := h.service.Delete(uint(id)); err != nil {
+	actorID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.service.Delete(actorID, uint(id)); err != nil {
+		if status, ok := errorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Error("Failed to delete car", slog.Uint64("car_id", id), slog.String("error", err.Error()))
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "delete failed"})
 		return
@@ -181,3 +213,89 @@ func (h *CarHandler) Delete(ctx *gin.Context) {
 	h.logger.Info("Car deleted successfully", slog.Uint64("car_id", id))
 	ctx.JSON(http.StatusOK, gin.H{"status": "deleted"})
 }
+
+// POST /cars/:id/photos — multipart-форма с файлом в поле photo
+func (h *CarHandler) AddPhoto(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		h.logger.Warn("Invalid car ID for photo upload", slog.String("id", idStr))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID"})
+		return
+	}
+
+	actorID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	header, err := ctx.FormFile("photo")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "photo file is required"})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		h.logger.Error("Failed to open uploaded photo", slog.String("error", err.Error()))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid photo file"})
+		return
+	}
+	defer file.Close()
+
+	photo, err := h.service.AddPhoto(actorID, uint(id), file, header.Size)
+	if err != nil {
+		if status, ok := errorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "car not found"})
+			return
+		}
+		h.logger.Error("Failed to add car photo", slog.Uint64("car_id", id), slog.String("error", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "photo upload failed"})
+		return
+	}
+
+	h.logger.Info("Car photo added", slog.Uint64("car_id", id), slog.Uint64("photo_id", uint64(photo.ID)))
+	ctx.JSON(http.StatusCreated, photo)
+}
+
+// DELETE /cars/:id/photos/:photo_id
+func (h *CarHandler) DeletePhoto(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID"})
+		return
+	}
+	photoID, err := strconv.ParseUint(ctx.Param("photo_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid photo ID"})
+		return
+	}
+
+	actorID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.service.DeletePhoto(actorID, uint(id), uint(photoID)); err != nil {
+		if status, ok := errorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "photo not found"})
+			return
+		}
+		h.logger.Error("Failed to delete car photo", slog.Uint64("photo_id", photoID), slog.String("error", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "delete failed"})
+		return
+	}
+
+	h.logger.Info("Car photo deleted", slog.Uint64("car_id", id), slog.Uint64("photo_id", photoID))
+	ctx.JSON(http.StatusOK, gin.H{"status": "deleted"})
+}
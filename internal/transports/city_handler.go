@@ -0,0 +1,141 @@
+package transports
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"github.com/mutsaevz/team-5-ambitious/internal/services"
+)
+
+const (
+	defaultSuggestLimit = 10
+	maxSuggestLimit     = 50
+)
+
+type CityHandler struct {
+	service services.CityService
+	logger  *slog.Logger
+}
+
+func NewCityHandler(service services.CityService, logger *slog.Logger) *CityHandler {
+	return &CityHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *CityHandler) RegisterRoutes(ctx *gin.Engine, auth gin.HandlerFunc) {
+	api := ctx.Group("/cities")
+	{
+		api.GET("/suggest", h.Suggest)
+		api.GET("/:id", h.GetByID)
+		api.POST("/", auth, h.Create)
+		api.POST("/:id/aliases", auth, h.AddAlias)
+	}
+}
+
+// GET /cities/suggest?q=гроз&limit=10
+func (h *CityHandler) Suggest(ctx *gin.Context) {
+	limit := defaultSuggestLimit
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = min(l, maxSuggestLimit)
+		}
+	}
+
+	cities, err := h.service.Suggest(ctx.Query("q"), limit)
+	if err != nil {
+		h.writeError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, cities)
+}
+
+func (h *CityHandler) GetByID(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	city, err := h.service.GetByID(uint(id))
+	if err != nil {
+		h.writeError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, city)
+}
+
+func (h *CityHandler) Create(ctx *gin.Context) {
+	var req dto.CityCreateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	actorID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	city, err := h.service.Create(actorID, &req)
+	if err != nil {
+		h.writeError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, city)
+}
+
+func (h *CityHandler) AddAlias(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req dto.CityAliasRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	actorID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	city, err := h.service.AddAlias(actorID, uint(id), req.Name)
+	if err != nil {
+		h.writeError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, city)
+}
+
+func (h *CityHandler) writeError(ctx *gin.Context, err error) {
+	if status, ok := errorStatus(err); ok {
+		ctx.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "city not found"})
+		return
+	}
+
+	h.logger.Error("city request failed",
+		slog.String("path", ctx.FullPath()),
+		slog.Any("error", err),
+	)
+	ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+}
@@ -0,0 +1,76 @@
+package transports
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"github.com/mutsaevz/team-5-ambitious/internal/services"
+)
+
+// errorStatus сопоставляет общие ошибки сервисов с HTTP-статусами,
+// чтобы все обработчики отвечали на них одинаково
+func errorStatus(err error) (int, bool) {
+	switch {
+	case errors.Is(err, services.ErrUnauthenticated):
+		return http.StatusUnauthorized, true
+	case errors.Is(err, services.ErrTooManyAttempts),
+		errors.Is(err, services.ErrTooManyRequests):
+		return http.StatusTooManyRequests, true
+	case errors.Is(err, services.ErrForbidden):
+		return http.StatusForbidden, true
+	case errors.Is(err, services.ErrDriverNotVerified):
+		return http.StatusForbidden, true
+	case errors.Is(err, services.ErrCarPhotoTooLarge),
+		errors.Is(err, services.ErrDocumentTooLarge):
+		return http.StatusRequestEntityTooLarge, true
+	case errors.Is(err, repository.ErrInvalidCursor):
+		return http.StatusBadRequest, true
+	case errors.Is(err, repository.ErrInsufficientFunds):
+		return http.StatusPaymentRequired, true
+	case errors.Is(err, services.ErrBookingActive),
+		errors.Is(err, services.ErrInvalidTransition),
+		errors.Is(err, services.ErrInvalidTripTransition),
+		errors.Is(err, services.ErrTripNotEditable),
+		errors.Is(err, services.ErrTripHasPassengers),
+		errors.Is(err, services.ErrNoAvailableSeats),
+		errors.Is(err, services.ErrTripNotBookable),
+		errors.Is(err, services.ErrDuplicateBooking),
+		errors.Is(err, services.ErrTripNotFull),
+		errors.Is(err, services.ErrAlreadyWaitlisted),
+		errors.Is(err, services.ErrWaitlistEntryClosed),
+		errors.Is(err, services.ErrPromoExhausted),
+		errors.Is(err, services.ErrReferralNotAllowed),
+		errors.Is(err, services.ErrCarLimitReached),
+		errors.Is(err, services.ErrPlateTaken),
+		errors.Is(err, services.ErrCarSeatsInUse),
+		errors.Is(err, services.ErrCarPhotoLimit),
+		errors.Is(err, services.ErrVerificationPending),
+		errors.Is(err, services.ErrVerificationReviewed),
+		errors.Is(err, services.ErrVerificationStale),
+		errors.Is(err, services.ErrSavedSearchLimit),
+		errors.Is(err, services.ErrPhoneTaken):
+		return http.StatusConflict, true
+	case errors.Is(err, services.ErrSelfBooking),
+		errors.Is(err, services.ErrInvalidSeatsCount),
+		errors.Is(err, services.ErrInvalidSchedule),
+		errors.Is(err, services.ErrInvalidStops),
+		errors.Is(err, services.ErrInvalidSegment),
+		errors.Is(err, services.ErrPromoInvalid),
+		errors.Is(err, services.ErrPromoNotEligible),
+		errors.Is(err, services.ErrReferralInvalid),
+		errors.Is(err, services.ErrCarRequired),
+		errors.Is(err, services.ErrInvalidCar),
+		errors.Is(err, services.ErrInvalidPlate),
+		errors.Is(err, services.ErrInvalidCarYear),
+		errors.Is(err, services.ErrUnsupportedPhoto),
+		errors.Is(err, services.ErrLicenseExpired),
+		errors.Is(err, services.ErrUnsupportedDocument),
+		errors.Is(err, services.ErrUnknownCity),
+		errors.Is(err, services.ErrInvalidCode),
+		errors.Is(err, services.ErrInvalidSavedSearch):
+		return http.StatusUnprocessableEntity, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,74 @@
+package transports
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"github.com/mutsaevz/team-5-ambitious/internal/services"
+)
+
+type LedgerHandler struct {
+	service services.LedgerService
+	logger  *slog.Logger
+}
+
+func NewLedgerHandler(service services.LedgerService, logger *slog.Logger) *LedgerHandler {
+	return &LedgerHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *LedgerHandler) RegisterRoutes(ctx *gin.Engine, auth gin.HandlerFunc) {
+	api := ctx.Group("/users/me")
+	{
+		api.GET("/transactions", auth, h.History)
+	}
+}
+
+// GET /users/me/transactions
+func (h *LedgerHandler) History(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var filter models.Page
+
+	if pageStr := ctx.Query("page"); pageStr != "" {
+		if page, err := strconv.Atoi(pageStr); err == nil {
+			filter.Page = page
+		}
+	}
+
+	if pageSizeStr := ctx.Query("pageSize"); pageSizeStr != "" {
+		if pageSize, err := strconv.Atoi(pageSizeStr); err == nil {
+			filter.PageSize = pageSize
+		}
+	}
+
+	filter.Cursor = ctx.Query("cursor")
+
+	entries, err := h.service.History(userID, filter)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("failed to get transactions",
+			slog.String("method", ctx.Request.Method),
+			slog.String("path", ctx.FullPath()),
+			slog.Any("error", err),
+		)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entries)
+}
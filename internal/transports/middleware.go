@@ -0,0 +1,49 @@
+package transports
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mutsaevz/team-5-ambitious/internal/services"
+)
+
+const userIDKey = "user_id"
+
+func AuthMiddleware(tokens services.TokenService, logger *slog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		header := ctx.GetHeader("Authorization")
+
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || token == "" {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing bearer token"})
+			return
+		}
+
+		userID, err := tokens.ParseAccess(token)
+		if err != nil {
+			logger.Warn("invalid access token",
+				slog.String("method", ctx.Request.Method),
+				slog.String("path", ctx.FullPath()),
+				slog.Any("error", err),
+			)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.Set(userIDKey, userID)
+		ctx.Next()
+	}
+}
+
+// currentUserID возвращает ID пользователя, положенный в контекст AuthMiddleware
+func currentUserID(ctx *gin.Context) (uint, bool) {
+	v, ok := ctx.Get(userIDKey)
+	if !ok {
+		return 0, false
+	}
+
+	id, ok := v.(uint)
+	return id, ok && id != 0
+}
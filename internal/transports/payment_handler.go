@@ -0,0 +1,149 @@
+package transports
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"github.com/mutsaevz/team-5-ambitious/internal/services"
+)
+
+type PaymentHandler struct {
+	service services.PaymentService
+	logger  *slog.Logger
+}
+
+func NewPaymentHandler(service services.PaymentService, logger *slog.Logger) *PaymentHandler {
+	return &PaymentHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *PaymentHandler) RegisterRoutes(ctx *gin.Engine, auth gin.HandlerFunc) {
+	api := ctx.Group("/payments")
+	{
+		api.POST("/topup", auth, h.TopUp)
+		api.POST("/withdraw", auth, h.Withdraw)
+		api.GET("/", auth, h.List)
+		api.POST("/callback/:provider", h.Callback)
+	}
+}
+
+// POST /payments/topup
+func (h *PaymentHandler) TopUp(ctx *gin.Context) {
+	h.create(ctx, h.service.TopUp)
+}
+
+// POST /payments/withdraw
+func (h *PaymentHandler) Withdraw(ctx *gin.Context) {
+	h.create(ctx, h.service.Withdraw)
+}
+
+func (h *PaymentHandler) create(ctx *gin.Context, fn func(userID uint, amount int) (*dto.PaymentIntentResponse, error)) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input dto.PaymentRequest
+
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON"})
+		return
+	}
+
+	resp, err := fn(userID, input.Amount)
+	if err != nil {
+		if status, ok := errorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrInvalidAmount) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("payment error",
+			slog.String("method", ctx.Request.Method),
+			slog.String("path", ctx.FullPath()),
+			slog.Any("error", err),
+		)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, resp)
+}
+
+// GET /payments
+func (h *PaymentHandler) List(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var filter models.Page
+
+	if pageStr := ctx.Query("page"); pageStr != "" {
+		if page, err := strconv.Atoi(pageStr); err == nil {
+			filter.Page = page
+		}
+	}
+
+	if pageSizeStr := ctx.Query("pageSize"); pageSizeStr != "" {
+		if pageSize, err := strconv.Atoi(pageSizeStr); err == nil {
+			filter.PageSize = pageSize
+		}
+	}
+
+	filter.Cursor = ctx.Query("cursor")
+
+	intents, err := h.service.List(userID, filter)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("failed to list payments", slog.Any("error", err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, intents)
+}
+
+// POST /payments/callback/:provider
+func (h *PaymentHandler) Callback(ctx *gin.Context) {
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+
+	err = h.service.HandleCallback(ctx.Param("provider"), ctx.GetHeader("X-Signature"), body)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidSignature):
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrUnknownProvider), errors.Is(err, repository.ErrNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			h.logger.Error("payment callback error",
+				slog.String("provider", ctx.Param("provider")),
+				slog.Any("error", err),
+			)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
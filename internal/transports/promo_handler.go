@@ -0,0 +1,134 @@
+package transports
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"github.com/mutsaevz/team-5-ambitious/internal/services"
+)
+
+type PromoHandler struct {
+	service services.PromoService
+	logger  *slog.Logger
+}
+
+func NewPromoHandler(service services.PromoService, logger *slog.Logger) *PromoHandler {
+	return &PromoHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *PromoHandler) RegisterRoutes(ctx *gin.Engine, auth gin.HandlerFunc) {
+	api := ctx.Group("/promo-codes")
+	{
+		api.POST("/", auth, h.Create)
+		api.GET("/", auth, h.List)
+		api.PATCH("/:id", auth, h.Update)
+	}
+}
+
+func (h *PromoHandler) Create(ctx *gin.Context) {
+	var req dto.PromoCodeCreateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	actorID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	promo, err := h.service.Create(actorID, &req)
+	if err != nil {
+		h.writeError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, promo)
+}
+
+func (h *PromoHandler) List(ctx *gin.Context) {
+	actorID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var filter models.Page
+
+	if pageStr := ctx.Query("page"); pageStr != "" {
+		if page, err := strconv.Atoi(pageStr); err == nil {
+			filter.Page = page
+		}
+	}
+
+	if pageSizeStr := ctx.Query("pageSize"); pageSizeStr != "" {
+		if pageSize, err := strconv.Atoi(pageSizeStr); err == nil {
+			filter.PageSize = pageSize
+		}
+	}
+
+	filter.Cursor = ctx.Query("cursor")
+
+	promos, err := h.service.List(actorID, filter)
+	if err != nil {
+		h.writeError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, promos)
+}
+
+func (h *PromoHandler) Update(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req dto.PromoCodeUpdateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON"})
+		return
+	}
+
+	actorID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	promo, err := h.service.Update(actorID, uint(id), &req)
+	if err != nil {
+		h.writeError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, promo)
+}
+
+func (h *PromoHandler) writeError(ctx *gin.Context, err error) {
+	if status, ok := errorStatus(err); ok {
+		ctx.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "promo code not found"})
+		return
+	}
+
+	h.logger.Error("promo code request failed",
+		slog.String("path", ctx.FullPath()),
+		slog.Any("error", err),
+	)
+	ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+}
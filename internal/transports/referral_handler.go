@@ -0,0 +1,79 @@
+package transports
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/services"
+)
+
+type ReferralHandler struct {
+	service services.ReferralService
+	logger  *slog.Logger
+}
+
+func NewReferralHandler(service services.ReferralService, logger *slog.Logger) *ReferralHandler {
+	return &ReferralHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *ReferralHandler) RegisterRoutes(ctx *gin.Engine, auth gin.HandlerFunc) {
+	ctx.GET("/users/me/referral", auth, h.Code)
+	ctx.POST("/users/me/referral", auth, h.Apply)
+}
+
+// GET /users/me/referral
+func (h *ReferralHandler) Code(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	code, err := h.service.Code(userID)
+	if err != nil {
+		h.writeError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"referral_code": code})
+}
+
+// POST /users/me/referral — указать код пригласившего
+func (h *ReferralHandler) Apply(ctx *gin.Context) {
+	var req dto.ReferralApplyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.service.Apply(userID, req.Code); err != nil {
+		h.writeError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "applied"})
+}
+
+func (h *ReferralHandler) writeError(ctx *gin.Context, err error) {
+	if status, ok := errorStatus(err); ok {
+		ctx.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Error("referral request failed",
+		slog.String("path", ctx.FullPath()),
+		slog.Any("error", err),
+	)
+	ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+}
@@ -1,6 +1,7 @@
 package transports
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -24,14 +25,14 @@ func NewReviewHandler(service services.ReviewService, logger *slog.Logger) *Revi
 	}
 }
 
-func (h ReviewHandler) RegisterRoutes(ctx *gin.Engine) {
+func (h ReviewHandler) RegisterRoutes(ctx *gin.Engine, auth gin.HandlerFunc) {
 	api := ctx.Group("")
 	{
-		api.POST("/trips/:id/:author_id/reviews", h.Create)
+		api.POST("/trips/:id/reviews", auth, h.Create)
 		api.GET("/reviews", h.List)
 		api.GET("/reviews/:id", h.GetByID)
-		api.PUT("/reviews/:id/:author_id", h.Update)
-		api.DELETE("/reviews/:id/:author_id", h.Delete)
+		api.PUT("/reviews/:id", auth, h.Update)
+		api.DELETE("/reviews/:id", auth, h.Delete)
 	}
 }
 
@@ -48,14 +49,9 @@ func (h *ReviewHandler) Create(ctx *gin.Context) {
 		return
 	}
 
-	authorID, err := strconv.ParseUint(ctx.Param("author_id"), 10, 64)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid author id"})
-		h.logger.Error("invalid author id",
-			slog.String("method", ctx.Request.Method),
-			slog.String("path", ctx.FullPath()),
-			slog.Any("error", err),
-		)
+	authorID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
 
@@ -71,9 +67,13 @@ func (h *ReviewHandler) Create(ctx *gin.Context) {
 		return
 	}
 
-	review, err := h.service.Create(uint(tripID), uint(authorID), &req)
+	review, err := h.service.Create(uint(tripID), authorID, &req)
 
 	if err != nil {
+		if status, ok := errorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
 
 		if err == repository.ErrNotFound {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "trip not found"})
@@ -119,8 +119,14 @@ func (h *ReviewHandler) List(ctx *gin.Context) {
 		}
 	}
 
+	filter.Cursor = ctx.Query("cursor")
+
 	reviews, err := h.service.List(filter)
 	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Error("error listing reviews",
 			slog.String("method", ctx.Request.Method),
 			slog.String("path", ctx.FullPath()),
@@ -177,14 +183,9 @@ func (h *ReviewHandler) Update(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid review id"})
 		return
 	}
-	authorID, err := strconv.ParseUint(ctx.Param("author_id"), 10, 64)
-	if err != nil {
-		h.logger.Error("invalid author id",
-			slog.String("method", ctx.Request.Method),
-			slog.String("path", ctx.FullPath()),
-			slog.Any("error", err),
-		)
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid author id"})
+	authorID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
 
@@ -194,8 +195,12 @@ func (h *ReviewHandler) Update(ctx *gin.Context) {
 		return
 	}
 
-	review, err := h.service.Update(uint(id), uint(authorID), &req)
+	review, err := h.service.Update(uint(id), authorID, &req)
 	if err != nil {
+		if status, ok := errorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
 		if err == repository.ErrNotFound {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "review not found"})
 			return
@@ -217,20 +222,21 @@ func (h *ReviewHandler) Delete(ctx *gin.Context) {
 			slog.String("path", ctx.FullPath()),
 			slog.Any("error", err),
 		)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid review id"})
+		return
 	}
-	authorID, err := strconv.ParseUint(ctx.Param("author_id"), 10, 64)
 
-	if err != nil {
-		h.logger.Error("invalid author id",
-			slog.String("method", ctx.Request.Method),
-			slog.String("path", ctx.FullPath()),
-			slog.Any("error", err),
-		)
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid author id"})
+	authorID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
 
-	if err := h.service.Delete(uint(id), uint(authorID)); err != nil {
+	if err := h.service.Delete(uint(id), authorID); err != nil {
+		if status, ok := errorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
 		if err == repository.ErrNotFound {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "review not found"})
 			return
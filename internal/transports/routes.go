@@ -15,16 +15,51 @@ func RegisterRoutes(
 	tripService services.TripService,
 	bookingService services.BookingService,
 	reviewService services.ReviewService,
+	authService services.AuthService,
+	tokenService services.TokenService,
+	ledgerService services.LedgerService,
+	paymentService services.PaymentService,
+	waitlistService services.WaitlistService,
+	tripTemplateService services.TripTemplateService,
+	promoService services.PromoService,
+	referralService services.ReferralService,
+	verificationService services.VerificationService,
+	cityService services.CityService,
+	savedSearchService services.SavedSearchService,
 ) {
+	auth := AuthMiddleware(tokenService, logger)
+
+	authHandler := NewAuthHandler(authService, logger)
 	userHandler := NewUserHandler(userService, logger)
 	carHandler := NewCarHandler(carService, logger)
 	tripHandler := NewTripHandler(tripService, logger)
 	bookingHandler := NewBookingHandler(bookingService, logger)
 	reviewHandler := NewReviewHandler(reviewService, logger)
+	ledgerHandler := NewLedgerHandler(ledgerService, logger)
+	waitlistHandler := NewWaitlistHandler(waitlistService, logger)
+	tripTemplateHandler := NewTripTemplateHandler(tripTemplateService, logger)
+	promoHandler := NewPromoHandler(promoService, logger)
+	referralHandler := NewReferralHandler(referralService, logger)
+	verificationHandler := NewVerificationHandler(verificationService, logger)
+	cityHandler := NewCityHandler(cityService, logger)
+	savedSearchHandler := NewSavedSearchHandler(savedSearchService, logger)
 
-	userHandler.RegisterRoutes(routes)
-	carHandler.RegisterRoutes(routes)
-	tripHandler.RegisterRoutes(routes)
-	bookingHandler.RegisterRoutes(routes)
-	reviewHandler.RegisterRoutes(routes)
+	authHandler.RegisterRoutes(routes)
+	userHandler.RegisterRoutes(routes, auth)
+	carHandler.RegisterRoutes(routes, auth)
+	tripHandler.RegisterRoutes(routes, auth)
+	bookingHandler.RegisterRoutes(routes, auth)
+	reviewHandler.RegisterRoutes(routes, auth)
+	ledgerHandler.RegisterRoutes(routes, auth)
+	// платежи подключаются, только если настроен провайдер
+	if paymentService != nil {
+		NewPaymentHandler(paymentService, logger).RegisterRoutes(routes, auth)
+	}
+	waitlistHandler.RegisterRoutes(routes, auth)
+	tripTemplateHandler.RegisterRoutes(routes, auth)
+	promoHandler.RegisterRoutes(routes, auth)
+	referralHandler.RegisterRoutes(routes, auth)
+	verificationHandler.RegisterRoutes(routes, auth)
+	cityHandler.RegisterRoutes(routes, auth)
+	savedSearchHandler.RegisterRoutes(routes, auth)
 }
@@ -0,0 +1,113 @@
+package transports
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"github.com/mutsaevz/team-5-ambitious/internal/services"
+)
+
+type SavedSearchHandler struct {
+	service services.SavedSearchService
+	logger  *slog.Logger
+}
+
+func NewSavedSearchHandler(service services.SavedSearchService, logger *slog.Logger) *SavedSearchHandler {
+	return &SavedSearchHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *SavedSearchHandler) RegisterRoutes(ctx *gin.Engine, auth gin.HandlerFunc) {
+	api := ctx.Group("/saved-searches")
+	{
+		api.POST("/", auth, h.Create)
+		api.GET("/", auth, h.ListMine)
+		api.DELETE("/:id", auth, h.Delete)
+	}
+}
+
+// POST /saved-searches
+func (h *SavedSearchHandler) Create(ctx *gin.Context) {
+	var req dto.SavedSearchCreateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON"})
+		return
+	}
+
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	search, err := h.service.Create(userID, &req)
+	if err != nil {
+		h.writeError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, search)
+}
+
+// GET /saved-searches
+func (h *SavedSearchHandler) ListMine(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	list, err := h.service.ListMine(userID)
+	if err != nil {
+		h.writeError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, list)
+}
+
+// DELETE /saved-searches/:id
+func (h *SavedSearchHandler) Delete(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	actorID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.service.Delete(actorID, uint(id)); err != nil {
+		h.writeError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "deleted"})
+}
+
+func (h *SavedSearchHandler) writeError(ctx *gin.Context, err error) {
+	if status, ok := errorStatus(err); ok {
+		ctx.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "saved search not found"})
+		return
+	}
+
+	h.logger.Error("saved search request failed",
+		slog.String("path", ctx.FullPath()),
+		slog.Any("error", err),
+	)
+	ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+}
@@ -1,13 +1,17 @@
 package transports
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
 	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
 	"github.com/mutsaevz/team-5-ambitious/internal/repository"
 	"github.com/mutsaevz/team-5-ambitious/internal/services"
 )
@@ -24,14 +28,17 @@ func NewTripHandler(service services.TripService, logger *slog.Logger) *TripHand
 	}
 }
 
-func (h *TripHandler) RegisterRoutes(ctx *gin.Engine) {
+func (h *TripHandler) RegisterRoutes(ctx *gin.Engine, auth gin.HandlerFunc) {
 	api := ctx.Group("/trips")
 	{
-		api.POST("/driver/:driverID", h.Create)
+		api.POST("/", auth, h.Create)
 		api.GET("/", h.List)
 		api.GET("/:id", h.GetByID)
-		api.PUT("/:id", h.Update)
-		api.DELETE("/:id", h.Delete)
+		api.PUT("/:id", auth, h.Update)
+		api.DELETE("/:id", auth, h.Delete)
+		api.POST("/:id/start", auth, h.Start)
+		api.POST("/:id/finish", auth, h.Finish)
+		api.POST("/:id/cancel", auth, h.Cancel)
 	}
 }
 
@@ -43,15 +50,18 @@ func (h *TripHandler) Create(ctx *gin.Context) {
 		return
 	}
 
-	idStr := ctx.Param("driverID")
-	id, err := strconv.ParseUint(idStr, 10, 64)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+	driverID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
 
-	trip, err := h.service.Create(uint(id), &req)
+	trip, err := h.service.Create(driverID, &req)
 	if err != nil {
+		if status, ok := errorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
 		if err == repository.ErrNotFound {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "driver not found"})
 			return
@@ -64,18 +74,20 @@ func (h *TripHandler) Create(ctx *gin.Context) {
 	ctx.JSON(http.StatusCreated, trip)
 }
 
+// List — поиск поездок. Параметры запроса в snake_case; прежние имена в camelCase
+// (fromCity, toCity, startTime, startTimeTo, pageSize) принимаются для совместимости
 func (h *TripHandler) List(ctx *gin.Context) {
 	var filter dto.TripFilter
 
-	if from := ctx.Query("fromCity"); from != "" {
+	if from := queryAlias(ctx, "from_city", "fromCity"); from != "" {
 		filter.FromCity = &from
 	}
 
-	if to := ctx.Query("toCity"); to != "" {
+	if to := queryAlias(ctx, "to_city", "toCity"); to != "" {
 		filter.ToCity = &to
 	}
 
-	if timeStr := ctx.Query("startTime"); timeStr != "" {
+	if timeStr := queryAlias(ctx, "start_time", "startTime"); timeStr != "" {
 		time, err := time.Parse(time.RFC3339, timeStr)
 		if err != nil {
 			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid time format"})
@@ -84,20 +96,98 @@ func (h *TripHandler) List(ctx *gin.Context) {
 		filter.StartTime = &time
 	}
 
+	if timeStr := queryAlias(ctx, "start_time_to", "startTimeTo"); timeStr != "" {
+		time, err := time.Parse(time.RFC3339, timeStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid time format"})
+			return
+		}
+		filter.StartTimeTo = &time
+	}
+
+	if priceStr := ctx.Query("max_price"); priceStr != "" {
+		price, err := strconv.Atoi(priceStr)
+		if err != nil || price < 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid max_price"})
+			return
+		}
+		filter.MaxPrice = &price
+	}
+
+	if ratingStr := ctx.Query("min_rating"); ratingStr != "" {
+		rating, err := strconv.ParseFloat(ratingStr, 64)
+		if err != nil || !(rating >= 0 && rating <= 5) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid min_rating"})
+			return
+		}
+		filter.MinRating = &rating
+	}
+
+	if verifiedOnly, err := strconv.ParseBool(ctx.Query("verified_only")); err == nil {
+		filter.VerifiedOnly = verifiedOnly
+	}
+
+	switch sort := constants.TripSort(ctx.Query("sort")); sort {
+	case "", constants.TripSortDeparture, constants.TripSortPrice, constants.TripSortRating:
+		filter.Sort = sort
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid sort"})
+		return
+	}
+
+	switch ctx.Query("order") {
+	case "":
+	case "asc":
+		desc := false
+		filter.Desc = &desc
+	case "desc":
+		desc := true
+		filter.Desc = &desc
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid order"})
+		return
+	}
+
+	var err error
+	if filter.Origin, err = geoAreaQuery(ctx, "from"); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.Destination, err = geoAreaQuery(ctx, "to"); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if includeFull, err := strconv.ParseBool(ctx.Query("include_full")); err == nil {
+		filter.IncludeFull = includeFull
+	}
+
+	filter.AirConditioning = boolQuery(ctx, "air_conditioning")
+	filter.LuggageSpace = boolQuery(ctx, "luggage_space")
+	filter.ChildSeat = boolQuery(ctx, "child_seat")
+	filter.PetsAllowed = boolQuery(ctx, "pets_allowed")
+	filter.SmokingAllowed = boolQuery(ctx, "smoking_allowed")
+
 	if pageStr := ctx.Query("page"); pageStr != "" {
 		if page, err := strconv.Atoi(pageStr); err == nil {
 			filter.Page = page
 		}
 	}
 
-	if pagesizeStr := ctx.Query("pageSize"); pagesizeStr != "" {
+	if pagesizeStr := queryAlias(ctx, "page_size", "pageSize"); pagesizeStr != "" {
 		if pageSize, err := strconv.Atoi(pagesizeStr); err == nil {
 			filter.PageSize = pageSize
 		}
 	}
 
+	filter.Cursor = ctx.Query("cursor")
+
 	list, err := h.service.List(filter)
 	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -141,8 +231,18 @@ func (h *TripHandler) Update(ctx *gin.Context) {
 		return
 	}
 
-	trip, err := h.service.Update(uint(id), req)
+	actorID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	trip, err := h.service.Update(actorID, uint(id), req)
 	if err != nil {
+		if status, ok := errorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
 		if err == repository.ErrNotFound {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "trip not found"})
 			return
@@ -163,7 +263,17 @@ func (h *TripHandler) Delete(ctx *gin.Context) {
 		return
 	}
 
-	if err := h.service.Delete(uint(id)); err != nil {
+	actorID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.service.Delete(actorID, uint(id)); err != nil {
+		if status, ok := errorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
 		if err == repository.ErrNotFound {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "trip not found"})
 			return
@@ -175,3 +285,109 @@ func (h *TripHandler) Delete(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, gin.H{"status": "deleted"})
 }
+
+func (h *TripHandler) Start(ctx *gin.Context) {
+	h.lifecycle(ctx, "failed to start trip", func(actorID, id uint) (*models.Trip, error) {
+		return h.service.Start(actorID, id)
+	})
+}
+
+func (h *TripHandler) Finish(ctx *gin.Context) {
+	h.lifecycle(ctx, "failed to finish trip", func(actorID, id uint) (*models.Trip, error) {
+		return h.service.Finish(actorID, id)
+	})
+}
+
+func (h *TripHandler) Cancel(ctx *gin.Context) {
+	var req dto.TripCancelRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "cancel reason is required"})
+		return
+	}
+
+	h.lifecycle(ctx, "failed to cancel trip", func(actorID, id uint) (*models.Trip, error) {
+		return h.service.Cancel(actorID, id, req.Reason)
+	})
+}
+
+// lifecycle — общий разбор запроса и ответа для команд жизненного цикла поездки
+func (h *TripHandler) lifecycle(ctx *gin.Context, failure string, command func(actorID, id uint) (*models.Trip, error)) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	actorID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	trip, err := command(actorID, uint(id))
+	if err != nil {
+		if status, ok := errorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		if err == repository.ErrNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "trip not found"})
+			return
+		}
+		h.logger.Error(failure, slog.Uint64("trip_id", uint64(id)), slog.Any("error", err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, trip)
+}
+
+const (
+	defaultSearchRadiusKm = 10
+	maxSearchRadiusKm     = 100
+)
+
+// geoAreaQuery читает круг поиска из параметров <prefix>_lat, <prefix>_lng и <prefix>_radius_km;
+// nil, если точка не задана
+func geoAreaQuery(ctx *gin.Context, prefix string) (*dto.GeoArea, error) {
+	latStr, lngStr := ctx.Query(prefix+"_lat"), ctx.Query(prefix+"_lng")
+	if latStr == "" && lngStr == "" {
+		return nil, nil
+	}
+
+	lat, err := strconv.ParseFloat(latStr, 64)
+	if err != nil || !(lat >= -90 && lat <= 90) {
+		return nil, fmt.Errorf("invalid %s_lat", prefix)
+	}
+	lng, err := strconv.ParseFloat(lngStr, 64)
+	if err != nil || !(lng >= -180 && lng <= 180) {
+		return nil, fmt.Errorf("invalid %s_lng", prefix)
+	}
+
+	radius := float64(defaultSearchRadiusKm)
+	if radiusStr := ctx.Query(prefix + "_radius_km"); radiusStr != "" {
+		radius, err = strconv.ParseFloat(radiusStr, 64)
+		if err != nil || !(radius > 0 && radius <= maxSearchRadiusKm) {
+			return nil, fmt.Errorf("invalid %s_radius_km", prefix)
+		}
+	}
+
+	return &dto.GeoArea{GeoPoint: dto.GeoPoint{Lat: lat, Lng: lng}, RadiusKm: radius}, nil
+}
+
+// queryAlias — параметр запроса по основному имени, а если он не задан — по прежнему
+func queryAlias(ctx *gin.Context, name, alias string) string {
+	if value := ctx.Query(name); value != "" {
+		return value
+	}
+	return ctx.Query(alias)
+}
+
+// boolQuery — необязательный логический параметр запроса; nil, если он не задан или не разобран
+func boolQuery(ctx *gin.Context, name string) *bool {
+	value, err := strconv.ParseBool(ctx.Query(name))
+	if err != nil {
+		return nil
+	}
+	return &value
+}
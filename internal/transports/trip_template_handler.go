@@ -0,0 +1,156 @@
+package transports
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"github.com/mutsaevz/team-5-ambitious/internal/services"
+)
+
+type TripTemplateHandler struct {
+	service services.TripTemplateService
+	logger  *slog.Logger
+}
+
+func NewTripTemplateHandler(service services.TripTemplateService, logger *slog.Logger) *TripTemplateHandler {
+	return &TripTemplateHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *TripTemplateHandler) RegisterRoutes(ctx *gin.Engine, auth gin.HandlerFunc) {
+	api := ctx.Group("/trip-templates")
+	{
+		api.POST("/", auth, h.Create)
+		api.GET("/", auth, h.ListMine)
+		api.GET("/:id", h.GetByID)
+		api.PATCH("/:id", auth, h.Update)
+		api.DELETE("/:id", auth, h.Cancel)
+	}
+}
+
+func (h *TripTemplateHandler) Create(ctx *gin.Context) {
+	var req dto.TripTemplateCreateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	driverID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	template, err := h.service.Create(driverID, &req)
+	if err != nil {
+		h.writeError(ctx, err, "car not found")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, template)
+}
+
+func (h *TripTemplateHandler) ListMine(ctx *gin.Context) {
+	driverID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	templates, err := h.service.ListByDriver(driverID)
+	if err != nil {
+		h.writeError(ctx, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, templates)
+}
+
+func (h *TripTemplateHandler) GetByID(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	template, err := h.service.GetByID(uint(id))
+	if err != nil {
+		h.writeError(ctx, err, "trip template not found")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, template)
+}
+
+func (h *TripTemplateHandler) Update(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req dto.TripTemplateUpdateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON"})
+		return
+	}
+
+	actorID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	template, err := h.service.Update(actorID, uint(id), &req)
+	if err != nil {
+		h.writeError(ctx, err, "trip template not found")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, template)
+}
+
+func (h *TripTemplateHandler) Cancel(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	actorID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.service.Cancel(actorID, uint(id)); err != nil {
+		h.writeError(ctx, err, "trip template not found")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "cancelled"})
+}
+
+func (h *TripTemplateHandler) writeError(ctx *gin.Context, err error, notFound string) {
+	if status, ok := errorStatus(err); ok {
+		ctx.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	if notFound != "" && errors.Is(err, repository.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": notFound})
+		return
+	}
+
+	h.logger.Error("trip template request failed",
+		slog.String("path", ctx.FullPath()),
+		slog.Any("error", err),
+	)
+	ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+}
@@ -1,6 +1,7 @@
 package transports
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -8,6 +9,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/mutsaevz/team-5-ambitious/internal/dto"
 	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
 	"github.com/mutsaevz/team-5-ambitious/internal/services"
 )
 
@@ -23,14 +25,17 @@ func NewUserHandler(service services.UserService, logger *slog.Logger) *UserHand
 	}
 }
 
-func (h UserHandler) RegisterRoutes(ctx *gin.Engine) {
+func (h UserHandler) RegisterRoutes(ctx *gin.Engine, auth gin.HandlerFunc) {
 	api := ctx.Group("/users")
 	{
 		api.POST("/", h.Create)
-		api.GET("/", h.List)
-		api.GET("/:id", h.GetByID)
-		api.PATCH("/:id", h.Update)
-		api.DELETE("/:id", h.Delete)
+		api.GET("/", auth, h.List)
+		api.GET("/me", auth, h.Me)
+		api.POST("/me/phone-code", auth, h.RequestPhoneCode)
+		api.GET("/:id", auth, h.GetByID)
+		api.PATCH("/:id", auth, h.Update)
+		api.PUT("/:id/role", auth, h.SetRole)
+		api.DELETE("/:id", auth, h.Delete)
 	}
 }
 
@@ -90,8 +95,20 @@ func (h *UserHandler) List(ctx *gin.Context) {
 		}
 	}
 
-	users, err := h.service.List(filter)
+	filter.Cursor = ctx.Query("cursor")
+
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	users, err := h.service.List(userID, filter)
 	if err != nil {
+		if status, ok := errorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Error("user list error",
 			slog.String("method", ctx.Request.Method),
 			slog.String("path", ctx.FullPath()),
@@ -124,6 +141,10 @@ func (h *UserHandler) GetByID(ctx *gin.Context) {
 
 	user, err := h.service.GetByID(uint(id))
 	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
 		h.logger.Error("handler called",
 			slog.String("method", ctx.Request.Method),
 			slog.String("error", "user not found"),
@@ -133,9 +154,61 @@ func (h *UserHandler) GetByID(ctx *gin.Context) {
 		return
 	}
 
+	// телефон и баланс видит только сам пользователь через /users/me
+	ctx.JSON(http.StatusOK, dto.NewUserPublic(user))
+}
+
+func (h *UserHandler) Me(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	user, err := h.service.GetByID(userID)
+	if err != nil {
+		h.logger.Error("user output error",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.Any("error", err),
+		)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "user not found"})
+		return
+	}
+
 	ctx.JSON(http.StatusOK, user)
 }
 
+// RequestPhoneCode отправляет код на новый номер; затем номер меняется через PATCH с phone_code
+func (h *UserHandler) RequestPhoneCode(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input dto.UserPhoneCodeRequest
+
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON"})
+		return
+	}
+
+	if err := h.service.RequestPhoneCode(userID, input.Phone); err != nil {
+		if status, ok := errorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("failed to request phone code",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.Any("error", err),
+		)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{"status": "code sent"})
+}
+
 func (h *UserHandler) Update(ctx *gin.Context) {
 	h.logger.Info("handler called",
 		slog.String("method", ctx.Request.Method),
@@ -154,6 +227,12 @@ func (h *UserHandler) Update(ctx *gin.Context) {
 		return
 	}
 
+	actorID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
 	var input dto.UserUpdateRequest
 
 	if err := ctx.ShouldBindJSON(&input); err != nil {
@@ -166,9 +245,13 @@ func (h *UserHandler) Update(ctx *gin.Context) {
 		return
 	}
 
-	updated, err := h.service.Update(uint(id), input)
+	updated, err := h.service.Update(actorID, uint(id), input)
 
 	if err != nil {
+		if status, ok := errorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Error("error saving changes",
 			slog.Uint64("user_id", uint64(id)),
 			slog.Any("error", err),
@@ -181,6 +264,52 @@ func (h *UserHandler) Update(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, updated)
 }
 
+func (h *UserHandler) SetRole(ctx *gin.Context) {
+	h.logger.Info("handler called",
+		slog.String("method", ctx.Request.Method),
+		slog.String("path", ctx.FullPath()),
+	)
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	actorID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input dto.UserRoleRequest
+
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON"})
+		return
+	}
+
+	updated, err := h.service.SetRole(actorID, uint(id), input.Role)
+	if err != nil {
+		if status, ok := errorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrInvalidRole) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("error saving role",
+			slog.Uint64("user_id", uint64(id)),
+			slog.Any("error", err),
+		)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error saving changes"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, updated)
+}
+
 func (h *UserHandler) Delete(ctx *gin.Context) {
 	h.logger.Info("handler called",
 		slog.String("method", ctx.Request.Method),
@@ -199,7 +328,17 @@ func (h *UserHandler) Delete(ctx *gin.Context) {
 		return
 	}
 
-	if err := h.service.Delete(uint(id)); err != nil {
+	actorID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.service.Delete(actorID, uint(id)); err != nil {
+		if status, ok := errorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Error("failed to delete user",
 			slog.String("method", ctx.Request.Method),
 			slog.Uint64("user_id", uint64(id)),
@@ -0,0 +1,239 @@
+package transports
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"github.com/mutsaevz/team-5-ambitious/internal/services"
+)
+
+type VerificationHandler struct {
+	service services.VerificationService
+	logger  *slog.Logger
+}
+
+func NewVerificationHandler(service services.VerificationService, logger *slog.Logger) *VerificationHandler {
+	return &VerificationHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *VerificationHandler) RegisterRoutes(ctx *gin.Engine, auth gin.HandlerFunc) {
+	api := ctx.Group("/verifications")
+	{
+		api.POST("/", auth, h.Submit)
+		api.GET("/me", auth, h.ListMine)
+		api.GET("/", auth, h.Queue)
+		api.POST("/:id/approve", auth, h.Approve)
+		api.POST("/:id/reject", auth, h.Reject)
+		api.GET("/:id/documents/:kind", auth, h.Document)
+	}
+}
+
+// POST /verifications — multipart-форма с файлами license и registration
+func (h *VerificationHandler) Submit(ctx *gin.Context) {
+	var req dto.VerificationSubmitRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	driverID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	license, closeLicense, err := openUpload(ctx, "license")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "license file is required"})
+		return
+	}
+	defer closeLicense()
+
+	registration, closeRegistration, err := openUpload(ctx, "registration")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "registration file is required"})
+		return
+	}
+	defer closeRegistration()
+
+	verification, err := h.service.Submit(driverID, &req, license, registration)
+	if err != nil {
+		h.writeError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, verification)
+}
+
+func (h *VerificationHandler) ListMine(ctx *gin.Context) {
+	driverID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	list, err := h.service.ListMine(driverID)
+	if err != nil {
+		h.writeError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, list)
+}
+
+// GET /verifications?status=pending — очередь на рассмотрение для администратора
+func (h *VerificationHandler) Queue(ctx *gin.Context) {
+	actorID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var status *constants.VerificationStatus
+	if s := ctx.Query("status"); s != "" {
+		value := constants.VerificationStatus(s)
+		status = &value
+	}
+
+	var filter models.Page
+
+	if pageStr := ctx.Query("page"); pageStr != "" {
+		if page, err := strconv.Atoi(pageStr); err == nil {
+			filter.Page = page
+		}
+	}
+
+	if pageSizeStr := ctx.Query("pageSize"); pageSizeStr != "" {
+		if pageSize, err := strconv.Atoi(pageSizeStr); err == nil {
+			filter.PageSize = pageSize
+		}
+	}
+
+	filter.Cursor = ctx.Query("cursor")
+
+	list, err := h.service.Queue(actorID, status, filter)
+	if err != nil {
+		h.writeError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, list)
+}
+
+func (h *VerificationHandler) Approve(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	actorID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	verification, err := h.service.Approve(actorID, uint(id))
+	if err != nil {
+		h.writeError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, verification)
+}
+
+func (h *VerificationHandler) Reject(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req dto.VerificationRejectRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "reason is required"})
+		return
+	}
+
+	actorID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	verification, err := h.service.Reject(actorID, uint(id), req.Reason)
+	if err != nil {
+		h.writeError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, verification)
+}
+
+// GET /verifications/:id/documents/:kind — файл документа для администратора
+func (h *VerificationHandler) Document(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	actorID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	kind := constants.VerificationDocument(ctx.Param("kind"))
+
+	file, contentType, err := h.service.Document(actorID, uint(id), kind)
+	if err != nil {
+		h.writeError(ctx, err)
+		return
+	}
+	defer file.Close()
+
+	ctx.DataFromReader(http.StatusOK, -1, contentType, file, nil)
+}
+
+func (h *VerificationHandler) writeError(ctx *gin.Context, err error) {
+	if status, ok := errorStatus(err); ok {
+		ctx.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "verification request not found"})
+		return
+	}
+
+	h.logger.Error("verification request failed",
+		slog.String("path", ctx.FullPath()),
+		slog.Any("error", err),
+	)
+	ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+}
+
+// openUpload открывает файл из поля multipart-формы; close нужно вызвать после обработки
+func openUpload(ctx *gin.Context, field string) (services.DocumentUpload, func(), error) {
+	header, err := ctx.FormFile(field)
+	if err != nil {
+		return services.DocumentUpload{}, nil, err
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return services.DocumentUpload{}, nil, err
+	}
+
+	return services.DocumentUpload{Reader: file, Size: header.Size}, func() { file.Close() }, nil
+}
@@ -0,0 +1,153 @@
+package transports
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"github.com/mutsaevz/team-5-ambitious/internal/services"
+)
+
+type WaitlistHandler struct {
+	service services.WaitlistService
+	logger  *slog.Logger
+}
+
+func NewWaitlistHandler(service services.WaitlistService, logger *slog.Logger) *WaitlistHandler {
+	return &WaitlistHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *WaitlistHandler) RegisterRoutes(ctx *gin.Engine, auth gin.HandlerFunc) {
+	trips := ctx.Group("/trips")
+	{
+		trips.POST("/:id/waitlist", auth, h.Join)
+		trips.GET("/:id/waitlist", auth, h.ListByTrip)
+	}
+
+	api := ctx.Group("/waitlist")
+	{
+		api.POST("/:id/accept", auth, h.Accept)
+		api.DELETE("/:id", auth, h.Leave)
+	}
+}
+
+// POST /trips/:id/waitlist
+func (h *WaitlistHandler) Join(ctx *gin.Context) {
+	tripID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid trip id"})
+		return
+	}
+
+	var req dto.WaitlistJoinRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON"})
+		return
+	}
+
+	passengerID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	entry, err := h.service.Join(passengerID, uint(tripID), &req)
+	if err != nil {
+		h.writeError(ctx, err, "trip not found")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, entry)
+}
+
+// GET /trips/:id/waitlist
+func (h *WaitlistHandler) ListByTrip(ctx *gin.Context) {
+	tripID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid trip id"})
+		return
+	}
+
+	actorID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	entries, err := h.service.ListByTrip(actorID, uint(tripID))
+	if err != nil {
+		h.writeError(ctx, err, "trip not found")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entries)
+}
+
+// POST /waitlist/:id/accept
+func (h *WaitlistHandler) Accept(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	passengerID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	booking, err := h.service.Accept(passengerID, uint(id))
+	if err != nil {
+		h.writeError(ctx, err, "waitlist entry not found")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, booking)
+}
+
+// DELETE /waitlist/:id
+func (h *WaitlistHandler) Leave(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	passengerID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.service.Leave(passengerID, uint(id)); err != nil {
+		h.writeError(ctx, err, "waitlist entry not found")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "left"})
+}
+
+func (h *WaitlistHandler) writeError(ctx *gin.Context, err error, notFound string) {
+	if status, ok := errorStatus(err); ok {
+		ctx.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": notFound})
+		return
+	}
+
+	h.logger.Error("waitlist request failed",
+		slog.String("path", ctx.FullPath()),
+		slog.Any("error", err),
+	)
+	ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+}